@@ -0,0 +1,99 @@
+package configset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// WithAuditSink returns an option that sets the sink of audit events, which is
+// called with an event for every update, environment variable override, load
+// and rollback of the config set, to satisfy change-tracking requirements.
+func WithAuditSink(auditSink func(event AuditEvent)) Option {
+	return func(options *options) { options.auditSink = auditSink }
+}
+
+// AuditEvent represents a change of the config set.
+type AuditEvent struct {
+	// Time is the time the change happened at.
+	Time time.Time `json:"time"`
+
+	// Kind is the kind of the change.
+	Kind AuditKind `json:"kind"`
+
+	// Actor is the actor of the change, set by (*Txn).SetActor, if any.
+	Actor string `json:"actor,omitempty"`
+
+	// Path is the path of the value changed, or empty for the whole config
+	// set.
+	Path string `json:"path,omitempty"`
+
+	// OldValueHash is the SHA-256 digest of the old value in form of JSON, in
+	// hex, or empty if the value did not exist.
+	OldValueHash string `json:"oldValueHash,omitempty"`
+
+	// NewValueHash is the SHA-256 digest of the new value in form of JSON, in
+	// hex, or empty if the value does not exist any more.
+	NewValueHash string `json:"newValueHash,omitempty"`
+}
+
+// AuditKind represents a kind of changes of the config set.
+type AuditKind string
+
+const (
+	// AuditLoad is the kind of loads and reloads of the config set, with the
+	// path empty.
+	AuditLoad AuditKind = "load"
+
+	// AuditEnvOverride is the kind of overrides by environment variables
+	// applied on loads, with the old value hash empty.
+	AuditEnvOverride AuditKind = "envOverride"
+
+	// AuditUpdate is the kind of updates by Set, Delete and Update.
+	AuditUpdate AuditKind = "update"
+
+	// AuditRollback is the kind of rollbacks, with the path empty.
+	AuditRollback AuditKind = "rollback"
+
+	// AuditSecretRotation is the kind of secrets rotated, see WatchSecrets.
+	AuditSecretRotation AuditKind = "secretRotation"
+)
+
+func newAuditEvent(kind AuditKind, actor string, path string, oldRawConfigSet json.RawMessage, newRawConfigSet json.RawMessage) AuditEvent {
+	return AuditEvent{
+		Time:         time.Now(),
+		Kind:         kind,
+		Actor:        actor,
+		Path:         path,
+		OldValueHash: hashValue(oldRawConfigSet, path),
+		NewValueHash: hashValue(newRawConfigSet, path),
+	}
+}
+
+func hashValue(rawConfigSet json.RawMessage, path string) string {
+	if rawConfigSet == nil {
+		return ""
+	}
+	value := rawConfigSet
+	if path != "" {
+		result := gjson.GetBytes(rawConfigSet, path)
+		if !result.Exists() {
+			return ""
+		}
+		value = json.RawMessage(result.Raw)
+	}
+	digest := sha256.Sum256(value)
+	return hex.EncodeToString(digest[:])
+}
+
+// auditLoad emits the audit events for a load.
+func auditLoad(auditSink func(AuditEvent), environment []string, envPrefix string, oldRawConfigSet json.RawMessage, newRawConfigSet json.RawMessage) {
+	auditSink(newAuditEvent(AuditLoad, "", "", oldRawConfigSet, newRawConfigSet))
+	for _, kv := range extractKVs(environment, envPrefix) {
+		path := kv[0][len(envPrefix):]
+		auditSink(newAuditEvent(AuditEnvOverride, "", path, nil, newRawConfigSet))
+	}
+}
@@ -0,0 +1,76 @@
+package configset_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_AuditSink(t *testing.T) {
+	var cs ConfigSet
+	var events []AuditEvent
+	cs.Configure(WithAuditSink(func(event AuditEvent) {
+		assert.False(t, event.Time.IsZero())
+		event.Time = time.Time{}
+		events = append(events, event)
+	}))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Update(func(tx *Txn) error {
+		tx.SetActor("alice")
+		if err := tx.Set("server.port", 8081); err != nil {
+			return err
+		}
+		return tx.Delete("server.host")
+	})
+	assert.NoError(t, err)
+	err = cs.Rollback(1)
+	assert.NoError(t, err)
+
+	hash := func(s string) string {
+		digest := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(digest[:])
+	}
+	assert.Equal(t, []AuditEvent{
+		{
+			Kind:         AuditLoad,
+			NewValueHash: hash(`{"server":{"host":"localhost","port":8080}}`),
+		},
+		{
+			Kind:         AuditEnvOverride,
+			Path:         "server.host",
+			NewValueHash: hash(`"localhost"`),
+		},
+		{
+			Kind:         AuditUpdate,
+			Actor:        "alice",
+			Path:         "server.port",
+			OldValueHash: hash(`8080`),
+			NewValueHash: hash(`8081`),
+		},
+		{
+			Kind:         AuditUpdate,
+			Actor:        "alice",
+			Path:         "server.host",
+			OldValueHash: hash(`"localhost"`),
+		},
+		{
+			Kind:         AuditRollback,
+			OldValueHash: hash(`{"server":{"port":8081}}`),
+			NewValueHash: hash(`{"server":{"host":"localhost","port":8080}}`),
+		},
+	}, events)
+}
@@ -0,0 +1,62 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// BindAndWatch reads the value for the given path into the given config, like
+// ReadValue, and then re-reads the value into the config whenever the value
+// changes after a reload, so code using the config never deals with reload
+// plumbing. Values pre-populated in the config are preserved as defaults for
+// all the re-reads. Each re-read is decoded into a new config first and then
+// copied into the given config while holding the given locker, which is
+// supposed to guard all the accesses to the config, e.g. a *sync.Mutex, or a
+// *sync.RWMutex with readers of the config holding read locks. A failed re-read
+// leaves the config untouched, and the failure is reported to the handler set by
+// WithReloadErrorHandler.
+// The returned function stops re-reading.
+func BindAndWatch(path string, config interface{}, locker sync.Locker) (cancel func(), err error) {
+	return cs.BindAndWatch(path, config, locker)
+}
+
+func (cs *ConfigSet) BindAndWatch(path string, config interface{}, locker sync.Locker) (func(), error) {
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr || configValue.IsNil() {
+		return nil, fmt.Errorf("configset: non-nil pointer expected; configType=\"%T\"", config)
+	}
+	cs.mutex.Lock()
+	jsonCodec := cs.jsonCodec()
+	cs.mutex.Unlock()
+	template, err := jsonCodec.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal to json; path=%q configType=\"%T\": %w", path, config, err)
+	}
+	readValue := func(snapshot *Snapshot) error {
+		newConfig := reflect.New(configValue.Type().Elem())
+		if err := jsonCodec.Unmarshal(template, newConfig.Interface()); err != nil {
+			return fmt.Errorf("unmarshal from json; path=%q configType=\"%T\": %w", path, config, err)
+		}
+		if err := snapshot.ReadValue(path, newConfig.Interface()); err != nil {
+			return err
+		}
+		locker.Lock()
+		configValue.Elem().Set(newConfig.Elem())
+		locker.Unlock()
+		return nil
+	}
+	if err := readValue(cs.Snapshot()); err != nil {
+		return nil, err
+	}
+	cancel := cs.Subscribe(path, func(_, newValue json.RawMessage) {
+		if newValue == nil {
+			return
+		}
+		if err := readValue(cs.Snapshot()); err != nil {
+			cs.reportReloadError(fmt.Errorf("re-read value; path=%q: %w", path, err))
+		}
+	})
+	return cancel, nil
+}
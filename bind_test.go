@@ -0,0 +1,56 @@
+package configset_test
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_BindAndWatch(t *testing.T) {
+	type Server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	var cs ConfigSet
+	var reloadErr error
+	cs.Configure(WithReloadErrorHandler(func(err error) { reloadErr = err }))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var mutex sync.Mutex
+	server := Server{Host: "localhost"}
+	cancel, err := cs.BindAndWatch("server", &server, &mutex)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, Server{Host: "localhost", Port: 8080}, server)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+	assert.Equal(t, Server{Host: "localhost", Port: 8081}, server)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=http"})
+	assert.NoError(t, err)
+	assert.Equal(t, Server{Host: "localhost", Port: 8081}, server)
+	assert.EqualError(t, reloadErr, `re-read value; path="server": unmarshal from json; path="server" configType="*configset_test.Server": json: cannot unmarshal string into Go struct field Server.port of type int`)
+
+	cancel()
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8082"})
+	assert.NoError(t, err)
+	assert.Equal(t, Server{Host: "localhost", Port: 8081}, server)
+
+	_, err = cs.BindAndWatch("server", server, &mutex)
+	assert.EqualError(t, err, `configset: non-nil pointer expected; configType="configset_test.Server"`)
+	_, err = cs.BindAndWatch("client", &server, &mutex)
+	assert.ErrorIs(t, err, ErrValueNotFound)
+}
@@ -0,0 +1,54 @@
+package configset
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// bufferPool pools the buffers for reading files, which are needed only until
+// the files are converted to JSON, so that frequent reloads, e.g. by Watch,
+// don't allocate buffers for every file over and over again.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// maxPooledBufferSize is the maximum capacity of buffers put back into the pool,
+// beyond which buffers are dropped rather than pinning large memory.
+const maxPooledBufferSize = 1 << 20
+
+func getBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+// putBuffer puts the given buffer back into the pool, zeroed, since the file
+// contents may contain secrets, which must not linger in pooled memory.
+func putBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() > maxPooledBufferSize {
+		return
+	}
+	buffer.Reset()
+	data := buffer.Bytes()
+	data = data[:cap(data)]
+	for i := range data {
+		data[i] = 0
+	}
+	bufferPool.Put(buffer)
+}
+
+// readFile likes afero.ReadFile but reads the given file into the given buffer.
+func readFile(fs afero.Fs, filePath string, buffer *bytes.Buffer) error {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if fileInfo, err := file.Stat(); err == nil && fileInfo.Size() < 1<<30 {
+		buffer.Grow(int(fileInfo.Size()) + bytes.MinRead)
+	}
+	_, err = buffer.ReadFrom(file)
+	return err
+}
@@ -0,0 +1,55 @@
+package configset
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/tidwall/gjson"
+)
+
+// canonicalizeJSON returns the given JSON value in the canonical form, i.e.
+// compact with the keys of objects sorted, so that dumps and digests of equal
+// config sets are identical regardless of the order the layers are merged in.
+func canonicalizeJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	return appendCanonicalJSON(make([]byte, 0, len(raw)), gjson.ParseBytes(raw))
+}
+
+func appendCanonicalJSON(buffer []byte, value gjson.Result) []byte {
+	switch {
+	case value.IsObject():
+		type member struct {
+			key   gjson.Result
+			value gjson.Result
+		}
+		var members []member
+		value.ForEach(func(key, value gjson.Result) bool {
+			members = append(members, member{key, value})
+			return true
+		})
+		sort.SliceStable(members, func(i, j int) bool { return members[i].key.Str < members[j].key.Str })
+		buffer = append(buffer, '{')
+		for i, member := range members {
+			if i >= 1 {
+				buffer = append(buffer, ',')
+			}
+			buffer = append(buffer, member.key.Raw...)
+			buffer = append(buffer, ':')
+			buffer = appendCanonicalJSON(buffer, member.value)
+		}
+		return append(buffer, '}')
+	case value.IsArray():
+		buffer = append(buffer, '[')
+		for i, element := range value.Array() {
+			if i >= 1 {
+				buffer = append(buffer, ',')
+			}
+			buffer = appendCanonicalJSON(buffer, element)
+		}
+		return append(buffer, ']')
+	default:
+		return append(buffer, value.Raw...)
+	}
+}
@@ -0,0 +1,119 @@
+package configset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// WithChecksumManifest returns an option that makes loads validate the
+// configuration files against the reserved file SHA256SUMS under the
+// directory, which is in the format of the output of sha256sum, listing the
+// *.yaml files under the directory and the directory _defaults, e.g.
+//
+//	cd config && sha256sum *.yaml _defaults/*.yaml > SHA256SUMS
+//
+// If any file is missing, unlisted, or has a different digest, the load fails
+// with ErrChecksumMismatch. Like with WithSignatureKey, the files are loaded
+// from the exact bytes validated, and files can't be inlined with placeholders.
+func WithChecksumManifest() Option {
+	return func(options *options) { options.checksumManifest = true }
+}
+
+// Digest returns the aggregate digest of the configuration files the config set
+// has been loaded from, which is the SHA-256 digest, in hex, of the manifest of
+// the files in the format of SHA256SUMS, so that operators can confirm exactly
+// which configuration a running process has loaded, e.g. by comparing with
+//
+//	cd config && sha256sum *.yaml _defaults/*.yaml | LC_ALL=C sort -k 2 | sha256sum
+//
+// If the config set has not been loaded, an empty string is returned.
+func Digest() string { return cs.Digest() }
+
+// ErrChecksumMismatch is returned when the configuration files do not match the
+// checksum manifest.
+var ErrChecksumMismatch = errors.New("configset: checksum mismatch")
+
+const checksumManifestFileName = "SHA256SUMS"
+
+func (cs *ConfigSet) Digest() string {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	return cs.digest
+}
+
+func digestManifest(manifest []byte) string {
+	digest := sha256.Sum256(manifest)
+	return hex.EncodeToString(digest[:])
+}
+
+// validateChecksums validates the given manifest of configuration files against
+// the checksum manifest under the given directory.
+func validateChecksums(fs afero.Fs, dirPath string, manifest []byte) error {
+	filePath := filepath.Join(dirPath, checksumManifestFileName)
+	data, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+	}
+	expectedDigests, err := parseManifest(data)
+	if err != nil {
+		return fmt.Errorf("parse manifest; filePath=%q: %w", filePath, err)
+	}
+	actualDigests, err := parseManifest(manifest)
+	if err != nil {
+		return fmt.Errorf("parse manifest: %w", err)
+	}
+	fileNames := make([]string, 0, len(expectedDigests))
+	for fileName := range expectedDigests {
+		fileNames = append(fileNames, fileName)
+	}
+	for fileName := range actualDigests {
+		if _, ok := expectedDigests[fileName]; !ok {
+			fileNames = append(fileNames, fileName)
+		}
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		expectedDigest, ok := expectedDigests[fileName]
+		if !ok {
+			return fmt.Errorf("%w; fileName=%q: unlisted", ErrChecksumMismatch, fileName)
+		}
+		actualDigest, ok := actualDigests[fileName]
+		if !ok {
+			return fmt.Errorf("%w; fileName=%q: missing", ErrChecksumMismatch, fileName)
+		}
+		if actualDigest != expectedDigest {
+			return fmt.Errorf("%w; fileName=%q expectedDigest=%q actualDigest=%q", ErrChecksumMismatch, fileName, expectedDigest, actualDigest)
+		}
+	}
+	return nil
+}
+
+// parseManifest parses the given manifest in the format of SHA256SUMS, and
+// returns the digests keyed by file names.
+func parseManifest(manifest []byte) (map[string]string, error) {
+	digests := make(map[string]string)
+	for i, line := range bytes.Split(manifest, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		fields := strings.SplitN(string(line), " ", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line; lineNumber=%d", i+1)
+		}
+		digest := strings.ToLower(fields[0])
+		// The file name is prefixed with ' ' in text mode or '*' in binary mode.
+		fileName := strings.TrimPrefix(strings.TrimPrefix(fields[1], " "), "*")
+		fileName = strings.TrimPrefix(filepath.ToSlash(fileName), "./")
+		digests[fileName] = digest
+	}
+	return digests, nil
+}
@@ -0,0 +1,70 @@
+package configset_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_ChecksumManifest(t *testing.T) {
+	hash := func(s string) string {
+		digest := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(digest[:])
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/_defaults/server.yaml", []byte("host: localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := fmt.Sprintf("%s  _defaults/server.yaml\n%s  server.yaml\n", hash("host: localhost\n"), hash("port: 8080\n"))
+
+	var cs ConfigSet
+	assert.Equal(t, "", cs.Digest())
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, hash(manifest), cs.Digest())
+
+	cs.Configure(WithChecksumManifest())
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.EqualError(t, err, `read file; filePath="/my_etc/SHA256SUMS": open /my_etc/SHA256SUMS: file does not exist`)
+
+	if err := afero.WriteFile(fs, "/my_etc/SHA256SUMS", []byte(fmt.Sprintf("%s *./server.yaml\n", hash("port: 8080\n"))), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.EqualError(t, err, `configset: checksum mismatch; fileName="_defaults/server.yaml": unlisted`)
+
+	if err := afero.WriteFile(fs, "/my_etc/SHA256SUMS", []byte(manifest+hash("")+"  client.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.EqualError(t, err, `configset: checksum mismatch; fileName="client.yaml": missing`)
+
+	if err := afero.WriteFile(fs, "/my_etc/SHA256SUMS", []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	tamperedFS := afero.NewMemMapFs()
+	if err := afero.WriteFile(tamperedFS, "/my_etc/server.yaml", []byte("port: 8081\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(&tamperingFS{Fs: fs, filePath: "/my_etc/server.yaml", tamperedFS: tamperedFS}, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8081\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.EqualError(t, err, fmt.Sprintf(`configset: checksum mismatch; fileName="server.yaml" expectedDigest=%q actualDigest=%q`, hash("port: 8080\n"), hash("port: 8081\n")))
+}
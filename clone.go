@@ -0,0 +1,47 @@
+package configset
+
+import "encoding/json"
+
+// Clone returns an independent copy of the config set, including the options,
+// rules, validators, types, default values, sources, the loaded configs and
+// the history, so that code can experiment with updates or what-if merges
+// without touching the config set. Subscriptions are not copied, and the copy is
+// not closed even if the config set is.
+func Clone() *ConfigSet { return cs.Clone() }
+
+func (cs *ConfigSet) Clone() *ConfigSet {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	clone := ConfigSet{
+		options:         cs.options,
+		rules:           append([]Rule(nil), cs.rules...),
+		validators:      append([]Validator(nil), cs.validators...),
+		types:           append([]typeRegistration(nil), cs.types...),
+		defaults:        append(json.RawMessage(nil), cs.defaults...),
+		secretPaths:     append([]string(nil), cs.secretPaths...),
+		secretValues:    append([]secretValue(nil), cs.secretValues...),
+		secretRefs:      append([]secretRef(nil), cs.secretRefs...),
+		input:           cs.input,
+		dirState:        cs.dirState,
+		digest:          cs.digest,
+		yamlFiles:       cs.yamlFiles,
+		overrides:       cs.overrides,
+		loaded:          cs.loaded,
+		provenance:      cs.provenance,
+		history:         append([]Generation(nil), cs.history...),
+		generationCount: cs.generationCount,
+	}
+	clone.options.sources = append([]Source(nil), cs.options.sources...)
+	clone.options.flagPatches = append([]valuePatch(nil), cs.options.flagPatches...)
+	clone.input.environment = append([]string(nil), cs.input.environment...)
+	if cs.secretProviders != nil {
+		clone.secretProviders = make(map[string]SecretProvider, len(cs.secretProviders))
+		for name, secretProvider := range cs.secretProviders {
+			clone.secretProviders[name] = secretProvider
+		}
+	}
+	if snapshot, ok := cs.snapshot.Load().(*Snapshot); ok {
+		clone.snapshot.Store(snapshot)
+	}
+	return &clone
+}
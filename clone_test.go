@@ -0,0 +1,39 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Clone(t *testing.T) {
+	var cs ConfigSet
+	cs.AddRules(Assert("server.port", Required()))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	clone := cs.Clone()
+	assert.Equal(t, string(cs.Dump("", "")), string(clone.Dump("", "")))
+
+	err = clone.Set("server.port", 8081)
+	assert.NoError(t, err)
+	err = clone.Delete("server.port")
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8081}}`, string(clone.Dump("", "")))
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+	assert.Len(t, clone.History(), 2)
+	assert.Len(t, cs.History(), 1)
+
+	err = clone.ForceRefresh()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(clone.Dump("", "")))
+}
@@ -0,0 +1,91 @@
+package configset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Close closes the config set, which stops Watch, Poll and WatchSecrets,
+// aborting the loads in flight, e.g. fetching configs from remote sources, and
+// waits for them to return. Then the sources implementing io.Closer are closed
+// to release resources, and the first error is returned, if any. Close must not
+// be called from subscribers, since they are called by Watch and Poll. Calling
+// Close more than once is a no-op.
+func Close() error { return cs.Close() }
+
+// ErrClosed is returned by Watch and Poll when the config set is closed.
+var ErrClosed = errors.New("configset: config set closed")
+
+func (cs *ConfigSet) Close() error {
+	cs.closureMutex.Lock()
+	if cs.closed {
+		cs.closureMutex.Unlock()
+		return nil
+	}
+	cs.closed = true
+	if cs.closure == nil {
+		cs.closure = make(chan struct{})
+	}
+	close(cs.closure)
+	cs.closureMutex.Unlock()
+	cs.backgroundWG.Wait()
+	cs.mutex.Lock()
+	sources := cs.options.sources
+	cs.mutex.Unlock()
+	var firstErr error
+	for i, source := range sources {
+		closer, ok := source.(io.Closer)
+		if !ok {
+			continue
+		}
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("close source; sourceIndex=%d sourceType=\"%T\": %w", i, source, err)
+		}
+	}
+	return firstErr
+}
+
+// enterBackground registers a background goroutine, which should stop once the
+// returned channel is closed, and then call leaveBackground.
+func (cs *ConfigSet) enterBackground() (<-chan struct{}, error) {
+	cs.closureMutex.Lock()
+	defer cs.closureMutex.Unlock()
+	if cs.closed {
+		return nil, ErrClosed
+	}
+	if cs.closure == nil {
+		cs.closure = make(chan struct{})
+	}
+	cs.backgroundWG.Add(1)
+	return cs.closure, nil
+}
+
+func (cs *ConfigSet) leaveBackground() { cs.backgroundWG.Done() }
+
+// contextWithClosure returns a copy of the given context, which is canceled once
+// the given closure is closed, so that closing the config set aborts the loads
+// in flight.
+func contextWithClosure(ctx context.Context, closure <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-closure:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// stopErr returns the error a background goroutine stops with, i.e. ErrClosed if
+// the given closure is closed, or the error of the given context otherwise.
+func stopErr(ctx context.Context, closure <-chan struct{}) error {
+	select {
+	case <-closure:
+		return ErrClosed
+	default:
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Close(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithWatchInterval(10*time.Millisecond), WithPollInterval(10*time.Millisecond))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	errs := make(chan error, 2)
+	go func() { errs <- cs.Watch(context.Background()) }()
+	go func() { errs <- cs.Poll(context.Background()) }()
+	time.Sleep(50 * time.Millisecond)
+
+	err = cs.Close()
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			assert.ErrorIs(t, err, ErrClosed)
+		case <-time.After(time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	err = cs.Watch(context.Background())
+	assert.ErrorIs(t, err, ErrClosed)
+	err = cs.Close()
+	assert.NoError(t, err)
+}
+
+func TestConfigSet_LoadContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	var cs ConfigSet
+	cs.AddSources(NewHTTPSource(server.URL, nil))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := cs.LoadContext(ctx, fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, ``, string(cs.Dump("", "")))
+}
+
+type closingSource struct {
+	sourceFunc
+	closeCount int
+}
+
+func (cs *closingSource) Close() error {
+	cs.closeCount++
+	return errors.New("something wrong")
+}
+
+func TestConfigSet_Close_abortsLoads(t *testing.T) {
+	var fetchCount int32
+	source := closingSource{sourceFunc: func(ctx context.Context) (json.RawMessage, error) {
+		if atomic.AddInt32(&fetchCount, 1) == 1 {
+			return json.RawMessage(`{}`), nil
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	var cs ConfigSet
+	cs.Configure(WithPollInterval(time.Millisecond))
+	cs.AddSources(&source)
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- cs.Poll(context.Background()) }()
+	for atomic.LoadInt32(&fetchCount) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	err = cs.Close()
+	assert.EqualError(t, err, `close source; sourceIndex=0 sourceType="*configset_test.closingSource": something wrong`)
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	err = cs.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, source.closeCount)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// runConvert prints the file, or the configuration of the directory loaded
+// like the library does without environment variables, converted to the
+// target format.
+func runConvert(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset convert [flags] <file or dir>")
+		flagSet.PrintDefaults()
+	}
+	from := flagSet.String("from", "yaml", "convert from `format`, i.e. yaml, which includes json")
+	to := flagSet.String("to", "json", "convert to `format`, i.e. json, yaml, toml, env or flat")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 1 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	if *from != "yaml" && *from != "json" {
+		fmt.Fprintf(stderr, "configset: unknown source format %q\n", *from)
+		return exitUsage
+	}
+	dumpFormat := configset.DumpFormat(*to)
+	if dumpFormat == "env" {
+		dumpFormat = configset.DumpFormatDotenv
+	}
+	if err := convert(args[0], dumpFormat, stdout); err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+func convert(filePath string, dumpFormat configset.DumpFormat, w io.Writer) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	cs := configset.New()
+	dumpOptions := configset.DumpOptions{Format: dumpFormat}
+	if dumpFormat == configset.DumpFormatJSON {
+		dumpOptions.Indention = "  "
+	}
+	if fileInfo.IsDir() {
+		if err := cs.Load(afero.NewOsFs(), filePath, nil); err != nil {
+			return fmt.Errorf("load config set: %w", err)
+		}
+	} else {
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("read file: %w", err)
+		}
+		rawConfig, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return fmt.Errorf("convert yaml to json; filePath=%q: %w", filePath, err)
+		}
+		// Wrap the file content, which may not be an object, in an object.
+		rawConfigSet, err := json.Marshal(map[string]json.RawMessage{"file": rawConfig})
+		if err != nil {
+			return fmt.Errorf("marshal to json; filePath=%q: %w", filePath, err)
+		}
+		if err := cs.MergeAt("", rawConfigSet); err != nil {
+			return err
+		}
+		dumpOptions.Path = "file"
+	}
+	return cs.DumpTo(w, dumpOptions)
+}
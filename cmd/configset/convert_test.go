@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunConvert(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "port: 8080\ntags: [a, b]\n",
+		"etc/client.yaml": "timeout: 10\n",
+		"list.yaml":       "- 1\n- 2\n",
+		"bad.yaml":        "{",
+	})
+	serverFilePath := filepath.Join(dirPath, "etc", "server.yaml")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"convert", serverFilePath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "{\n  \"port\": 8080,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"convert", "--from", "yaml", "--to", "toml", serverFilePath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "port = 8080\ntags = [\"a\", \"b\"]\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"convert", "-to", "env", filepath.Join(dirPath, "etc")}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "CLIENT_TIMEOUT=10\nSERVER_PORT=8080\nSERVER_TAGS_0=\"a\"\nSERVER_TAGS_1=\"b\"\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"convert", "-to", "yaml", filepath.Join(dirPath, "list.yaml")}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "- 1\n- 2\n", stdout.String())
+
+	exitCode = run([]string{"convert", filepath.Join(dirPath, "bad.yaml")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "configset: convert yaml to json; ")
+	stderr.Reset()
+	exitCode = run([]string{"convert", "-to", "toml", filepath.Join(dirPath, "list.yaml")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "non-object value unsupported by dump format")
+	exitCode = run([]string{"convert", "-from", "xml", serverFilePath}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/go-tk/configset"
+)
+
+// runDiff prints the changes of values from the configuration of the first
+// directory to the one of the second directory, each with its own env files,
+// if any, so that reviews show semantic differences rather than text ones,
+// e.g. between environments:
+//
+//	configset diff -env-file-b prod.env ./etc ./etc
+//
+// The values of secrets, i.e. values decrypted or resolved from secret
+// references and values for the paths given with -secret-path, are masked.
+func runDiff(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset diff [flags] <dir a> <dir b>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	var envFilesA, envFilesB stringsFlag
+	flagSet.Var(&envFilesA, "env-file-a", "like -env-file but for the first directory only (repeatable)")
+	flagSet.Var(&envFilesB, "env-file-b", "like -env-file but for the second directory only (repeatable)")
+	var secretPaths stringsFlag
+	flagSet.Var(&secretPaths, "secret-path", "mark the values for `path` as secrets (repeatable)")
+	jsonOutput := flagSet.Bool("json", false, "print the changes as a JSON array")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 2 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	a, err := loadFlags.load(args[0], envFilesA...)
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	b, err := loadFlags.load(args[1], envFilesB...)
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	a.AddSecretPaths(secretPaths...)
+	b.AddSecretPaths(secretPaths...)
+	changes := configset.DiffRedacted(a, b)
+	if *jsonOutput {
+		if changes == nil {
+			changes = []configset.Change{}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			fmt.Fprintf(stderr, "configset: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	fmt.Fprint(stdout, configset.FormatChanges(changes))
+	return exitOK
+}
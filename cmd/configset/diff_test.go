@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunDiff(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"a/server.yaml": "port: 8080\nhost: localhost\n",
+		"b/server.yaml": "port: 8081\ntls: {cert: a.pem}\n",
+		"prod.env":      "CONFIGSET.server.port=80\n",
+	})
+	aDirPath := filepath.Join(dirPath, "a")
+	bDirPath := filepath.Join(dirPath, "b")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"diff", aDirPath, bDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, `- server.host="localhost"
+~ server.port=8080 -> 8081
++ server.tls={"cert":"a.pem"}
+`, stdout.String())
+
+	stdout.Reset()
+	exitCode = run([]string{"diff", "-env-file-b", filepath.Join(dirPath, "prod.env"), aDirPath, aDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "~ server.port=8080 -> 80\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"diff", "-env-file", filepath.Join(dirPath, "prod.env"), "-json", aDirPath, aDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "[]\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"diff", "-json", "-env-file-a", filepath.Join(dirPath, "prod.env"), aDirPath, aDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, `[
+  {
+    "kind": "modified",
+    "path": "server.port",
+    "oldValue": 80,
+    "newValue": 8080
+  }
+]
+`, stdout.String())
+
+	stdout.Reset()
+	exitCode = run([]string{"diff", "-secret-path", "server.port", aDirPath, bDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, `- server.host="localhost"
+~ server.port="******" -> "******"
++ server.tls={"cert":"a.pem"}
+`, stdout.String())
+
+	exitCode = run([]string{"diff", aDirPath, filepath.Join(dirPath, "c")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "configset: load config set: ")
+	exitCode = run([]string{"diff", aDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
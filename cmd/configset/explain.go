@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tidwall/gjson"
+)
+
+// runExplain prints the leaf values for the path, with environment variables
+// applied, each followed by the layer which supplied the value and the ones it
+// overrode, see configset.Explain, with the values of secrets masked, e.g.
+//
+//	server.port=8081
+//	  from env CONFIGSET.server.port=8081
+//	  overrode file /etc/app/server.yaml=8080
+func runExplain(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset explain [flags] <dir> <path>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 2 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	cs, err := loadFlags.load(args[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	explanations, err := cs.Explain(args[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	raw := cs.DumpRedacted("", "")
+	for _, explanation := range explanations {
+		fmt.Fprintf(stdout, "%s=%s\n", explanation.Path, gjson.GetBytes(raw, explanation.Path).Raw)
+		for i, origin := range explanation.Origins {
+			verb := "overrode"
+			if i == 0 {
+				verb = "from"
+			}
+			fmt.Fprintf(stdout, "  %s %s=%s\n", verb, origin, origin.Value)
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunExplain(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/defaults.yaml": "server: {port: 80, host: localhost}\n",
+		"etc/server.yaml":   "port: 8080\n",
+		"prod.env":          "CONFIGSET.server.port=8081\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"explain", "-env-file", filepath.Join(dirPath, "prod.env"), etcDirPath, "server"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, `server.host="localhost"
+  from defaultsFile `+filepath.Join(etcDirPath, "defaults.yaml")+`="localhost"
+server.port=8081
+  from env CONFIGSET.server.port=8081
+  overrode file `+filepath.Join(etcDirPath, "server.yaml")+`=8080
+  overrode defaultsFile `+filepath.Join(etcDirPath, "defaults.yaml")+`=80
+`, stdout.String())
+
+	exitCode = run([]string{"explain", etcDirPath, "server.tls"}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "value not found")
+	exitCode = run([]string{"explain", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// runLint reports suspicious configuration of the directory, i.e. duplicate
+// keys in files, keys unknown to the schema, if any, insecure files containing
+// secrets, see configset.WithSecretFilePolicy, and values whose types differ
+// from the ones of the values they override, e.g. a number in a file
+// overridden by a string in an environment variable.
+func runLint(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset lint [flags] <dir>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	schemaFilePath := flagSet.String("schema", "", "report keys unknown to the schema `file`")
+	var secretPaths stringsFlag
+	flagSet.Var(&secretPaths, "secret-path", "mark the values for `path` as secrets (repeatable)")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 1 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	dirPath := args[0]
+	var schema schema
+	if *schemaFilePath != "" {
+		schema, err = readSchema(*schemaFilePath)
+		if err != nil {
+			fmt.Fprintf(stderr, "configset: %v\n", err)
+			return exitFailure
+		}
+	}
+	problems, err := lintDuplicateKeys(dirPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	if len(problems) == 0 {
+		problems, err = lintConfigSet(&loadFlags, dirPath, schema, secretPaths)
+		if err != nil {
+			fmt.Fprintf(stderr, "configset: %v\n", err)
+			return exitFailure
+		}
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(stdout, problem)
+	}
+	if len(problems) >= 1 {
+		fmt.Fprintf(stderr, "configset: %d problem(s) found\n", len(problems))
+		return exitFailure
+	}
+	return exitOK
+}
+
+// lintDuplicateKeys reports duplicate keys in the *.yaml files under the given
+// directory and the directory _defaults, which fail loads.
+func lintDuplicateKeys(dirPath string) ([]string, error) {
+	var problems []string
+	for _, dirPath := range []string{dirPath, filepath.Join(dirPath, "_defaults")} {
+		fileInfos, err := ioutil.ReadDir(dirPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("read dir: %w", err)
+		}
+		for _, fileInfo := range fileInfos {
+			if fileInfo.IsDir() || !strings.HasSuffix(fileInfo.Name(), ".yaml") {
+				continue
+			}
+			filePath := filepath.Join(dirPath, fileInfo.Name())
+			data, err := ioutil.ReadFile(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("read file: %w", err)
+			}
+			var document yamlv3.Node
+			if err := yamlv3.Unmarshal(data, &document); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", filePath, err))
+				continue
+			}
+			problems = appendDuplicateKeys(problems, filePath, &document)
+		}
+	}
+	return problems, nil
+}
+
+func appendDuplicateKeys(problems []string, filePath string, node *yamlv3.Node) []string {
+	if node.Kind == yamlv3.MappingNode {
+		keyLines := make(map[string]int, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if line, ok := keyLines[keyNode.Value]; ok {
+				problems = append(problems, fmt.Sprintf("%s:%d: duplicate key %q, first defined on line %d", filePath, keyNode.Line, keyNode.Value, line))
+			} else {
+				keyLines[keyNode.Value] = keyNode.Line
+			}
+			problems = appendDuplicateKeys(problems, filePath, node.Content[i+1])
+		}
+		return problems
+	}
+	for _, childNode := range node.Content {
+		problems = appendDuplicateKeys(problems, filePath, childNode)
+	}
+	return problems
+}
+
+// lintConfigSet loads the config set from the given directory and reports the
+// keys unknown to the given schema, if any, insecure files containing secrets
+// and type mismatches between layers.
+func lintConfigSet(loadFlags *loadFlags, dirPath string, schema schema, secretPaths []string) ([]string, error) {
+	var problems []string
+	cs := loadFlags.newConfigSet(
+		configset.WithSecretFilePolicy(configset.SecretFileWarn),
+		configset.WithWarningHandler(func(warning error) {
+			problems = append(problems, warning.Error())
+		}),
+	)
+	cs.AddSecretPaths(secretPaths...)
+	environment, err := loadFlags.environment()
+	if err != nil {
+		return nil, err
+	}
+	if err := cs.Load(afero.NewOsFs(), dirPath, environment); err != nil {
+		return append(problems, fmt.Sprintf("load config set: %v", err)), nil
+	}
+	explanations, err := cs.Explain("")
+	if err != nil {
+		return nil, err
+	}
+	for _, explanation := range explanations {
+		origin := explanation.Origins[0]
+		if schema != nil && !schema.covers(explanation.Path) {
+			problems = append(problems, fmt.Sprintf("%s: unknown key, set by %s", explanation.Path, origin))
+		}
+		typeName := jsonTypeName(origin.Value)
+		for _, overriddenOrigin := range explanation.Origins[1:] {
+			if overriddenTypeName := jsonTypeName(overriddenOrigin.Value); overriddenTypeName != typeName {
+				problems = append(problems, fmt.Sprintf("%s: type mismatch, %s set by %s overrides %s set by %s", explanation.Path, typeName, origin, overriddenTypeName, overriddenOrigin))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// jsonTypeName returns the name of the type of the given value in form of JSON.
+func jsonTypeName(value []byte) string {
+	switch result := gjson.ParseBytes(value); result.Type {
+	case gjson.Null:
+		return "null"
+	case gjson.False, gjson.True:
+		return "boolean"
+	case gjson.Number:
+		return "number"
+	case gjson.String:
+		return "string"
+	default:
+		if result.IsArray() {
+			return "array"
+		}
+		return "object"
+	}
+}
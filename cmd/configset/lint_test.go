@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLint(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml":      "port: 8080\ntimeout: 30\n",
+		"etc/db.yaml":          "password: hunter2\n",
+		"prod.env":             "CONFIGSET.server.port=\"8081\"\nCONFIGSET.server.debug=true\n",
+		"schema.yaml":          "server.port: {}\nserver.timeout: {}\ndb: {}\n",
+		"dup/server.yaml":      "port: 8080\ntls:\n  cert: a.pem\n  cert: b.pem\nport: 8081\n",
+		"dup/_defaults/a.yaml": "a: 1\na: 2\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	if err := os.Chmod(filepath.Join(etcDirPath, "db.yaml"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"lint", "-schema", filepath.Join(dirPath, "schema.yaml"), "-secret-path", "db.password", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Empty(t, stdout.String())
+
+	if err := os.Chmod(filepath.Join(etcDirPath, "db.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	exitCode = run([]string{"lint", "-schema", filepath.Join(dirPath, "schema.yaml"), "-secret-path", "db.password", "-env-file", filepath.Join(dirPath, "prod.env"), etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Equal(t, `configset: insecure secret file; filePath="`+filepath.Join(etcDirPath, "db.yaml")+`" fileMode="-rw-r--r--": world-readable
+server.debug: unknown key, set by env CONFIGSET.server.debug
+server.port: type mismatch, string set by env CONFIGSET.server.port overrides number set by file `+filepath.Join(etcDirPath, "server.yaml")+`
+`, stdout.String())
+	assert.Equal(t, "configset: 3 problem(s) found\n", stderr.String())
+
+	stdout.Reset()
+	exitCode = run([]string{"lint", filepath.Join(dirPath, "dup")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Equal(t, filepath.Join(dirPath, "dup", "server.yaml")+`:4: duplicate key "cert", first defined on line 3
+`+filepath.Join(dirPath, "dup", "server.yaml")+`:5: duplicate key "port", first defined on line 1
+`+filepath.Join(dirPath, "dup", "_defaults", "a.yaml")+`:2: duplicate key "a", first defined on line 1
+`, stdout.String())
+	exitCode = run([]string{"lint"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
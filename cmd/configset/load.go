@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/go-tk/configset"
+	"github.com/spf13/afero"
+)
+
+// loadFlags represents the flags shared by the commands loading config sets.
+type loadFlags struct {
+	envFiles  stringsFlag
+	envPrefix string
+}
+
+func (lf *loadFlags) register(flagSet *flag.FlagSet) {
+	flagSet.Var(&lf.envFiles, "env-file", "read environment variables from `file`, in the form of KEY=VALUE lines, on top of the environment of the process (repeatable)")
+	flagSet.StringVar(&lf.envPrefix, "env-prefix", "", "set the `prefix` of environment variables overriding values (default \"CONFIGSET.\")")
+}
+
+// newConfigSet returns a new config set with the options set by the flags.
+func (lf *loadFlags) newConfigSet(options ...configset.Option) *configset.ConfigSet {
+	if lf.envPrefix != "" {
+		options = append(options, configset.WithEnvPrefix(lf.envPrefix))
+	}
+	return configset.New(options...)
+}
+
+// load returns a new config set loaded from the given directory with the
+// environment, like the library does, see environment.
+func (lf *loadFlags) load(dirPath string, extraEnvFilePaths ...string) (*configset.ConfigSet, error) {
+	environment, err := lf.environment(extraEnvFilePaths...)
+	if err != nil {
+		return nil, err
+	}
+	cs := lf.newConfigSet()
+	if err := cs.Load(afero.NewOsFs(), dirPath, environment); err != nil {
+		return nil, fmt.Errorf("load config set: %w", err)
+	}
+	return cs, nil
+}
+
+// environment returns the environment of the process with the environment
+// variables read from the env files, followed by the given extra env files,
+// appended, which take precedence.
+func (lf *loadFlags) environment(extraEnvFilePaths ...string) ([]string, error) {
+	environment := os.Environ()
+	envFilePaths := append(append([]string(nil), lf.envFiles...), extraEnvFilePaths...)
+	for _, envFilePath := range envFilePaths {
+		data, err := ioutil.ReadFile(envFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("read env file: %w", err)
+		}
+		kvs, err := parseEnvFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse env file; envFilePath=%q: %w", envFilePath, err)
+		}
+		environment = append(environment, kvs...)
+	}
+	return environment, nil
+}
+
+// parseEnvFile parses KEY=VALUE lines, skipping blank lines and comments
+// starting with #. Values are kept as they are, since they are YAML.
+func parseEnvFile(data []byte) ([]string, error) {
+	var kvs []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		if strings.IndexByte(line, '=') <= 0 {
+			return nil, fmt.Errorf("invalid line; lineNumber=%d", lineNumber)
+		}
+		kvs = append(kvs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return kvs, nil
+}
+
+// stringsFlag represents a repeatable flag.
+type stringsFlag []string
+
+func (sf *stringsFlag) String() string { return strings.Join(*sf, ",") }
+
+func (sf *stringsFlag) Set(value string) error {
+	*sf = append(*sf, value)
+	return nil
+}
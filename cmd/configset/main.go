@@ -0,0 +1,101 @@
+// Command configset inspects configuration directories the way the configset
+// library loads them, e.g. in CI before deploys.
+//
+// Usage:
+//
+//	configset <command> [flags] <arguments>
+//
+// The commands are:
+//
+//	validate   load a directory and report all the problems found
+//	get        print the effective value for a path
+//	render     print the effective configuration
+//	diff       print the changes between two directories or environments
+//	convert    convert a file or directory between formats
+//	explain    print which layers supplied the values for a path
+//	set        set a value in the configuration file it belongs to
+//	lint       report suspicious configuration
+//	watch      print changes and run a command on changes of a directory
+//
+// Run "configset <command> -h" for the flags of a command.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+func main() { os.Exit(run(os.Args[1:], os.Stdout, os.Stderr)) }
+
+// command represents a subcommand, which returns the exit code.
+type command func(args []string, stdout io.Writer, stderr io.Writer) int
+
+var commands = map[string]command{
+	"validate": runValidate,
+	"get":      runGet,
+	"render":   runRender,
+	"diff":     runDiff,
+	"convert":  runConvert,
+	"explain":  runExplain,
+	"set":      runSet,
+	"lint":     runLint,
+	"watch":    runWatch,
+}
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "configset: unknown command %q\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+	return command(args[1:], stdout, stderr)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: configset <command> [flags] <arguments>")
+	fmt.Fprintln(w, "commands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+}
+
+// parseArgs parses the flags, which may be interspersed with the arguments,
+// e.g. "set <path> <value> -dir ./etc", and returns the arguments.
+func parseArgs(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positionalArgs []string
+	for {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		n := len(args) - flagSet.NArg()
+		if n >= 1 && args[n-1] == "--" {
+			// The flag terminator "--" ends parsing, e.g. for negative numbers.
+			return append(positionalArgs, flagSet.Args()...), nil
+		}
+		args = flagSet.Args()
+		if len(args) == 0 {
+			return positionalArgs, nil
+		}
+		positionalArgs = append(positionalArgs, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	exitCode := run(nil, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr.String(), "usage: configset <command>")
+
+	stderr.Reset()
+	exitCode = run([]string{"foo"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+	assert.Contains(t, stderr.String(), `configset: unknown command "foo"`)
+}
+
+// writeFiles writes the given files, whose keys are file paths relative to a
+// new temporary directory, and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dirPath := t.TempDir()
+	for filePath, data := range files {
+		filePath = filepath.Join(dirPath, filePath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dirPath
+}
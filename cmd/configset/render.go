@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/go-tk/configset"
+)
+
+// runGet prints the effective value for the path, with environment variables
+// applied, as the application will see it.
+func runGet(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("get", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset get [flags] <dir> <path>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	format := flagSet.String("format", "json", "print the value in `format`, i.e. json, yaml, flat, toml or dotenv")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 2 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	return dump(&loadFlags, args[0], args[1], configset.DumpFormat(*format), stdout, stderr)
+}
+
+// runRender prints the effective configuration, with environment variables
+// applied, as the application will see it.
+func runRender(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("render", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset render [flags] <dir>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	format := flagSet.String("format", "json", "print the configuration in `format`, i.e. json, yaml, flat, toml or dotenv")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 1 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	return dump(&loadFlags, args[0], "", configset.DumpFormat(*format), stdout, stderr)
+}
+
+func dump(loadFlags *loadFlags, dirPath string, path string, format configset.DumpFormat, stdout io.Writer, stderr io.Writer) int {
+	cs, err := loadFlags.load(dirPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	dumpOptions := configset.DumpOptions{
+		Format: format,
+		Path:   path,
+	}
+	if format == configset.DumpFormatJSON {
+		dumpOptions.Indention = "  "
+	}
+	if err := cs.DumpTo(stdout, dumpOptions); err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunGet(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "port: 8080\ntls: {cert: a.pem}\n",
+		"prod.env":        "CONFIGSET.server.port=8081\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"get", "-env-file", filepath.Join(dirPath, "prod.env"), etcDirPath, "server.port"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "8081\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"get", "-format", "yaml", etcDirPath, "server.tls"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "cert: a.pem\n", stdout.String())
+
+	exitCode = run([]string{"get", etcDirPath, "server.host"}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Equal(t, "configset: configset: value not found; path=\"server.host\"\n", stderr.String())
+	stderr.Reset()
+	exitCode = run([]string{"get", filepath.Join(dirPath, "nonexistent"), "server"}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "configset: load config set: ")
+	exitCode = run([]string{"get", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
+
+func TestRunRender(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "port: 8080\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"render", "-env-prefix", "MYAPP.", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "{\n  \"server\": {\n    \"port\": 8080\n  }\n}\n", stdout.String())
+	stdout.Reset()
+	exitCode = run([]string{"render", "-format", "toml", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Equal(t, "[server]\nport = 8080\n", stdout.String())
+	exitCode = run([]string{"render", "-format", "xml", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), `unknown dump format; format="xml"`)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-tk/configset"
+	"sigs.k8s.io/yaml"
+)
+
+// schemaEntry represents the constraints on the value for a path in a schema
+// file, e.g.
+//
+//	server.port:
+//	  required: true
+//	  min: 1
+//	  max: 65535
+//	server.mode:
+//	  in: [dev, prod]
+//	server.host:
+//	  notEmpty: true
+//	  matches: ^[a-z.]+$
+type schemaEntry struct {
+	Required bool          `json:"required"`
+	NotEmpty bool          `json:"notEmpty"`
+	Min      *float64      `json:"min"`
+	Max      *float64      `json:"max"`
+	In       []interface{} `json:"in"`
+	Matches  string        `json:"matches"`
+}
+
+// schema represents a schema file, whose keys are paths.
+type schema map[string]schemaEntry
+
+// readSchema reads the given schema file.
+func readSchema(schemaFilePath string) (schema, error) {
+	data, err := ioutil.ReadFile(schemaFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file: %w", err)
+	}
+	var schema schema
+	if err := yaml.UnmarshalStrict(data, &schema); err != nil {
+		return nil, fmt.Errorf("parse schema file; schemaFilePath=%q: %w", schemaFilePath, err)
+	}
+	return schema, nil
+}
+
+// rules returns the rules of the schema, sorted by path.
+func (s schema) rules() []configset.Rule {
+	var paths []string
+	for path := range s {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var rules []configset.Rule
+	for _, path := range paths {
+		rules = append(rules, configset.Assert(path, s[path].checks()...))
+	}
+	return rules
+}
+
+// covers reports whether the given path is the path of an entry of the schema
+// or within one.
+func (s schema) covers(path string) bool {
+	for {
+		if _, ok := s[path]; ok {
+			return true
+		}
+		i := strings.LastIndexByte(path, '.')
+		for i >= 1 && path[i-1] == '\\' {
+			i = strings.LastIndexByte(path[:i-1], '.')
+		}
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+func (se schemaEntry) checks() []configset.Check {
+	var checks []configset.Check
+	if se.Required {
+		checks = append(checks, configset.Required())
+	}
+	if se.NotEmpty {
+		checks = append(checks, configset.NotEmpty())
+	}
+	if se.Min != nil || se.Max != nil {
+		min, max := math.Inf(-1), math.Inf(1)
+		if se.Min != nil {
+			min = *se.Min
+		}
+		if se.Max != nil {
+			max = *se.Max
+		}
+		checks = append(checks, configset.Between(min, max))
+	}
+	if se.In != nil {
+		checks = append(checks, configset.In(se.In...))
+	}
+	if se.Matches != "" {
+		checks = append(checks, configset.Matches(se.Matches))
+	}
+	return checks
+}
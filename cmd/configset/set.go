@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"sigs.k8s.io/yaml"
+)
+
+// runSet sets the value for the path to the value in form of YAML in the
+// configuration file it belongs to, preserving comments, see
+// configset.SetInFile, e.g.
+//
+//	configset set server.port 8081 -dir ./etc
+func runSet(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("set", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset set [flags] <path> <yaml value>")
+		flagSet.PrintDefaults()
+	}
+	dirPath := flagSet.String("dir", ".", "edit the configuration files under `dir`")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 2 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	path := args[0]
+	value, err := yaml.YAMLToJSON([]byte(args[1]))
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: convert yaml to json; value=%q: %v\n", args[1], err)
+		return exitFailure
+	}
+	if err := configset.SetInFile(afero.NewOsFs(), *dirPath, path, json.RawMessage(value)); err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
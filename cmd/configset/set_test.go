@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSet(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "# server\nport: 8080 # the port\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"set", "server.port", "8081", "--dir", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	exitCode = run([]string{"set", "-dir", etcDirPath, "server.tags", "[a, b]"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	exitCode = run([]string{"set", "-dir", etcDirPath, "--", "server.offset", "-1"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	data, err := ioutil.ReadFile(filepath.Join(etcDirPath, "server.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "# server\nport: 8081 # the port\ntags:\n- a\n- b\noffset: -1\n", string(data))
+
+	exitCode = run([]string{"set", "-dir", etcDirPath, "server.port", "{"}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "configset: convert yaml to json; ")
+	exitCode = run([]string{"set", "server.port"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// runValidate loads the directory like the library does, with the rules of the
+// schema file, if any, and reports all the problems found, see configset.Vet.
+func runValidate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset validate [flags] <dir>")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	schemaFilePath := flagSet.String("schema", "", "check values against the rules in the schema `file`")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 1 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	dirPath := args[0]
+	environment, err := loadFlags.environment()
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	cs := loadFlags.newConfigSet()
+	if *schemaFilePath != "" {
+		schema, err := readSchema(*schemaFilePath)
+		if err != nil {
+			fmt.Fprintf(stderr, "configset: %v\n", err)
+			return exitFailure
+		}
+		cs.AddRules(schema.rules()...)
+	}
+	report := cs.Vet(afero.NewOsFs(), dirPath, environment)
+	for _, problem := range report.Problems {
+		if problem.Path == "" {
+			fmt.Fprintln(stdout, problem.Message)
+		} else {
+			fmt.Fprintf(stdout, "%s: %s\n", problem.Path, problem.Message)
+		}
+	}
+	if !report.OK() {
+		fmt.Fprintf(stderr, "configset: %d problem(s) found\n", len(report.Problems))
+		return exitFailure
+	}
+	return exitOK
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunValidate(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "port: 8080\nmode: test\n",
+		"prod.env":        "# production\nexport CONFIGSET.server.port=70000\n\nCONFIGSET.server.mode=prod\n",
+		"schema.yaml":     "server.port: {required: true, min: 1, max: 65535}\nserver.mode: {in: [dev, prod]}\nserver.host: {required: true}\n",
+		"bad.env":         "CONFIGSET.server.port\n",
+		"bad_schema.yaml": "server.port: {requried: true}\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	var stdout, stderr bytes.Buffer
+	exitCode := run([]string{"validate", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, exitCode)
+	assert.Empty(t, stdout.String())
+
+	exitCode = run([]string{"validate", "-schema", filepath.Join(dirPath, "schema.yaml"), "-env-file", filepath.Join(dirPath, "prod.env"), etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Equal(t, `server.host: value is required
+server.port: value 70000 is not between 1 and 65535
+`, stdout.String())
+	assert.Equal(t, "configset: 2 problem(s) found\n", stderr.String())
+
+	stderr.Reset()
+	exitCode = run([]string{"validate", "-env-file", filepath.Join(dirPath, "bad.env"), etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "invalid line; lineNumber=1")
+	stderr.Reset()
+	exitCode = run([]string{"validate", "-schema", filepath.Join(dirPath, "bad_schema.yaml"), etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "parse schema file")
+	exitCode = run([]string{"validate"}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-tk/configset"
+	"github.com/spf13/afero"
+)
+
+// runWatch watches the directory, see configset.Watch, and on every change of
+// the configuration prints the changes of values and runs the command, if any,
+// until interrupted, e.g. during local development:
+//
+//	configset watch -dir ./etc -exec ./reload.sh
+func runWatch(args []string, stdout io.Writer, stderr io.Writer) int {
+	flagSet := flag.NewFlagSet("watch", flag.ContinueOnError)
+	flagSet.SetOutput(stderr)
+	flagSet.Usage = func() {
+		fmt.Fprintln(stderr, "usage: configset watch [flags]")
+		flagSet.PrintDefaults()
+	}
+	var loadFlags loadFlags
+	loadFlags.register(flagSet)
+	dirPath := flagSet.String("dir", ".", "watch the configuration files under `dir`")
+	command := flagSet.String("exec", "", "run `command` with the shell on every change")
+	interval := flagSet.Duration("interval", time.Second, "poll the directory at `interval` if the file system provides no change notifications")
+	quietPeriod := flagSet.Duration("quiet-period", 100*time.Millisecond, "coalesce changes within `period` into one reload")
+	args, err := parseArgs(flagSet, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(args) != 0 {
+		flagSet.Usage()
+		return exitUsage
+	}
+	environment, err := loadFlags.environment()
+	if err != nil {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	var mutex sync.Mutex
+	var previous *configset.ConfigSet
+	var cs *configset.ConfigSet
+	cs = loadFlags.newConfigSet(
+		configset.WithWatchInterval(*interval),
+		configset.WithWatchQuietPeriod(*quietPeriod),
+		configset.WithReloadErrorHandler(func(err error) {
+			fmt.Fprintf(stderr, "configset: reload config set: %v\n", err)
+		}),
+		configset.WithAfterReloadHook(func(*configset.Snapshot, *configset.Snapshot) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			current := cs.Clone()
+			changes := configset.Diff(previous, current)
+			previous = current
+			if len(changes) == 0 {
+				return
+			}
+			fmt.Fprint(stdout, configset.FormatChanges(changes))
+			if *command != "" {
+				if err := runCommand(*command, stdout, stderr); err != nil {
+					fmt.Fprintf(stderr, "configset: run command; command=%q: %v\n", *command, err)
+				}
+			}
+		}),
+	)
+	if err := cs.Load(afero.NewOsFs(), *dirPath, environment); err != nil {
+		fmt.Fprintf(stderr, "configset: load config set: %v\n", err)
+		return exitFailure
+	}
+	mutex.Lock()
+	previous = cs.Clone()
+	mutex.Unlock()
+	ctx, cancel := watchContext()
+	defer cancel()
+	if err := cs.Watch(ctx); !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(stderr, "configset: %v\n", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// watchContext returns the context for watching, which is canceled on
+// interrupts.
+var watchContext = func() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+func runCommand(command string, stdout io.Writer, stderr io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
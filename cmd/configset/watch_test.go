@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWatch(t *testing.T) {
+	dirPath := writeFiles(t, map[string]string{
+		"etc/server.yaml": "port: 8080\n",
+	})
+	etcDirPath := filepath.Join(dirPath, "etc")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func(oldWatchContext func() (context.Context, context.CancelFunc)) { watchContext = oldWatchContext }(watchContext)
+	watchContext = func() (context.Context, context.CancelFunc) { return ctx, cancel }
+	var stdout, stderr lockedBuffer
+	exitCodes := make(chan int, 1)
+	go func() {
+		exitCodes <- run([]string{"watch", "-dir", etcDirPath, "-interval", "10ms", "-quiet-period", "0", "-exec", "echo reloaded"}, &stdout, &stderr)
+	}()
+	for i := 0; i < 100 && !strings.Contains(stdout.String(), "reloaded"); i++ {
+		// Replace the file atomically, since the watcher may otherwise read it
+		// truncated in the middle of writing.
+		tempFilePath := filepath.Join(dirPath, "server.yaml")
+		if err := ioutil.WriteFile(tempFilePath, []byte(fmt.Sprintf("port: %d\n", 8081+i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tempFilePath, filepath.Join(etcDirPath, "server.yaml")); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	assert.Equal(t, exitOK, <-exitCodes)
+	assert.Regexp(t, `^~ server\.port=80\d\d -> 80\d\d\nreloaded\n`, stdout.String())
+	assert.Empty(t, stderr.String())
+
+	exitCode := run([]string{"watch", "-dir", filepath.Join(dirPath, "nonexistent")}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, exitCode)
+	assert.Contains(t, stderr.String(), "configset: load config set: ")
+	exitCode = run([]string{"watch", etcDirPath}, &stdout, &stderr)
+	assert.Equal(t, exitUsage, exitCode)
+}
+
+type lockedBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (lb *lockedBuffer) Write(p []byte) (int, error) {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buffer.Write(p)
+}
+
+func (lb *lockedBuffer) String() string {
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	return lb.buffer.String()
+}
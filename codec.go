@@ -0,0 +1,32 @@
+package configset
+
+import "encoding/json"
+
+// JSONCodec represents an encoder and decoder of JSON, e.g. of the package
+// encoding/json, which is the default, or a faster drop-in replacement such as
+// jsoniter or go-json.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// WithJSONCodec returns an option that sets the codec used to decode values into
+// configs, see ReadValue, and to encode configs where needed, e.g. with
+// DecodeMerge and BindAndWatch. The codec takes effect from the next load.
+func WithJSONCodec(jsonCodec JSONCodec) Option {
+	return func(options *options) { options.jsonCodec = jsonCodec }
+}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (stdJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// jsonCodec returns the codec set by WithJSONCodec, or the codec of the package
+// encoding/json.
+func (cs *ConfigSet) jsonCodec() JSONCodec {
+	if cs.options.jsonCodec == nil {
+		return stdJSONCodec{}
+	}
+	return cs.options.jsonCodec
+}
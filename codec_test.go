@@ -0,0 +1,47 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingJSONCodec struct {
+	marshalCount   int32
+	unmarshalCount int32
+}
+
+func (cjc *countingJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&cjc.marshalCount, 1)
+	return json.Marshal(v)
+}
+
+func (cjc *countingJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&cjc.unmarshalCount, 1)
+	return json.Unmarshal(data, v)
+}
+
+func TestWithJSONCodec(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var jsonCodec countingJSONCodec
+	var cs ConfigSet
+	cs.Configure(WithJSONCodec(&jsonCodec), WithDecodeMode(DecodeMerge))
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	var port int
+	err = cs.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+	assert.Equal(t, int32(1), jsonCodec.marshalCount)
+	assert.Equal(t, int32(1), jsonCodec.unmarshalCount)
+}
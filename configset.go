@@ -2,26 +2,55 @@ package configset
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/tidwall/gjson"
-	"github.com/tidwall/sjson"
 	"sigs.k8s.io/yaml"
 )
 
-var cs configSet
+var cs = new(ConfigSet)
 
 // Load loads the config set from all *.yaml files under the given directory.
+// The file defaults.yaml and the *.yaml files under the directory _defaults are
+// reserved for default values, which are deep-merged beneath the other files.
+// The file _shared.yaml is reserved for fragments reused across files, which
+// objects use with the key `$use`.
+// The file overrides.yaml is reserved for overrides, which are applied last as
+// a JSON merge patch (RFC 7386), see WithPersistentOverrides.
 // If there are environment variables set such as CONFIGSET.{path}={value},
 // the config set will be overwritten according to {paths} and {values}.
-func Load(dirPath string) error { return cs.Load(afero.NewOsFs(), dirPath, os.Environ()) }
+// Placeholders such as ${ref:{path}} within string values from the files, or
+// default values, are resolved against the config set after all the layers have
+// been applied, so that repeated values can be defined once, e.g.
+//
+//	baseURL: https://example.com
+//	loginURL: ${ref:common.baseURL}/login
+//
+// A string value consisting of a placeholder only is replaced with the value
+// referenced, keeping its type. `$${` escapes `${`.
+// Placeholders such as ${file:{path}} are replaced with the contents of the
+// files, relative to the directory unless absolute, e.g. for certificates. The
+// files must be under the directory or the directories set by WithFileRoots.
+// Placeholders such as ${expr:{expression}} are replaced with the results of
+// simple expressions for derived values, e.g. "${expr: .replicas * 2}", where
+// references starting with a dot are relative to the enclosing object.
+func Load(dirPath string) error { return cs.Load(afero.NewOsFs(), dirPath, cs.environment()) }
+
+// LoadContext likes Load but bounds the load, including fetching configs from
+// remote sources, with the given context.
+func LoadContext(ctx context.Context, dirPath string) error {
+	return cs.LoadContext(ctx, afero.NewOsFs(), dirPath, cs.environment())
+}
 
 // MustLoad likes Load but panics when an error occurs.
 func MustLoad(dirPath string) {
@@ -32,9 +61,23 @@ func MustLoad(dirPath string) {
 
 // ReadValue finds the value for the given path from the config set and
 // unmarshals the given config from that value in form of JSON.
-// If no value can be found by the path, ErrValueNotFound is returned.
+// If no value can be found by the path, ErrValueNotFound is returned, or if the
+// config set has not been loaded, ErrNotLoaded is returned.
+// Struct fields missing in the value are set to the default values specified by
+// the `default` struct tags, if any, e.g.
+//
+//	Port int `json:"port" default:"8080"`
+//
+// If the config set has not been loaded but the directory has been set with
+// WithDir, the first call loads the config set, see Open, so that libraries
+// reading values during initialization don't depend on the order of calls.
+// If the load fails, the next call tries again.
 func ReadValue(path string, config interface{}) error { return cs.ReadValue(path, config) }
 
+// IsLoaded reports whether the config set has been loaded successfully, e.g.
+// with Load or Open, rather than only assigned values with Set or MergeAt.
+func IsLoaded() bool { return cs.IsLoaded() }
+
 // MustReadValue likes ReadValue but panics when an error occurs.
 func MustReadValue(path string, config interface{}) {
 	if err := ReadValue(path, config); err != nil {
@@ -42,32 +85,343 @@ func MustReadValue(path string, config interface{}) {
 	}
 }
 
-// Dump returns the config set in form of JSON.
+// Dump returns the config set in form of JSON, in the canonical form, i.e. with
+// the keys of objects sorted and the formatting stable, so that dumps of equal
+// config sets are identical, e.g. for golden-file tests and digests.
 func Dump(prefix string, indention string) json.RawMessage { return cs.Dump(prefix, indention) }
 
-type configSet struct {
-	raw json.RawMessage
+// Bytes likes Dump without prefix and indention but returns the config set
+// without copying it, which spares large config sets dumped frequently, e.g. on
+// every scrape, from allocations. The returned bytes are shared and MUST NOT be
+// modified. If the config set has not been loaded, nil is returned.
+func Bytes() []byte { return cs.Bytes() }
+
+// ConfigSet represents a config set. The package-level functions operate on the
+// global config set, whereas a ConfigSet can be used on its own, e.g. in tests.
+// The zero value is an empty config set ready to use, see also New.
+type ConfigSet struct {
+	mutex           sync.Mutex
+	options         options
+	rules           []Rule
+	validators      []Validator
+	types           []typeRegistration
+	defaults        json.RawMessage
+	secretProviders map[string]SecretProvider
+	secretPaths     []string
+	secretValues    []secretValue
+	secretRefs      []secretRef
+	input           loadInput
+	dirState        string
+	digest          string
+	yamlFiles       map[string][]byte
+	overrides       json.RawMessage
+	loaded          bool
+	provenance      *provenanceNode
+	mergeTrace      []MergeStep
+	snapshot        atomic.Value
+	history         []Generation
+	generationCount int
+	loadStats       loadStats
+	subscriptions   subscriptions
+
+	// closureMutex guards closed and closure apart from mutex, which loads hold
+	// throughout, so that Close can abort the loads in flight.
+	closureMutex sync.Mutex
+	closed       bool
+	closure      chan struct{}
+	backgroundWG sync.WaitGroup
+
+	// autoLoadMutex guards autoLoaded apart from mutex, which Open acquires.
+	autoLoadMutex sync.Mutex
+	autoLoaded    bool
+}
+
+type loadInput struct {
+	fs          afero.Fs
+	dirPath     string
+	environment []string
+}
+
+func (cs *ConfigSet) Load(fs afero.Fs, dirPath string, environment []string) error {
+	return cs.LoadContext(context.Background(), fs, dirPath, environment)
 }
 
-func (cs *configSet) Load(fs afero.Fs, dirPath string, environment []string) error {
-	raw, err := aggregateConfigs(fs, dirPath)
+func (cs *ConfigSet) LoadContext(ctx context.Context, fs afero.Fs, dirPath string, environment []string) error {
+	return cs.load(ctx, &loadInput{
+		fs:          fs,
+		dirPath:     dirPath,
+		environment: environment,
+	})
+}
+
+// load loads the config set with the given input, or reloads the config set
+// with the last input if the given input is nil.
+func (cs *ConfigSet) load(ctx context.Context, input *loadInput) error {
+	cs.mutex.Lock()
+	beforeLoadHook := cs.options.beforeLoadHook
+	cs.mutex.Unlock()
+	if beforeLoadHook != nil {
+		if err := beforeLoadHook(ctx); err != nil {
+			err = fmt.Errorf("run before-load hook: %w", err)
+			cs.mutex.Lock()
+			cs.recordLoad(0, err)
+			cs.mutex.Unlock()
+			return err
+		}
+	}
+	cs.mutex.Lock()
+	if input != nil {
+		cs.input = *input
+	}
+	oldSnapshot := cs.Snapshot()
+	startTime := time.Now()
+	ctx, span := cs.tracer().StartSpan(ctx, "configset.load")
+	span.SetAttribute("configset.reload", input == nil)
+	err := cs.doLoad(ctx, span)
+	span.End(err)
+	cs.recordLoad(time.Since(startTime), err)
+	newSnapshot := cs.Snapshot()
+	environment := cs.input.environment
+	auditSink := cs.options.auditSink
+	envPrefix := cs.envPrefix()
+	generationCount := cs.generationCount
+	logger := cs.logger()
+	afterLoadHook := cs.options.afterLoadHook
+	afterReloadHook := cs.options.afterReloadHook
+	cs.mutex.Unlock()
 	if err != nil {
 		return err
 	}
-	raw, err = overwriteConfigSet(raw, environment)
+	logger.Info("configset: loaded", "generation", generationCount, "fingerprint", newSnapshot.Fingerprint())
+	if oldSnapshot.raw != nil {
+		cs.subscriptions.Notify(oldSnapshot.raw, newSnapshot.raw)
+	}
+	if auditSink != nil {
+		auditLoad(auditSink, environment, envPrefix, oldSnapshot.raw, newSnapshot.raw)
+	}
+	if afterLoadHook != nil {
+		afterLoadHook(newSnapshot)
+	}
+	if afterReloadHook != nil && oldSnapshot.raw != nil {
+		afterReloadHook(oldSnapshot, newSnapshot)
+	}
+	return nil
+}
+
+func (cs *ConfigSet) doLoad(ctx context.Context, span Span) error {
+	if cs.input.fs == nil {
+		return ErrNotLoaded
+	}
+	dirState, err := statDir(cs.input.fs, cs.input.dirPath)
 	if err != nil {
 		return err
 	}
-	cs.raw = raw
+	if err := cs.checkFileSizes(cs.input.fs, cs.input.dirPath); err != nil {
+		return err
+	}
+	fs := cs.input.fs
+	var fileCopies afero.Fs
+	if cs.verifiesFiles() {
+		fileCopies = afero.NewMemMapFs()
+	}
+	manifest, err := makeManifest(fs, cs.input.dirPath, fileCopies)
+	if err != nil {
+		return err
+	}
+	if cs.options.signatureKey != nil {
+		if err := verifySignature(fs, cs.input.dirPath, manifest, cs.options.signatureKey); err != nil {
+			return err
+		}
+	}
+	if cs.options.checksumManifest {
+		if err := validateChecksums(fs, cs.input.dirPath, manifest); err != nil {
+			return err
+		}
+	}
+	if fileCopies != nil {
+		// Load the files verified rather than the ones on the file system, which
+		// might have changed since.
+		fs = fileCopies
+	}
+	span.SetAttribute("configset.file_count", bytes.Count(manifest, []byte("\n")))
+	result, err := cs.build(ctx, fs, cs.input.dirPath, cs.input.environment)
+	if err != nil {
+		return err
+	}
+	raw := result.raw
+	span.SetAttribute("configset.byte_size", len(raw))
+	span.AddEvent("configset.built")
+	if err := cs.check(raw, result.provenance); err != nil {
+		return err
+	}
+	var yamlFiles map[string][]byte
+	if cs.options.roundTrip {
+		yamlFiles, err = readYAMLFiles(fs, cs.input.dirPath)
+		if err != nil {
+			return err
+		}
+	}
+	if oldRaw := cs.Snapshot().raw; oldRaw != nil && cs.options.typeStabilityCheck {
+		if err := checkTypeStability(oldRaw, raw); err != nil {
+			return err
+		}
+	}
+	cs.dirState = dirState
+	cs.digest = digestManifest(manifest)
+	cs.yamlFiles = yamlFiles
+	cs.overrides = result.overrides
+	cs.secretValues = result.secretValues
+	cs.secretRefs = result.secretRefs
+	cs.provenance = result.provenance
+	cs.mergeTrace = result.mergeTrace
+	cs.loaded = true
+	cs.storeSnapshot(raw)
+	return nil
+}
+
+// verifiesFiles reports whether loads verify the configuration files, see
+// WithSignatureKey and WithChecksumManifest.
+func (cs *ConfigSet) verifiesFiles() bool {
+	return cs.options.signatureKey != nil || cs.options.checksumManifest
+}
+
+// check checks the given config set against the rules and validators. Rule
+// violations are annotated with the origins of the values from the given
+// provenance, if any.
+func (cs *ConfigSet) check(rawConfigSet json.RawMessage, provenance *provenanceNode) error {
+	if ruleViolations := checkRules(rawConfigSet, cs.rules); len(ruleViolations) >= 1 {
+		ruleViolation := ruleViolations[0]
+		if origin, err := provenance.lookUpOrigin(ruleViolation.path); err == nil {
+			return fmt.Errorf("%w; path=%q origin=%q: %v", ErrRuleViolation, ruleViolation.path, origin, ruleViolation.err)
+		}
+		return fmt.Errorf("%w; path=%q: %v", ErrRuleViolation, ruleViolation.path, ruleViolation.err)
+	}
+	if errs := runValidators(rawConfigSet, cs.validators); len(errs) >= 1 {
+		return fmt.Errorf("validate config set: %w", errs[0])
+	}
 	return nil
 }
 
-func aggregateConfigs(fs afero.Fs, dirPath string) (json.RawMessage, error) {
+type buildResult struct {
+	raw          json.RawMessage
+	overrides    json.RawMessage
+	secretValues []secretValue
+	secretRefs   []secretRef
+	provenance   *provenanceNode
+	mergeTrace   []MergeStep
+}
+
+func (cs *ConfigSet) build(ctx context.Context, fs afero.Fs, dirPath string, environment []string) (buildResult, error) {
+	provenance := new(provenanceNode)
+	var mergeTrace []MergeStep
+	if cs.options.mergeTrace {
+		provenance.trace = &mergeTrace
+	}
+	if cs.defaults != nil {
+		provenance.mergeValue(gjson.ParseBytes(cs.defaults), Origin{Layer: OriginDefaults}, false)
+	}
+	var secretValues []secretValue
+	raw, overrides, err := cs.aggregateConfigs(fs, dirPath, environment, provenance, &secretValues)
+	if err != nil {
+		return buildResult{}, err
+	}
+	if cs.defaults != nil {
+		raw = mergeJSON(cs.defaults, raw)
+	}
+	raw, err = fetchConfigs(ctx, raw, cs.options.sources, cs.tracer(), provenance)
+	if err != nil {
+		return buildResult{}, err
+	}
+	cs.logEnviron(environment)
+	raw, err = overwriteConfigSet(raw, environment, cs.envPrefix(), provenance)
+	if err != nil {
+		return buildResult{}, err
+	}
+	if overrides != nil {
+		raw = applyMergePatch(raw, overrides)
+		provenance.patchValue(gjson.ParseBytes(overrides), Origin{
+			Layer: OriginOverrides,
+			Name:  filepath.Join(dirPath, overridesConfigName+".yaml"),
+		})
+	}
+	raw, err = patchConfigSet(raw, cs.options.flagPatches, OriginFlag, provenance)
+	if err != nil {
+		return buildResult{}, err
+	}
+	if cs.options.decryptor != nil {
+		var decryptedValues []secretValue
+		raw, decryptedValues, err = decryptValues(raw, cs.options.decryptor)
+		if err != nil {
+			return buildResult{}, err
+		}
+		secretValues = append(secretValues, decryptedValues...)
+	}
+	placeholderFS := fs
+	if cs.verifiesFiles() {
+		// Inlined files are not covered by the signature or the checksums.
+		placeholderFS = nil
+	}
+	raw, err = resolvePlaceholders(placeholderFS, dirPath, cs.options.fileRoots, raw, provenance)
+	if err != nil {
+		return buildResult{}, err
+	}
+	var secretRefs []secretRef
+	if !cs.options.secretsOnDemand {
+		raw, secretRefs, err = resolveSecretRefs(ctx, raw, cs.secretProviders)
+		if err != nil {
+			return buildResult{}, err
+		}
+	}
+	return buildResult{
+		raw:          raw,
+		overrides:    overrides,
+		secretValues: secretValues,
+		secretRefs:   secretRefs,
+		provenance:   provenance,
+		mergeTrace:   mergeTrace,
+	}, nil
+}
+
+func (cs *ConfigSet) aggregateConfigs(fs afero.Fs, dirPath string, environment []string, provenance *provenanceNode,
+	secretValues *[]secretValue) (json.RawMessage, json.RawMessage, error) {
+	rawConfigs, err := cs.readConfigs(fs, dirPath, environment, secretValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	rawOverrides := rawConfigs[overridesConfigName]
+	delete(rawConfigs, overridesConfigName)
+	fragmentExpander := newFragmentExpander(rawConfigs[sharedConfigName])
+	delete(rawConfigs, sharedConfigName)
+	rawDefaults, err := cs.readDefaults(fs, dirPath, environment, rawConfigs, fragmentExpander, provenance, secretValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	for configName, rawConfig := range rawConfigs {
+		rawConfig, err = fragmentExpander.expandConfigs(joinPath("", configName), rawConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		rawConfigs[configName] = rawConfig
+		provenance.child(configName).mergeValue(gjson.ParseBytes(rawConfig), Origin{
+			Layer: OriginFile,
+			Name:  filepath.Join(dirPath, configName+".yaml"),
+		}, false)
+	}
+	rawConfigSet := assembleObject(rawConfigs)
+	if rawDefaults != nil {
+		rawConfigSet = mergeJSON(rawDefaults, rawConfigSet)
+	}
+	return rawConfigSet, rawOverrides, nil
+}
+
+// readConfigs reads the configs from the *.yaml files under the given directory,
+// and appends the values of SOPS-encrypted files to the given secret values.
+func (cs *ConfigSet) readConfigs(fs afero.Fs, dirPath string, environment []string, secretValues *[]secretValue) (map[string]json.RawMessage, error) {
 	fileInfoSet, err := afero.ReadDir(fs, dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("read dir; dirPath=%q: %w", dirPath, err)
 	}
-	rawConfigs := make(map[string]json.RawMessage)
+	var configNames []string
 	for _, fileInfo := range fileInfoSet {
 		if fileInfo.IsDir() {
 			continue
@@ -75,52 +429,215 @@ func aggregateConfigs(fs afero.Fs, dirPath string) (json.RawMessage, error) {
 		fileName := fileInfo.Name()
 		configName := strings.TrimSuffix(fileName, ".yaml")
 		if len(configName) == len(fileName) {
+			cs.logger().Debug("configset: skip non-yaml file", "filePath", filepath.Join(dirPath, fileName))
 			continue
 		}
-		filePath := filepath.Join(dirPath, fileName)
-		rawConfig, err := afero.ReadFile(fs, filePath)
-		if err != nil {
-			return nil, fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+		configNames = append(configNames, configName)
+	}
+	type result struct {
+		rawConfig json.RawMessage
+		isSOPS    bool
+		err       error
+	}
+	results := make([]result, len(configNames))
+	concurrency := cs.options.loadConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var waitGroup sync.WaitGroup
+	for i, configName := range configNames {
+		semaphore <- struct{}{}
+		waitGroup.Add(1)
+		go func(result *result, filePath string) {
+			defer func() {
+				<-semaphore
+				waitGroup.Done()
+			}()
+			result.rawConfig, result.isSOPS, result.err = cs.readConfig(fs, filePath, environment)
+		}(&results[i], filepath.Join(dirPath, configName+".yaml"))
+	}
+	waitGroup.Wait()
+	rawConfigs := make(map[string]json.RawMessage, len(configNames))
+	for i, configName := range configNames {
+		result := &results[i]
+		if result.err != nil {
+			return nil, result.err
 		}
-		rawConfig, err = yaml.YAMLToJSONStrict(rawConfig)
+		if result.isSOPS {
+			// The reserved configs defaults and overrides cover the whole config set.
+			var path string
+			if configName != defaultsConfigName && configName != overridesConfigName {
+				path = joinPath("", configName)
+			}
+			*secretValues = appendSOPSSecretValues(*secretValues, path, gjson.ParseBytes(result.rawConfig))
+		} else {
+			filePath := filepath.Join(dirPath, configName+".yaml")
+			if err := cs.checkSecretFile(fs, filePath, configName, result.rawConfig); err != nil {
+				return nil, err
+			}
+		}
+		rawConfigs[configName] = result.rawConfig
+	}
+	return rawConfigs, nil
+}
+
+// readConfig reads the config from the given file, and reports whether the file
+// is encrypted with SOPS. It may be called concurrently, see
+// WithLoadConcurrency.
+func (cs *ConfigSet) readConfig(fs afero.Fs, filePath string, environment []string) (json.RawMessage, bool, error) {
+	buffer := getBuffer()
+	defer putBuffer(buffer)
+	data, err := cs.readFile(fs, filePath, buffer)
+	if err != nil {
+		return nil, false, fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+	}
+	if cs.options.template != nil {
+		data, err = cs.renderTemplate(filePath, data, environment)
 		if err != nil {
-			return nil, fmt.Errorf("convert yaml to json; filePath=%q: %w", filePath, err)
+			return nil, false, fmt.Errorf("render template; filePath=%q: %w", filePath, err)
 		}
-		rawConfigs[configName] = rawConfig
 	}
-	rawConfigSet, err := json.Marshal(rawConfigs)
+	rawConfig, err := yaml.YAMLToJSONStrict(data)
 	if err != nil {
-		return nil, fmt.Errorf("marshal to json: %w", err)
+		return nil, false, fmt.Errorf("convert yaml to json; filePath=%q: %w", filePath, err)
+	}
+	if isSOPSFile(rawConfig) {
+		rawConfig, err = cs.decryptSOPSFile(data)
+		if err != nil {
+			return nil, false, fmt.Errorf("decrypt sops file; filePath=%q: %w", filePath, err)
+		}
+		return rawConfig, true, nil
 	}
-	return rawConfigSet, nil
+	return rawConfig, false, nil
 }
 
-func overwriteConfigSet(rawConfigSet json.RawMessage, environment []string) (json.RawMessage, error) {
-	kvs := extractKVs(environment)
-	for _, kv := range kvs {
-		key, value := kv[0], kv[1]
-		data, err := yaml.YAMLToJSONStrict([]byte(value))
+// assembleObject assembles the given members, which must be valid JSON values,
+// into an object with the keys sorted, like json.Marshal does, but without
+// re-encoding the values.
+func assembleObject(members map[string]json.RawMessage) json.RawMessage {
+	keys := make([]string, 0, len(members))
+	size := 2
+	for key, value := range members {
+		keys = append(keys, key)
+		size += len(key) + len(value) + 4
+	}
+	sort.Strings(keys)
+	buffer := make([]byte, 0, size)
+	buffer = append(buffer, '{')
+	for i, key := range keys {
+		if i >= 1 {
+			buffer = append(buffer, ',')
+		}
+		quotedKey, _ := json.Marshal(key)
+		buffer = append(buffer, quotedKey...)
+		buffer = append(buffer, ':')
+		buffer = append(buffer, members[key]...)
+	}
+	return append(buffer, '}')
+}
+
+const (
+	defaultsConfigName = "defaults"
+	defaultsDirName    = "_defaults"
+)
+
+// readDefaults reads the default configs from the reserved directory _defaults,
+// in which each *.yaml file provides the defaults for the config of the same
+// name, and then from the reserved file defaults.yaml, which provides the
+// defaults for all configs. Rather than becoming a config, the defaults are
+// deep-merged beneath the other configs.
+func (cs *ConfigSet) readDefaults(fs afero.Fs, dirPath string, environment []string, rawConfigs map[string]json.RawMessage,
+	fragmentExpander *fragmentExpander, provenance *provenanceNode, secretValues *[]secretValue) (json.RawMessage, error) {
+	var rawDefaults json.RawMessage
+	defaultsDirPath := filepath.Join(dirPath, defaultsDirName)
+	if fileInfo, err := fs.Stat(defaultsDirPath); err == nil && fileInfo.IsDir() {
+		rawDefaultConfigs, err := cs.readConfigs(fs, defaultsDirPath, environment, secretValues)
 		if err != nil {
-			return nil, fmt.Errorf("convert yaml to json; key=%q value=%q: %w", key, value, err)
+			return nil, err
 		}
-		path := key[len(keyPrefix):]
-		rawConfigSet, err = sjson.SetRawBytesOptions(rawConfigSet, path, data, &sjson.Options{
-			Optimistic:     true,
-			ReplaceInPlace: true,
-		})
+		for configName, rawDefaultConfig := range rawDefaultConfigs {
+			rawDefaultConfig, err = fragmentExpander.expandConfigs(joinPath("", configName), rawDefaultConfig)
+			if err != nil {
+				return nil, err
+			}
+			rawDefaultConfigs[configName] = rawDefaultConfig
+			provenance.child(configName).mergeValue(gjson.ParseBytes(rawDefaultConfig), Origin{
+				Layer: OriginDefaultsFile,
+				Name:  filepath.Join(defaultsDirPath, configName+".yaml"),
+			}, false)
+		}
+		rawDefaults = assembleObject(rawDefaultConfigs)
+	}
+	if rawDefaultConfigs, ok := rawConfigs[defaultsConfigName]; ok {
+		delete(rawConfigs, defaultsConfigName)
+		rawDefaultConfigs, err := fragmentExpander.expandConfigs("", rawDefaultConfigs)
+		if err != nil {
+			return nil, err
+		}
+		provenance.mergeValue(gjson.ParseBytes(rawDefaultConfigs), Origin{
+			Layer: OriginDefaultsFile,
+			Name:  filepath.Join(dirPath, defaultsConfigName+".yaml"),
+		}, false)
+		if rawDefaults == nil {
+			rawDefaults = rawDefaultConfigs
+		} else {
+			rawDefaults = mergeJSON(rawDefaults, rawDefaultConfigs)
+		}
+	}
+	return rawDefaults, nil
+}
+
+func overwriteConfigSet(rawConfigSet json.RawMessage, environment []string, envPrefix string, provenance *provenanceNode) (json.RawMessage, error) {
+	kvs := extractKVs(environment, envPrefix)
+	valuePatches := make([]valuePatch, len(kvs))
+	for i, kv := range kvs {
+		key, value := kv[0], kv[1]
+		valuePatches[i] = valuePatch{
+			key:   key,
+			path:  key[len(envPrefix):],
+			value: value,
+		}
+	}
+	return patchConfigSet(rawConfigSet, valuePatches, OriginEnv, provenance)
+}
+
+// valuePatch represents a value in form of YAML to set for a path, given by a
+// key, e.g. an environment variable.
+type valuePatch struct {
+	key   string
+	path  string
+	value string
+}
+
+// patchConfigSet sets the values of the given patches in order, recording the
+// keys of the patches as the names of origins of the given layer.
+func patchConfigSet(rawConfigSet json.RawMessage, valuePatches []valuePatch, originLayer OriginLayer, provenance *provenanceNode) (json.RawMessage, error) {
+	if len(valuePatches) == 0 {
+		return rawConfigSet, nil
+	}
+	var envPatch envPatch
+	for _, valuePatch := range valuePatches {
+		data, err := yaml.YAMLToJSONStrict([]byte(valuePatch.value))
 		if err != nil {
-			return nil, fmt.Errorf("set json value; path=%q: %w", path, err)
+			return nil, fmt.Errorf("convert yaml to json; key=%q value=%q: %w", valuePatch.key, valuePatch.value, err)
+		}
+		if valuePatch.path == "" {
+			return nil, fmt.Errorf("set json value; path=%q: path cannot be empty", valuePatch.path)
 		}
+		keys := splitPath(valuePatch.path)
+		envPatch.set(keys, data)
+		provenance.descendant(keys).mergeValue(gjson.ParseBytes(data), Origin{Layer: originLayer, Name: valuePatch.key}, true)
 	}
-	return rawConfigSet, nil
+	return envPatch.apply(rawConfigSet)
 }
 
 const keyPrefix = "CONFIGSET."
 
-func extractKVs(environment []string) [][2]string {
+func extractKVs(environment []string, envPrefix string) [][2]string {
 	var kvs [][2]string
 	for _, rawKV := range environment {
-		if !strings.HasPrefix(rawKV, keyPrefix) {
+		if !strings.HasPrefix(rawKV, envPrefix) {
 			continue
 		}
 		i := strings.IndexByte(rawKV, '=')
@@ -130,35 +647,26 @@ func extractKVs(environment []string) [][2]string {
 		kv := [2]string{rawKV[:i], rawKV[i+1:]}
 		kvs = append(kvs, kv)
 	}
-	sort.Slice(kvs, func(i, j int) bool {
+	sort.SliceStable(kvs, func(i, j int) bool {
 		return kvs[i][0] < kvs[j][0]
 	})
 	return kvs
 }
 
-func (cs *configSet) ReadValue(path string, config interface{}) error {
-	value := gjson.GetBytes(cs.raw, path).Raw
-	if value == "" {
-		return fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
-	}
-	if err := json.Unmarshal([]byte(value), config); err != nil {
-		return fmt.Errorf("unmarshal from json; path=%q configType=\"%T\": %w", path, config, err)
+func (cs *ConfigSet) ReadValue(path string, config interface{}) error {
+	if err := cs.autoLoad(); err != nil {
+		return err
 	}
-	return nil
+	return cs.Snapshot().ReadValue(path, config)
 }
 
-func (cs *configSet) Dump(prefix string, indention string) json.RawMessage {
-	if len(prefix)+len(indention) == 0 {
-		raw := make(json.RawMessage, len(cs.raw))
-		copy(raw, cs.raw)
-		return raw
-	}
-	var buffer bytes.Buffer
-	json.Indent(&buffer, cs.raw, prefix, indention)
-	buffer.WriteByte('\n')
-	raw := buffer.Bytes()
-	return raw
+func (cs *ConfigSet) IsLoaded() bool { return cs.Snapshot().loaded }
+
+func (cs *ConfigSet) Dump(prefix string, indention string) json.RawMessage {
+	return cs.Snapshot().Dump(prefix, indention)
 }
 
+func (cs *ConfigSet) Bytes() []byte { return cs.Snapshot().Bytes() }
+
 // ErrValueNotFound is returned when the JSON value does not exist.
 var ErrValueNotFound = errors.New("configset: value not found")
@@ -1,8 +1,12 @@
 package configset_test
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	. "github.com/go-tk/configset"
@@ -84,6 +88,29 @@ author: roy
 		}).
 		Run(t)
 
+	// directory with default configuration files
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			snippet1(t, c)
+			if err := afero.WriteFile(c.fs, "/my_etc/defaults.yaml", []byte(`
+aaa:
+  hello: nobody
+  numbers: []
+gogo:
+  license: mit
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/_defaults/gogo.yaml", []byte(`
+license: bsd
+author: nobody
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.expectedJSON = `{"aaa":{"hello":"world","numbers":[1,2,3]},"gogo":{"author":"roy","license":"mit","version":1}}`
+		}).
+		Run(t)
+
 	// environment with overriding values
 	tc.Copy().
 		SetCallback(0, func(t *testing.T, c *C) {
@@ -145,6 +172,223 @@ numbers: [1,2,3
 			c.expectedErr = os.ErrNotExist
 		}).
 		Run(t)
+
+	// configuration files with references
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/common.yaml", []byte(`
+baseURL: https://example.com
+loginURL: ${ref:common.baseURL}/login?next=${ref:common.next}
+next: ${ref:common.home}
+home: /home
+port: 8080
+server: ${ref:common.port}
+limits: {cpu: 1}
+copy: ${ref:common.limits}
+escaped: $${ref:common.port} ${HOME} ${unknown:x}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.environment = []string{"CONFIGSET.common.home=/index"}
+			c.expectedJSON = `{"common":{"baseURL":"https://example.com","copy":{"cpu":1},"escaped":"${ref:common.port} ${HOME} ${unknown:x}","home":"/index","limits":{"cpu":1},"loginURL":"https://example.com/login?next=/index","next":"/index","port":8080,"server":8080}}`
+		}).
+		Run(t)
+
+	// configuration files with reference cycles
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/common.yaml", []byte(`
+a: ${ref:common.b}
+b: x${ref:common.a}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `resolve reference; path="common.a": resolve reference; path="common.b": resolve reference; path="common.a": configset: reference cycle; path="common.b"`
+			c.expectedErr = ErrReferenceCycle
+		}).
+		Run(t)
+
+	// configuration files with references to non-existent values
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/common.yaml", []byte(`
+a: ${ref:common.b}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `resolve reference; path="common.a": configset: value not found; path="common.b"`
+			c.expectedErr = ErrValueNotFound
+		}).
+		Run(t)
+
+	// configuration files with references interpolating non-scalar values
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/common.yaml", []byte(`
+a: {}
+b: x${ref:common.a}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `configset: non-scalar value interpolated; path="common.b" placeholder="${ref:common.a}"`
+		}).
+		Run(t)
+
+	// configuration files with expressions
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/app.yaml", []byte(`
+replicas: 3
+workers: "${expr: .replicas * 2}"
+queueSize: "${expr: (common.base + .replicas) % 4 - -1.5}"
+ratio: ${expr:.replicas / 2}
+name: '${expr: common.prefix + "-" + "app"}'
+label: "n=${expr: .workers + 1}"
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/common.yaml", []byte(`
+base: 10
+prefix: my
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedJSON = `{"app":{"label":"n=7","name":"my-app","queueSize":2.5,"ratio":1.5,"replicas":3,"workers":6},"common":{"base":10,"prefix":"my"}}`
+		}).
+		Run(t)
+
+	// configuration files with invalid expressions
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/app.yaml", []byte(`
+name: x
+workers: "${expr: .name * 2}"
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `configset: invalid expression; path="app.workers" expression=".name * 2": operator '*' applied to non-numbers`
+			c.expectedErr = ErrInvalidExpression
+		}).
+		Run(t)
+
+	// configuration files with inlined files
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/tls.yaml", []byte(`
+ca: ${file:/my_etc/ssl/ca.pem}
+query: "-- ${file:sql/query.sql}"
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/ssl/ca.pem", []byte("-----BEGIN CERTIFICATE-----\n${ref:x}\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/sql/query.sql", []byte("SELECT 1"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedJSON = `{"tls":{"ca":"-----BEGIN CERTIFICATE-----\n${ref:x}\n","query":"-- SELECT 1"}}`
+		}).
+		Run(t)
+
+	// configuration files with inlined non-existent files
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/tls.yaml", []byte(`
+ca: ${file:ca.pem}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `inline file; path="tls.ca": read file; filePath="/my_etc/ca.pem": open /my_etc/ca.pem: file does not exist`
+			c.expectedErr = os.ErrNotExist
+		}).
+		Run(t)
+
+	// configuration files with inlined files outside the directory
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/tls.yaml", []byte(`
+ca: ${file:../etc/shadow}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `inline file; path="tls.ca": configset: file not allowed; filePath="/etc/shadow"`
+			c.expectedErr = ErrFileNotAllowed
+		}).
+		Run(t)
+
+	// configuration files with shared fragments
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/_shared.yaml", []byte(`
+pool:
+  maxOpen: 10
+  maxIdle: 2
+timeouts:
+  $use: pool
+  read: 1s
+  maxIdle: 3
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/db.yaml", []byte(`
+primary:
+  $use: pool
+  maxOpen: 20
+replicas:
+  - $use: [pool, timeouts]
+    host: replica1
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedJSON = `{"db":{"primary":{"maxIdle":2,"maxOpen":20},"replicas":[{"host":"replica1","maxIdle":3,"maxOpen":10,"read":"1s"}]}}`
+		}).
+		Run(t)
+
+	// configuration files with unknown shared fragments
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/db.yaml", []byte(`
+primary:
+  $use: pool
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `use fragment; path="db.primary": configset: fragment not found; name="pool"`
+			c.expectedErr = ErrFragmentNotFound
+		}).
+		Run(t)
+
+	// configuration files with shared fragment cycles
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := afero.WriteFile(c.fs, "/my_etc/_shared.yaml", []byte(`
+a: {$use: b}
+b: {$use: a}
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := afero.WriteFile(c.fs, "/my_etc/db.yaml", []byte(`
+primary:
+  $use: a
+`), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c.dirPath = "/my_etc"
+			c.expectedErrStr = `use fragment; path="db.primary": use fragment; path="_shared.a": use fragment; path="_shared.b": configset: reference cycle; name="a"`
+			c.expectedErr = ErrReferenceCycle
+		}).
+		Run(t)
 }
 
 func TestConfigSet_ReadValue(t *testing.T) {
@@ -241,6 +485,47 @@ author:
 		}).
 		Run(t)
 
+	// read value with default tags
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			type Address struct {
+				City    string `json:"city" default:"london"`
+				ZipCode string `json:"zip_code" default:"10001"`
+			}
+			type Author struct {
+				Name    string   `json:"name" default:"nobody"`
+				Gender  string   `json:"gender"`
+				Age     int      `json:"age" default:"18"`
+				Tags    []string `json:"tags" default:"[a, b]"`
+				Address Address  `json:"address"`
+			}
+			c.path = "gogo.author"
+			c.config = &Author{}
+			c.expectedConfig = &Author{
+				Name:   "roy",
+				Gender: "male",
+				Age:    18,
+				Tags:   []string{"a", "b"},
+				Address: Address{
+					City:    "london",
+					ZipCode: "10001",
+				},
+			}
+		}).
+		Run(t)
+
+	// bad default tag
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			type Author struct {
+				Age int `json:"age" default:"eighteen"`
+			}
+			c.path = "gogo.author"
+			c.config = &Author{}
+			c.expectedErrStr = `apply default tags; path="gogo.author" configType="*configset_test.Author": set default value; fieldName="Age" defaultValue="eighteen": unmarshal from json: json: cannot unmarshal string into Go value of type int`
+		}).
+		Run(t)
+
 	// read non-existent value
 	tc.Copy().
 		SetCallback(0, func(t *testing.T, c *C) {
@@ -268,3 +553,108 @@ author:
 		}).
 		Run(t)
 }
+
+func TestConfigSet_Concurrency(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Load(fs, "/my_etc", nil); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(t, cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var port int
+				assert.NoError(t, cs.ReadValue("server.port", &port))
+				assert.Contains(t, []int{8080, 8081}, port)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConfigSet_Load_placeholders(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/tls.yaml", []byte(`
+ca: ${file:/etc/ssl/ca.pem}
+key: ${ref:tls.ca}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/etc/ssl/ca.pem", []byte("CA"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/etc/shadow", []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrFileNotAllowed)
+
+	cs.Configure(WithFileRoots("/etc/ssl"))
+	cs.AddSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+		return json.RawMessage(`{"tls":{"source":"${file:/etc/ssl/ca.pem}"}}`), nil
+	}))
+	err = cs.Load(fs, "/my_etc", []string{
+		"CONFIGSET.tls.env=${file:/etc/shadow}",
+		"CONFIGSET.tls.ref=${ref:tls.ca}",
+		"CONFIGSET.tls.key=${ref:tls.env}",
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"tls":{"ca":"CA","env":"${file:/etc/shadow}","key":"${ref:tls.env}","ref":"${ref:tls.ca}","source":"${file:/etc/ssl/ca.pem}"}}`, string(cs.Dump("", "")))
+}
+
+// newBenchmarkFs returns a file system with the given number of configuration
+// files under /my_etc, each of which has the given number of values.
+func newBenchmarkFs(b *testing.B, configCount int, valueCount int) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for i := 0; i < configCount; i++ {
+		var builder strings.Builder
+		for j := 0; j < valueCount; j++ {
+			fmt.Fprintf(&builder, "key%d:\n  number: %d\n  string: value%d\n", j, j, j)
+		}
+		if err := afero.WriteFile(fs, fmt.Sprintf("/my_etc/config%d.yaml", i), []byte(builder.String()), 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return fs
+}
+
+func BenchmarkConfigSet_Load(b *testing.B) {
+	fs := newBenchmarkFs(b, 100, 10)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var cs ConfigSet
+		if err := cs.Load(fs, "/my_etc", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConfigSet_Load_reload(b *testing.B) {
+	fs := newBenchmarkFs(b, 100, 10)
+	var cs ConfigSet
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := cs.Load(fs, "/my_etc", nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
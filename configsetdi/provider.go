@@ -0,0 +1,77 @@
+// Package configsetdi provides providers of config sets and config sections for
+// dependency injection, e.g. with Uber fx or Google Wire, so that constructors
+// can take config sections directly. The providers are plain functions, and
+// the package doesn't depend on any framework.
+package configsetdi
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-tk/configset"
+)
+
+// ConfigSetProvider returns a provider of a new config set created with the
+// given options and loaded with Open, e.g.
+//
+//	fx.Provide(configsetdi.ConfigSetProvider(configset.WithDir("/etc/app")))
+//
+// The config set should be closed when the application stops, e.g. in an fx
+// OnStop hook, if it runs Watch or Poll.
+func ConfigSetProvider(options ...configset.Option) func() (*configset.ConfigSet, error) {
+	return func() (*configset.ConfigSet, error) {
+		cs := configset.New(options...)
+		if err := cs.Open(context.Background()); err != nil {
+			return nil, fmt.Errorf("open config set: %w", err)
+		}
+		return cs, nil
+	}
+}
+
+// ValueProvider returns a provider of the value for the given path in the
+// config set, which is a function of type
+//
+//	func(*configset.ConfigSet) (T, error)
+//
+// where T is the type of the given config, e.g. ServerConfig or *ServerConfig,
+// and the value is read with ReadValue, so that constructors can take config
+// sections directly, e.g.
+//
+//	fx.Provide(configsetdi.ValueProvider("server", ServerConfig{}))
+//
+// The provider is made with reflection, which suits frameworks resolving
+// dependencies at run time, such as fx. Wire analyzes code statically, so with
+// Wire the equivalent provider should be written out, e.g.
+//
+//	func provideServerConfig(cs *configset.ConfigSet) (config ServerConfig, err error) {
+//		err = cs.ReadValue("server", &config)
+//		return
+//	}
+func ValueProvider(path string, config interface{}) interface{} {
+	configType := reflect.TypeOf(config)
+	if configType == nil {
+		panic(fmt.Sprintf("configsetdi: nil config; path=%q", path))
+	}
+	providerType := reflect.FuncOf(
+		[]reflect.Type{reflect.TypeOf((*configset.ConfigSet)(nil))},
+		[]reflect.Type{configType, reflect.TypeOf((*error)(nil)).Elem()},
+		false,
+	)
+	return reflect.MakeFunc(providerType, func(args []reflect.Value) []reflect.Value {
+		cs := args[0].Interface().(*configset.ConfigSet)
+		var value reflect.Value
+		if configType.Kind() == reflect.Ptr {
+			value = reflect.New(configType.Elem())
+		} else {
+			value = reflect.New(configType)
+		}
+		if err := cs.ReadValue(path, value.Interface()); err != nil {
+			return []reflect.Value{reflect.Zero(configType), reflect.ValueOf(&err).Elem()}
+		}
+		if configType.Kind() != reflect.Ptr {
+			value = value.Elem()
+		}
+		return []reflect.Value{value, reflect.Zero(providerType.Out(1))}
+	}).Interface()
+}
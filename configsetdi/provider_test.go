@@ -0,0 +1,60 @@
+package configsetdi_test
+
+import (
+	"testing"
+
+	"github.com/go-tk/configset"
+	. "github.com/go-tk/configset/configsetdi"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverConfig struct {
+	Port int `json:"port"`
+}
+
+func TestConfigSetProvider(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs, err := ConfigSetProvider(configset.WithFS(fs), configset.WithDir("/my_etc"))()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+
+	_, err = ConfigSetProvider(configset.WithFS(fs), configset.WithDir("/etc"))()
+	assert.Contains(t, err.Error(), "open config set: ")
+}
+
+func TestValueProvider(t *testing.T) {
+	var cs configset.ConfigSet
+	err := cs.LoadFiles(map[string][]byte{"server.yaml": []byte("port: 8080\n")}, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	provider, ok := ValueProvider("server", serverConfig{}).(func(*configset.ConfigSet) (serverConfig, error))
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	config, err := provider(&cs)
+	assert.NoError(t, err)
+	assert.Equal(t, serverConfig{Port: 8080}, config)
+
+	pointerProvider, ok := ValueProvider("server", (*serverConfig)(nil)).(func(*configset.ConfigSet) (*serverConfig, error))
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	pointerConfig, err := pointerProvider(&cs)
+	assert.NoError(t, err)
+	assert.Equal(t, &serverConfig{Port: 8080}, pointerConfig)
+
+	pointerProvider = ValueProvider("client", (*serverConfig)(nil)).(func(*configset.ConfigSet) (*serverConfig, error))
+	pointerConfig, err = pointerProvider(&cs)
+	assert.ErrorIs(t, err, configset.ErrValueNotFound)
+	assert.Nil(t, pointerConfig)
+	assert.Panics(t, func() { ValueProvider("server", nil) })
+}
@@ -0,0 +1,577 @@
+// The config service serves a config set over the network, so that one process
+// owning the sources, e.g. a sidecar, can distribute configuration to others.
+// Values are in form of JSON, and paths are in the syntax of ReadValue.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: configset/v1/configset.proto
+
+package configsetv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Change_Kind int32
+
+const (
+	Change_KIND_UNSPECIFIED Change_Kind = 0
+	Change_KIND_ADDED       Change_Kind = 1
+	Change_KIND_REMOVED     Change_Kind = 2
+	Change_KIND_MODIFIED    Change_Kind = 3
+)
+
+// Enum value maps for Change_Kind.
+var (
+	Change_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_ADDED",
+		2: "KIND_REMOVED",
+		3: "KIND_MODIFIED",
+	}
+	Change_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_ADDED":       1,
+		"KIND_REMOVED":     2,
+		"KIND_MODIFIED":    3,
+	}
+)
+
+func (x Change_Kind) Enum() *Change_Kind {
+	p := new(Change_Kind)
+	*p = x
+	return p
+}
+
+func (x Change_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Change_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_configset_v1_configset_proto_enumTypes[0].Descriptor()
+}
+
+func (Change_Kind) Type() protoreflect.EnumType {
+	return &file_configset_v1_configset_proto_enumTypes[0]
+}
+
+func (x Change_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Change_Kind.Descriptor instead.
+func (Change_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{6, 0}
+}
+
+type ReadValueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadValueRequest) Reset() {
+	*x = ReadValueRequest{}
+	mi := &file_configset_v1_configset_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadValueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadValueRequest) ProtoMessage() {}
+
+func (x *ReadValueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadValueRequest.ProtoReflect.Descriptor instead.
+func (*ReadValueRequest) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReadValueRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ReadValueResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The value in form of JSON.
+	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// The fingerprint of the config set read, see Fingerprint.
+	Fingerprint   string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadValueResponse) Reset() {
+	*x = ReadValueResponse{}
+	mi := &file_configset_v1_configset_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadValueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadValueResponse) ProtoMessage() {}
+
+func (x *ReadValueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadValueResponse.ProtoReflect.Descriptor instead.
+func (*ReadValueResponse) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReadValueResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *ReadValueResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+type DumpRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the value to dump, or empty for the whole config set.
+	Path          string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpRequest) Reset() {
+	*x = DumpRequest{}
+	mi := &file_configset_v1_configset_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpRequest) ProtoMessage() {}
+
+func (x *DumpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpRequest.ProtoReflect.Descriptor instead.
+func (*DumpRequest) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DumpRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type DumpResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The value in form of JSON, with the values of secrets masked.
+	Value       []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Fingerprint string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	// The sequence number of the generation, see History.
+	Generation    int64 `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpResponse) Reset() {
+	*x = DumpResponse{}
+	mi := &file_configset_v1_configset_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpResponse) ProtoMessage() {}
+
+func (x *DumpResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpResponse.ProtoReflect.Descriptor instead.
+func (*DumpResponse) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DumpResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *DumpResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *DumpResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+type WatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path prefix to watch, or empty for the whole config set.
+	PathPrefix    string `protobuf:"bytes,1,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_configset_v1_configset_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchRequest) GetPathPrefix() string {
+	if x != nil {
+		return x.PathPrefix
+	}
+	return ""
+}
+
+type WatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The value for the path prefix in form of JSON, with the values of secrets
+	// masked.
+	Value       []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Fingerprint string `protobuf:"bytes,2,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	Generation  int64  `protobuf:"varint,3,opt,name=generation,proto3" json:"generation,omitempty"`
+	// The changes from the previous value, see Diff, which are empty in the
+	// first response.
+	Changes       []*Change `protobuf:"bytes,4,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	mi := &file_configset_v1_configset_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchResponse) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *WatchResponse) GetGeneration() int64 {
+	if x != nil {
+		return x.Generation
+	}
+	return 0
+}
+
+func (x *WatchResponse) GetChanges() []*Change {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type Change struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          Change_Kind            `protobuf:"varint,1,opt,name=kind,proto3,enum=configset.v1.Change_Kind" json:"kind,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	OldValue      []byte                 `protobuf:"bytes,3,opt,name=old_value,json=oldValue,proto3" json:"old_value,omitempty"`
+	NewValue      []byte                 `protobuf:"bytes,4,opt,name=new_value,json=newValue,proto3" json:"new_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Change) Reset() {
+	*x = Change{}
+	mi := &file_configset_v1_configset_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Change) ProtoMessage() {}
+
+func (x *Change) ProtoReflect() protoreflect.Message {
+	mi := &file_configset_v1_configset_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Change.ProtoReflect.Descriptor instead.
+func (*Change) Descriptor() ([]byte, []int) {
+	return file_configset_v1_configset_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Change) GetKind() Change_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return Change_KIND_UNSPECIFIED
+}
+
+func (x *Change) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Change) GetOldValue() []byte {
+	if x != nil {
+		return x.OldValue
+	}
+	return nil
+}
+
+func (x *Change) GetNewValue() []byte {
+	if x != nil {
+		return x.NewValue
+	}
+	return nil
+}
+
+var File_configset_v1_configset_proto protoreflect.FileDescriptor
+
+const file_configset_v1_configset_proto_rawDesc = "" +
+	"\n" +
+	"\x1cconfigset/v1/configset.proto\x12\fconfigset.v1\"&\n" +
+	"\x10ReadValueRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"K\n" +
+	"\x11ReadValueResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint\"!\n" +
+	"\vDumpRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"f\n" +
+	"\fDumpResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x03R\n" +
+	"generation\"/\n" +
+	"\fWatchRequest\x12\x1f\n" +
+	"\vpath_prefix\x18\x01 \x01(\tR\n" +
+	"pathPrefix\"\x97\x01\n" +
+	"\rWatchResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05value\x12 \n" +
+	"\vfingerprint\x18\x02 \x01(\tR\vfingerprint\x12\x1e\n" +
+	"\n" +
+	"generation\x18\x03 \x01(\x03R\n" +
+	"generation\x12.\n" +
+	"\achanges\x18\x04 \x03(\v2\x14.configset.v1.ChangeR\achanges\"\xd8\x01\n" +
+	"\x06Change\x12-\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x19.configset.v1.Change.KindR\x04kind\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\x12\x1b\n" +
+	"\told_value\x18\x03 \x01(\fR\boldValue\x12\x1b\n" +
+	"\tnew_value\x18\x04 \x01(\fR\bnewValue\"Q\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"KIND_ADDED\x10\x01\x12\x10\n" +
+	"\fKIND_REMOVED\x10\x02\x12\x11\n" +
+	"\rKIND_MODIFIED\x10\x032\xe0\x01\n" +
+	"\rConfigService\x12L\n" +
+	"\tReadValue\x12\x1e.configset.v1.ReadValueRequest\x1a\x1f.configset.v1.ReadValueResponse\x12=\n" +
+	"\x04Dump\x12\x19.configset.v1.DumpRequest\x1a\x1a.configset.v1.DumpResponse\x12B\n" +
+	"\x05Watch\x12\x1a.configset.v1.WatchRequest\x1a\x1b.configset.v1.WatchResponse0\x01BBZ@github.com/go-tk/configset/configsetgrpc/configsetv1;configsetv1b\x06proto3"
+
+var (
+	file_configset_v1_configset_proto_rawDescOnce sync.Once
+	file_configset_v1_configset_proto_rawDescData []byte
+)
+
+func file_configset_v1_configset_proto_rawDescGZIP() []byte {
+	file_configset_v1_configset_proto_rawDescOnce.Do(func() {
+		file_configset_v1_configset_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_configset_v1_configset_proto_rawDesc), len(file_configset_v1_configset_proto_rawDesc)))
+	})
+	return file_configset_v1_configset_proto_rawDescData
+}
+
+var file_configset_v1_configset_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_configset_v1_configset_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_configset_v1_configset_proto_goTypes = []any{
+	(Change_Kind)(0),          // 0: configset.v1.Change.Kind
+	(*ReadValueRequest)(nil),  // 1: configset.v1.ReadValueRequest
+	(*ReadValueResponse)(nil), // 2: configset.v1.ReadValueResponse
+	(*DumpRequest)(nil),       // 3: configset.v1.DumpRequest
+	(*DumpResponse)(nil),      // 4: configset.v1.DumpResponse
+	(*WatchRequest)(nil),      // 5: configset.v1.WatchRequest
+	(*WatchResponse)(nil),     // 6: configset.v1.WatchResponse
+	(*Change)(nil),            // 7: configset.v1.Change
+}
+var file_configset_v1_configset_proto_depIdxs = []int32{
+	7, // 0: configset.v1.WatchResponse.changes:type_name -> configset.v1.Change
+	0, // 1: configset.v1.Change.kind:type_name -> configset.v1.Change.Kind
+	1, // 2: configset.v1.ConfigService.ReadValue:input_type -> configset.v1.ReadValueRequest
+	3, // 3: configset.v1.ConfigService.Dump:input_type -> configset.v1.DumpRequest
+	5, // 4: configset.v1.ConfigService.Watch:input_type -> configset.v1.WatchRequest
+	2, // 5: configset.v1.ConfigService.ReadValue:output_type -> configset.v1.ReadValueResponse
+	4, // 6: configset.v1.ConfigService.Dump:output_type -> configset.v1.DumpResponse
+	6, // 7: configset.v1.ConfigService.Watch:output_type -> configset.v1.WatchResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_configset_v1_configset_proto_init() }
+func file_configset_v1_configset_proto_init() {
+	if File_configset_v1_configset_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_configset_v1_configset_proto_rawDesc), len(file_configset_v1_configset_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_configset_v1_configset_proto_goTypes,
+		DependencyIndexes: file_configset_v1_configset_proto_depIdxs,
+		EnumInfos:         file_configset_v1_configset_proto_enumTypes,
+		MessageInfos:      file_configset_v1_configset_proto_msgTypes,
+	}.Build()
+	File_configset_v1_configset_proto = out.File
+	file_configset_v1_configset_proto_goTypes = nil
+	file_configset_v1_configset_proto_depIdxs = nil
+}
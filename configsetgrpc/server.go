@@ -0,0 +1,169 @@
+// Package configsetgrpc serves config sets over gRPC with the service
+// configset.v1.ConfigService, see proto/configset/v1/configset.proto, so that
+// one process owning the sources, e.g. a sidecar, can distribute configuration
+// to others. It is a separate module, so that the module configset doesn't
+// depend on gRPC.
+//
+// The package configsetv1 is generated from the proto file with:
+//
+//	protoc -I proto --go_out=configsetgrpc --go_opt=module=github.com/go-tk/configset/configsetgrpc \
+//		--go-grpc_out=configsetgrpc --go-grpc_opt=module=github.com/go-tk/configset/configsetgrpc \
+//		configset/v1/configset.proto
+package configsetgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-tk/configset"
+	"github.com/go-tk/configset/configsetgrpc/configsetv1"
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the service configset.v1.ConfigService for a config set.
+// ReadValue returns values as they are, whereas Dump and Watch return values
+// with secrets masked, see configset.DumpRedacted.
+type Server struct {
+	configsetv1.UnimplementedConfigServiceServer
+
+	cs *configset.ConfigSet
+}
+
+var _ configsetv1.ConfigServiceServer = (*Server)(nil)
+
+// NewServer returns a server for the given config set, which can be registered
+// with configsetv1.RegisterConfigServiceServer, or with Register.
+func NewServer(cs *configset.ConfigSet) *Server { return &Server{cs: cs} }
+
+// Register registers a server for the given config set with the given gRPC
+// server.
+func Register(grpcServer grpc.ServiceRegistrar, cs *configset.ConfigSet) {
+	configsetv1.RegisterConfigServiceServer(grpcServer, NewServer(cs))
+}
+
+// ReadValue implements configsetv1.ConfigServiceServer.
+func (s *Server) ReadValue(_ context.Context, request *configsetv1.ReadValueRequest) (*configsetv1.ReadValueResponse, error) {
+	snapshot := s.cs.Snapshot()
+	var value json.RawMessage
+	if request.GetPath() == "" {
+		value = snapshot.Dump("", "")
+		if value == nil {
+			return nil, status.Error(codes.FailedPrecondition, configset.ErrNotLoaded.Error())
+		}
+	} else {
+		var err error
+		value, err = snapshot.DumpAt(request.GetPath(), "", "")
+		if err != nil {
+			return nil, errorStatus(err)
+		}
+	}
+	return &configsetv1.ReadValueResponse{
+		Value:       value,
+		Fingerprint: snapshot.Fingerprint(),
+	}, nil
+}
+
+// Dump implements configsetv1.ConfigServiceServer.
+func (s *Server) Dump(_ context.Context, request *configsetv1.DumpRequest) (*configsetv1.DumpResponse, error) {
+	state, err := s.readState(request.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	if state.Value == nil {
+		return nil, errorStatus(configset.ErrValueNotFound)
+	}
+	return &configsetv1.DumpResponse{
+		Value:       state.Value,
+		Fingerprint: state.Fingerprint,
+		Generation:  state.Generation,
+	}, nil
+}
+
+// Watch implements configsetv1.ConfigServiceServer.
+func (s *Server) Watch(request *configsetv1.WatchRequest, stream configsetv1.ConfigService_WatchServer) error {
+	pathPrefix := request.GetPathPrefix()
+	changed := make(chan struct{}, 1)
+	cancel := s.cs.Subscribe(pathPrefix, func(json.RawMessage, json.RawMessage) {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	defer cancel()
+	state, err := s.readState(pathPrefix)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(state); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-changed:
+		}
+		newState, err := s.readState(pathPrefix)
+		if err != nil {
+			return err
+		}
+		if newState.Fingerprint == state.Fingerprint {
+			continue
+		}
+		for _, change := range configset.DiffJSON(pathPrefix, state.Value, newState.Value) {
+			newState.Changes = append(newState.Changes, &configsetv1.Change{
+				Kind:     changeKinds[change.Kind],
+				Path:     change.Path,
+				OldValue: change.OldValue,
+				NewValue: change.NewValue,
+			})
+		}
+		if err := stream.Send(newState); err != nil {
+			return err
+		}
+		state = newState
+	}
+}
+
+var changeKinds = map[configset.ChangeKind]configsetv1.Change_Kind{
+	configset.ChangeAdded:    configsetv1.Change_KIND_ADDED,
+	configset.ChangeRemoved:  configsetv1.Change_KIND_REMOVED,
+	configset.ChangeModified: configsetv1.Change_KIND_MODIFIED,
+}
+
+// readState returns the redacted value for the given path, which is nil if the
+// value doesn't exist, along with the fingerprint and the generation of the
+// config set.
+func (s *Server) readState(path string) (*configsetv1.WatchResponse, error) {
+	var state configsetv1.WatchResponse
+	if history := s.cs.History(); len(history) >= 1 {
+		state.Generation = int64(history[0].Number)
+	}
+	state.Fingerprint = s.cs.Fingerprint()
+	raw := s.cs.DumpRedacted("", "")
+	if raw == nil {
+		return nil, status.Error(codes.FailedPrecondition, configset.ErrNotLoaded.Error())
+	}
+	if path == "" {
+		state.Value = raw
+	} else if value := gjson.GetBytes(raw, path); value.Exists() {
+		state.Value = json.RawMessage(value.Raw)
+	}
+	return &state, nil
+}
+
+// errorStatus returns the status for the given error of the config set.
+func errorStatus(err error) error {
+	switch {
+	case errors.Is(err, configset.ErrValueNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, configset.ErrNotLoaded):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
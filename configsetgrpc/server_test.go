@@ -0,0 +1,114 @@
+package configsetgrpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-tk/configset"
+	"github.com/go-tk/configset/configsetgrpc"
+	"github.com/go-tk/configset/configsetgrpc/configsetv1"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestServer(t *testing.T) {
+	var cs configset.ConfigSet
+	cs.AddSecretPaths("db.password")
+	client := newClient(t, &cs)
+	ctx := context.Background()
+
+	_, err := client.ReadValue(ctx, &configsetv1.ReadValueRequest{Path: "server.port"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: hunter2
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	readValueResponse, err := client.ReadValue(ctx, &configsetv1.ReadValueRequest{Path: "db.password"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `"hunter2"`, string(readValueResponse.GetValue()))
+		assert.Equal(t, cs.Fingerprint(), readValueResponse.GetFingerprint())
+	}
+	_, err = client.ReadValue(ctx, &configsetv1.ReadValueRequest{Path: "server.host"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	dumpResponse, err := client.Dump(ctx, &configsetv1.DumpRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"db":{"password":"******"},"server":{"port":8080}}`, string(dumpResponse.GetValue()))
+		assert.Equal(t, int64(1), dumpResponse.GetGeneration())
+	}
+	dumpResponse, err = client.Dump(ctx, &configsetv1.DumpRequest{Path: "db"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"password":"******"}`, string(dumpResponse.GetValue()))
+	}
+	_, err = client.Dump(ctx, &configsetv1.DumpRequest{Path: "cache"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Watch(watchCtx, &configsetv1.WatchRequest{PathPrefix: "server"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	watchResponse, err := stream.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"port":8080}`, string(watchResponse.GetValue()))
+		assert.Empty(t, watchResponse.GetChanges())
+	}
+	err = cs.Set("server.port", 8081)
+	assert.NoError(t, err)
+	watchResponse, err = stream.Recv()
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"port":8081}`, string(watchResponse.GetValue()))
+		assert.Equal(t, cs.Fingerprint(), watchResponse.GetFingerprint())
+		assert.Equal(t, int64(2), watchResponse.GetGeneration())
+		if assert.Len(t, watchResponse.GetChanges(), 1) {
+			change := watchResponse.GetChanges()[0]
+			assert.Equal(t, configsetv1.Change_KIND_MODIFIED, change.GetKind())
+			assert.Equal(t, "server.port", change.GetPath())
+			assert.Equal(t, `8080`, string(change.GetOldValue()))
+			assert.Equal(t, `8081`, string(change.GetNewValue()))
+		}
+	}
+	cancel()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+}
+
+// newClient serves the given config set in memory, and returns a client
+// connected to it.
+func newClient(t *testing.T, cs *configset.ConfigSet) configsetv1.ConfigServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	configsetgrpc.Register(grpcServer, cs)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return configsetv1.NewConfigServiceClient(conn)
+}
@@ -0,0 +1,209 @@
+// Package configsetkoanf bridges koanf and configset in both directions, so
+// that koanf providers, e.g. for Vault, Consul or S3, can be used as sources of
+// config sets, and koanf users can load config sets, with the overrides of
+// environment variables applied, into koanf instances. The package doesn't
+// depend on koanf, as the types here satisfy the interfaces of koanf, and koanf
+// providers and parsers satisfy the interfaces Provider and Parser.
+package configsetkoanf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-tk/configset"
+	"sigs.k8s.io/yaml"
+)
+
+// Provider is the interface of koanf providers. Providers support either or
+// both of the methods, and return errors from the others.
+type Provider interface {
+	ReadBytes() ([]byte, error)
+	Read() (map[string]interface{}, error)
+}
+
+// Parser is the interface of koanf parsers.
+type Parser interface {
+	Unmarshal(data []byte) (map[string]interface{}, error)
+	Marshal(values map[string]interface{}) ([]byte, error)
+}
+
+// NewSource returns a source fetching the values of the given koanf provider
+// at every load, see configset.AddSources. If the given parser is nil, the
+// values are read with Read, otherwise with ReadBytes and parsed by the parser.
+// Flat keys delimited by dots, e.g. "server.port" from the env provider, are
+// expanded into nested ones like koanf does.
+func NewSource(provider Provider, parser Parser) configset.Source {
+	return source{provider, parser}
+}
+
+type source struct {
+	provider Provider
+	parser   Parser
+}
+
+var _ configset.Source = source{}
+
+func (s source) Fetch(context.Context) (json.RawMessage, error) {
+	var values map[string]interface{}
+	if s.parser == nil {
+		var err error
+		values, err = s.provider.Read()
+		if err != nil {
+			return nil, fmt.Errorf("read values: %w", err)
+		}
+	} else {
+		data, err := s.provider.ReadBytes()
+		if err != nil {
+			return nil, fmt.Errorf("read bytes: %w", err)
+		}
+		values, err = s.parser.Unmarshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse bytes: %w", err)
+		}
+	}
+	rawValues, err := json.Marshal(unflatten(values))
+	if err != nil {
+		return nil, fmt.Errorf("marshal to json: %w", err)
+	}
+	return rawValues, nil
+}
+
+func (s source) String() string { return "koanf" }
+
+// unflatten returns the given values with the keys delimited by dots expanded
+// into nested maps, and the maps keyed by interface{} values, e.g. from YAML
+// parsers, converted into ones keyed by strings, which can be marshalled to
+// JSON. Maps for the same key, e.g. {"a.b.c": 1, "a": {"b": {"d": 2}}}, are
+// merged deeply. The keys are processed in sorted order, so that conflicting
+// values, e.g. {"a": 1, "a.b": 2}, are resolved regardless of map iteration
+// order, with the longer keys taking precedence.
+func unflatten(values map[string]interface{}) map[string]interface{} {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make(map[string]interface{}, len(values))
+	for _, key := range keys {
+		value := normalizeValue(values[key])
+		subkeys := strings.Split(key, ".")
+		m := result
+		for _, subkey := range subkeys[:len(subkeys)-1] {
+			child, ok := m[subkey].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				m[subkey] = child
+			}
+			m = child
+		}
+		mergeValue(m, subkeys[len(subkeys)-1], value)
+	}
+	return result
+}
+
+// mergeValue sets the given value for the given key of the given map, merging
+// maps deeply.
+func mergeValue(m map[string]interface{}, key string, value interface{}) {
+	valueMap, ok := value.(map[string]interface{})
+	if !ok {
+		m[key] = value
+		return
+	}
+	child, ok := m[key].(map[string]interface{})
+	if !ok {
+		m[key] = valueMap
+		return
+	}
+	for childKey, childValue := range valueMap {
+		mergeValue(child, childKey, childValue)
+	}
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return unflatten(value)
+	case map[interface{}]interface{}:
+		values := make(map[string]interface{}, len(value))
+		for key, childValue := range value {
+			values[fmt.Sprint(key)] = childValue
+		}
+		return unflatten(values)
+	case []interface{}:
+		elements := make([]interface{}, len(value))
+		for i, element := range value {
+			elements[i] = normalizeValue(element)
+		}
+		return elements
+	default:
+		return value
+	}
+}
+
+// ConfigSetProvider is a koanf provider of the values of a config set, which
+// loads the config set, with the overrides of environment variables applied,
+// into koanf instances, e.g.
+//
+//	k.Load(configsetkoanf.NewProvider(cs), nil)
+type ConfigSetProvider struct {
+	cs *configset.ConfigSet
+}
+
+var _ Provider = (*ConfigSetProvider)(nil)
+
+// NewProvider returns a koanf provider of the values of the given config set.
+func NewProvider(cs *configset.ConfigSet) *ConfigSetProvider { return &ConfigSetProvider{cs} }
+
+// ReadBytes returns the config set in form of JSON.
+func (csp *ConfigSetProvider) ReadBytes() ([]byte, error) {
+	rawConfigSet := csp.cs.Bytes()
+	if rawConfigSet == nil {
+		return nil, configset.ErrNotLoaded
+	}
+	return rawConfigSet, nil
+}
+
+// Read returns the config set in form of nested maps.
+func (csp *ConfigSetProvider) Read() (map[string]interface{}, error) {
+	rawConfigSet, err := csp.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(rawConfigSet, &values); err != nil {
+		return nil, fmt.Errorf("unmarshal from json: %w", err)
+	}
+	return values, nil
+}
+
+// YAMLParser is a koanf parser of YAML, and JSON as a subset of YAML, with the
+// semantics of configuration files of config sets, i.e. YAML is converted into
+// JSON first, so that koanf users get the same values as config sets do.
+type YAMLParser struct{}
+
+var _ Parser = YAMLParser{}
+
+// Unmarshal parses the given YAML into nested maps.
+func (YAMLParser) Unmarshal(data []byte) (map[string]interface{}, error) {
+	rawValues, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("convert yaml to json: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(rawValues, &values); err != nil {
+		return nil, fmt.Errorf("unmarshal from json: %w", err)
+	}
+	return values, nil
+}
+
+// Marshal formats the given nested maps in YAML.
+func (YAMLParser) Marshal(values map[string]interface{}) ([]byte, error) {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("marshal to yaml: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,122 @@
+package configsetkoanf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-tk/configset"
+	. "github.com/go-tk/configset/configsetkoanf"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSource(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 80
+host: localhost
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs configset.ConfigSet
+	cs.AddSources(
+		NewSource(fakeProvider{values: map[string]interface{}{
+			"server.port":   8080,
+			"server.tls":    map[interface{}]interface{}{"cert": "a.pem"},
+			"server.tls.ca": "ca.pem",
+		}}, nil),
+		NewSource(fakeProvider{data: []byte("client: {tags: [{1: true}]}\n")}, YAMLParser{}),
+	)
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.client.timeout=10"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"client":{"tags":[{"1":true}],"timeout":10},"server":{"host":"localhost","port":8080,"tls":{"ca":"ca.pem","cert":"a.pem"}}}`, string(cs.Dump("", "")))
+	assert.Equal(t, []string{"koanf", "koanf"}, cs.Status().Sources)
+
+	cs = configset.ConfigSet{}
+	cs.AddSources(NewSource(fakeProvider{}, YAMLParser{}))
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, errUnsupported)
+}
+
+func TestNewSource_flatAndNestedKeys(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := fs.Mkdir("/my_etc", 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Repeat loads, since map iteration order varies.
+	for i := 0; i < 20; i++ {
+		var cs configset.ConfigSet
+		cs.AddSources(NewSource(fakeProvider{values: map[string]interface{}{
+			"a.b.c": 1,
+			"a":     map[string]interface{}{"b": map[string]interface{}{"d": 2}},
+			"a.b":   map[interface{}]interface{}{"e": 3},
+			"x":     1,
+			"x.y":   2,
+		}}, nil))
+		err := cs.Load(fs, "/my_etc", nil)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, `{"a":{"b":{"c":1,"d":2,"e":3}},"x":{"y":2}}`, string(cs.Dump("", "")))
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	var cs configset.ConfigSet
+	provider := NewProvider(&cs)
+	_, err := provider.Read()
+	assert.ErrorIs(t, err, configset.ErrNotLoaded)
+
+	err = cs.LoadFiles(map[string][]byte{"server.yaml": []byte("port: 8080\ntags: [a]\n")}, []string{"CONFIGSET.server.port=8081"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	data, err := provider.ReadBytes()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8081,"tags":["a"]}}`, string(data))
+	values, err := provider.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"server": map[string]interface{}{
+			"port": float64(8081),
+			"tags": []interface{}{"a"},
+		},
+	}, values)
+}
+
+func TestYAMLParser(t *testing.T) {
+	values, err := YAMLParser{}.Unmarshal([]byte("port: 8080\ntags: [a]\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"port": float64(8080), "tags": []interface{}{"a"}}, values)
+	_, err = YAMLParser{}.Unmarshal([]byte("a: b: c"))
+	assert.Error(t, err)
+
+	data, err := YAMLParser{}.Marshal(values)
+	assert.NoError(t, err)
+	assert.Equal(t, "port: 8080\ntags:\n- a\n", string(data))
+}
+
+var errUnsupported = errors.New("unsupported")
+
+// fakeProvider implements the interface of koanf providers, supporting Read
+// with the values, or ReadBytes with the data.
+type fakeProvider struct {
+	values map[string]interface{}
+	data   []byte
+}
+
+func (fp fakeProvider) ReadBytes() ([]byte, error) {
+	if fp.data == nil {
+		return nil, errUnsupported
+	}
+	return fp.data, nil
+}
+
+func (fp fakeProvider) Read() (map[string]interface{}, error) {
+	if fp.values == nil {
+		return nil, errUnsupported
+	}
+	return fp.values, nil
+}
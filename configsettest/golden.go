@@ -0,0 +1,44 @@
+// Package configsettest provides helpers for testing with config sets.
+package configsettest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-tk/configset"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("configsettest.update", false, "update golden files of config sets")
+
+// AssertGolden asserts that the given config set, in the canonical form of JSON
+// indented with two spaces, with the values of secrets masked, see
+// configset.DumpRedacted, equals the content of the given golden file, so that
+// the effective configuration per environment can be locked down in CI. If the
+// test binary is run with the flag -configsettest.update, the golden file is
+// written instead, along with the directories missing.
+func AssertGolden(t testing.TB, cs *configset.ConfigSet, goldenFilePath string) bool {
+	t.Helper()
+	dump := cs.DumpRedacted("", "  ")
+	if dump == nil {
+		t.Errorf("configsettest: config set not loaded; goldenFilePath=%q", goldenFilePath)
+		return false
+	}
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFilePath), 0755); err != nil {
+			t.Fatalf("configsettest: create directory; goldenFilePath=%q: %v", goldenFilePath, err)
+		}
+		if err := ioutil.WriteFile(goldenFilePath, dump, 0644); err != nil {
+			t.Fatalf("configsettest: write golden file; goldenFilePath=%q: %v", goldenFilePath, err)
+		}
+		return true
+	}
+	golden, err := ioutil.ReadFile(goldenFilePath)
+	if err != nil {
+		t.Fatalf("configsettest: read golden file; goldenFilePath=%q: %v", goldenFilePath, err)
+	}
+	return assert.Equal(t, string(golden), string(dump), "configsettest: config set differs from golden file; goldenFilePath=%q", goldenFilePath)
+}
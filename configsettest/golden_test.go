@@ -0,0 +1,73 @@
+package configsettest_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-tk/configset"
+	. "github.com/go-tk/configset/configsettest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertGolden(t *testing.T) {
+	var cs configset.ConfigSet
+	cs.AddSecretPaths("db.password")
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: hunter2
+port: 5432
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, AssertGolden(t, &cs, "testdata/expected.json"))
+
+	err = cs.Set("db.port", 5433)
+	assert.NoError(t, err)
+	var rt recordingT
+	rt.TB = t
+	assert.False(t, AssertGolden(&rt, &cs, "testdata/expected.json"))
+	assert.Len(t, rt.errors, 1)
+}
+
+func TestAssertGolden_notLoaded(t *testing.T) {
+	var cs configset.ConfigSet
+	var rt recordingT
+	rt.TB = t
+	assert.False(t, AssertGolden(&rt, &cs, "testdata/expected.json"))
+	assert.Equal(t, []string{`configsettest: config set not loaded; goldenFilePath="testdata/expected.json"`}, rt.errors)
+}
+
+type recordingT struct {
+	testing.TB
+
+	errors []string
+}
+
+func (rt *recordingT) Errorf(format string, args ...interface{}) {
+	rt.errors = append(rt.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden_update(t *testing.T) {
+	if err := flag.Set("configsettest.update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer flag.Set("configsettest.update", "false")
+	var cs configset.ConfigSet
+	err := cs.MergeAt("", []byte(`{"db":{"port":5432}}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	goldenFilePath := filepath.Join(t.TempDir(), "testdata", "expected.json")
+	assert.True(t, AssertGolden(t, &cs, goldenFilePath))
+	golden, err := ioutil.ReadFile(goldenFilePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"db\": {\n    \"port\": 5432\n  }\n}\n", string(golden))
+}
@@ -0,0 +1,18 @@
+package configsettest
+
+import (
+	"testing"
+
+	"github.com/go-tk/configset"
+)
+
+// Set is configset.ConfigSet.SetForTest for the given config set, or
+// configset.SetForTest if the given config set is nil.
+func Set(t testing.TB, cs *configset.ConfigSet, path string, value interface{}) {
+	t.Helper()
+	if cs == nil {
+		configset.SetForTest(t, path, value)
+		return
+	}
+	cs.SetForTest(t, path, value)
+}
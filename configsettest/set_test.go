@@ -0,0 +1,45 @@
+package configsettest_test
+
+import (
+	"testing"
+
+	"github.com/go-tk/configset"
+	"github.com/go-tk/configset/configsettest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	var cs configset.ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tags: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Run("", func(t *testing.T) {
+		configsettest.Set(t, &cs, "server.port", 8081)
+		configsettest.Set(t, &cs, "server.tags", []string{"c"})
+		configsettest.Set(t, &cs, "server.host", "localhost")
+		assert.Equal(t, `{"server":{"host":"localhost","port":8081,"tags":["c"]}}`, string(cs.Dump("", "")))
+	})
+	assert.Equal(t, `{"server":{"port":8080,"tags":["a","b"]}}`, string(cs.Dump("", "")))
+}
+
+func TestSet_packageLevel(t *testing.T) {
+	t.Cleanup(func() { configset.Reset() })
+	err := configset.MergeAt("", []byte(`{"server":{"port":8080}}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Run("", func(t *testing.T) {
+		configsettest.Set(t, nil, "server.port", 8081)
+		assert.Equal(t, `{"server":{"port":8081}}`, string(configset.Dump("", "")))
+	})
+	assert.Equal(t, `{"server":{"port":8080}}`, string(configset.Dump("", "")))
+}
@@ -0,0 +1,75 @@
+package configsettest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/go-tk/configset"
+)
+
+// FakeSource is an in-memory configset.Source for tests, which returns scripted
+// responses, i.e. configs or errors, so that code depending on remote sources
+// can be tested without network. Fetches return the responses in the order they
+// are scripted, and the last response is repeated once all responses have been
+// returned. A zero FakeSource is ready to use.
+type FakeSource struct {
+	mutex      sync.Mutex
+	responses  []fakeResponse
+	fetchCount int
+}
+
+var _ configset.Source = (*FakeSource)(nil)
+
+type fakeResponse struct {
+	raw json.RawMessage
+	err error
+}
+
+// ErrNoResponse is returned by fetches of a FakeSource without responses
+// scripted.
+var ErrNoResponse = errors.New("configsettest: no response")
+
+// Respond scripts a response of the given configs in form of a JSON object,
+// whose keys are config names and values are configs.
+func (fs *FakeSource) Respond(raw json.RawMessage) *FakeSource {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.responses = append(fs.responses, fakeResponse{raw: raw})
+	return fs
+}
+
+// Fail scripts a response of the given error, e.g. to inject failures of remote
+// servers.
+func (fs *FakeSource) Fail(err error) *FakeSource {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.responses = append(fs.responses, fakeResponse{err: err})
+	return fs
+}
+
+// FetchCount returns the number of fetches so far.
+func (fs *FakeSource) FetchCount() int {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	return fs.fetchCount
+}
+
+// Fetch implements configset.Source.
+func (fs *FakeSource) Fetch(ctx context.Context) (json.RawMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+	fs.fetchCount++
+	if len(fs.responses) == 0 {
+		return nil, ErrNoResponse
+	}
+	response := fs.responses[0]
+	if len(fs.responses) >= 2 {
+		fs.responses = fs.responses[1:]
+	}
+	return response.raw, response.err
+}
@@ -0,0 +1,49 @@
+package configsettest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-tk/configset"
+	. "github.com/go-tk/configset/configsettest"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeSource(t *testing.T) {
+	var source FakeSource
+	_, err := source.Fetch(context.Background())
+	assert.ErrorIs(t, err, ErrNoResponse)
+
+	errUnavailable := errors.New("unavailable")
+	source.Respond([]byte(`{"server":{"port":8080}}`)).
+		Fail(errUnavailable).
+		Respond([]byte(`{"server":{"port":8081}}`))
+	var cs configset.ConfigSet
+	cs.AddSources(&source)
+	fs := afero.NewMemMapFs()
+	if err := fs.Mkdir("/my_etc", 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+	err = cs.ForceRefresh()
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+	for i := 0; i < 2; i++ {
+		err = cs.ForceRefresh()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"server":{"port":8081}}`, string(cs.Dump("", "")))
+	}
+	assert.Equal(t, 5, source.FetchCount())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = source.Fetch(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 5, source.FetchCount())
+}
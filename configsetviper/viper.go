@@ -0,0 +1,96 @@
+// Package configsetviper bridges Viper and configset, so that large
+// applications based on cobra and Viper can migrate to configset incrementally,
+// module by module, e.g. by importing the settings of Viper into a config set
+// as a source, and exporting the config set back to Viper for the modules not
+// migrated yet. The package doesn't depend on Viper, as *viper.Viper satisfies
+// the interfaces Settings and Setter.
+package configsetviper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-tk/configset"
+)
+
+// Settings is the interface of Viper providing all the settings, i.e. nested
+// maps keyed by lower-case keys.
+type Settings interface {
+	AllSettings() map[string]interface{}
+}
+
+// Setter is the interface of Viper overriding settings.
+type Setter interface {
+	Set(key string, value interface{})
+}
+
+// NewSource returns a source fetching all the settings of the given Viper
+// instance at every load, whose top-level keys become config names, see
+// configset.AddSources.
+func NewSource(settings Settings) configset.Source { return source{settings} }
+
+type source struct {
+	settings Settings
+}
+
+var _ configset.Source = source{}
+
+func (s source) Fetch(context.Context) (json.RawMessage, error) {
+	rawSettings, err := json.Marshal(normalizeSetting(s.settings.AllSettings()))
+	if err != nil {
+		return nil, fmt.Errorf("marshal to json: %w", err)
+	}
+	return rawSettings, nil
+}
+
+func (source) String() string { return "viper" }
+
+// normalizeSetting returns a copy of the given setting with the maps keyed by
+// interface{} values, e.g. from YAML decoders, converted into ones keyed by
+// strings, which can be marshalled to JSON. The setting is left untouched, as
+// it may be shared with Viper.
+func normalizeSetting(setting interface{}) interface{} {
+	switch setting := setting.(type) {
+	case map[string]interface{}:
+		normalizedSetting := make(map[string]interface{}, len(setting))
+		for key, value := range setting {
+			normalizedSetting[key] = normalizeSetting(value)
+		}
+		return normalizedSetting
+	case map[interface{}]interface{}:
+		normalizedSetting := make(map[string]interface{}, len(setting))
+		for key, value := range setting {
+			normalizedSetting[fmt.Sprint(key)] = normalizeSetting(value)
+		}
+		return normalizedSetting
+	case []interface{}:
+		normalizedSetting := make([]interface{}, len(setting))
+		for i, element := range setting {
+			normalizedSetting[i] = normalizeSetting(element)
+		}
+		return normalizedSetting
+	default:
+		return setting
+	}
+}
+
+// Export sets the values of the given config set, one for each config, on the
+// given Viper instance, where they take precedence over the other settings.
+// Objects are exported as maps keyed by strings, and numbers as float64
+// values, which Viper casts on reads.
+// If the config set has not been loaded, configset.ErrNotLoaded is returned.
+func Export(cs *configset.ConfigSet, setter Setter) error {
+	rawConfigSet := cs.Bytes()
+	if rawConfigSet == nil {
+		return configset.ErrNotLoaded
+	}
+	var configs map[string]interface{}
+	if err := json.Unmarshal(rawConfigSet, &configs); err != nil {
+		return fmt.Errorf("unmarshal from json: %w", err)
+	}
+	for configName, config := range configs {
+		setter.Set(configName, config)
+	}
+	return nil
+}
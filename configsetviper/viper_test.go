@@ -0,0 +1,64 @@
+package configsetviper_test
+
+import (
+	"testing"
+
+	"github.com/go-tk/configset"
+	. "github.com/go-tk/configset/configsetviper"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSource(t *testing.T) {
+	v := fakeViper{
+		"server": map[string]interface{}{
+			"port": 8080,
+			"tls":  map[interface{}]interface{}{"cert": "a.pem"},
+		},
+		"client": map[string]interface{}{
+			"tags": []interface{}{map[interface{}]interface{}{1: true}},
+		},
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 80
+host: localhost
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs configset.ConfigSet
+	cs.AddSources(NewSource(v))
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.client.timeout=10"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"client":{"tags":[{"1":true}],"timeout":10},"server":{"host":"localhost","port":8080,"tls":{"cert":"a.pem"}}}`, string(cs.Dump("", "")))
+	assert.Equal(t, []string{"viper"}, cs.Status().Sources)
+}
+
+func TestExport(t *testing.T) {
+	var cs configset.ConfigSet
+	v := fakeViper{}
+	err := Export(&cs, v)
+	assert.ErrorIs(t, err, configset.ErrNotLoaded)
+
+	err = cs.LoadFiles(map[string][]byte{"server.yaml": []byte("port: 8080\ntags: [a]\n")}, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = Export(&cs, v)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeViper{
+		"server": map[string]interface{}{
+			"port": float64(8080),
+			"tags": []interface{}{"a"},
+		},
+	}, v)
+}
+
+// fakeViper implements the interfaces of Viper with the top-level settings.
+type fakeViper map[string]interface{}
+
+func (fv fakeViper) AllSettings() map[string]interface{} { return fv }
+
+func (fv fakeViper) Set(key string, value interface{}) { fv[key] = value }
@@ -0,0 +1,122 @@
+package configset
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WithDecodeCache returns an option that makes ReadValue cache the values
+// decoded, keyed by path and type of config, until the next load or update, so
+// that hot paths reading the same values, e.g. per request, don't decode the
+// same JSON over and over again. Configs are filled with deep copies of the
+// cached values, so modifying them doesn't affect the cache, except for the
+// unexported state of types implementing json.Unmarshaler.
+// Values are cached only if the decoding doesn't depend on the configs, i.e.
+// configs are zero values or the decode mode is DecodeReplace, see
+// WithDecodeMode, and values holding secret references resolved on demand are
+// never cached, see WithSecretsOnDemand.
+func WithDecodeCache() Option {
+	return func(options *options) { options.decodeCache = true }
+}
+
+// decodeCache caches the values decoded by ReadValue for a snapshot.
+type decodeCache struct {
+	values sync.Map
+}
+
+type decodeCacheKey struct {
+	path       string
+	configType reflect.Type
+}
+
+// isCacheable reports whether the value for the given config can be taken from
+// or put into the cache with the given decode mode.
+func (dc *decodeCache) isCacheable(config interface{}, decodeMode DecodeMode) bool {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return false
+	}
+	switch decodeMode {
+	case DecodeReplace:
+		return true
+	case DecodeOverlay:
+		return value.Elem().IsZero()
+	default:
+		return false
+	}
+}
+
+// get fills the given config with the cached value for the given path, if any,
+// and reports whether it did.
+func (dc *decodeCache) get(path string, config interface{}) bool {
+	value := reflect.ValueOf(config)
+	cachedValue, ok := dc.values.Load(decodeCacheKey{path, value.Type()})
+	if !ok {
+		return false
+	}
+	value.Elem().Set(copyValue(cachedValue.(reflect.Value)))
+	return true
+}
+
+// put caches the value of the given config decoded for the given path.
+func (dc *decodeCache) put(path string, config interface{}) {
+	value := reflect.ValueOf(config)
+	dc.values.Store(decodeCacheKey{path, value.Type()}, copyValue(value.Elem()))
+}
+
+// copyValue returns a deep copy of the given value, which shares nothing with
+// the value except for the unexported fields of structs.
+func copyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return value
+		}
+		valueCopy := reflect.New(value.Type().Elem())
+		valueCopy.Elem().Set(copyValue(value.Elem()))
+		return valueCopy
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		valueCopy := reflect.New(value.Type()).Elem()
+		valueCopy.Set(copyValue(value.Elem()))
+		return valueCopy
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		valueCopy := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			valueCopy.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return valueCopy
+	case reflect.Array:
+		valueCopy := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			valueCopy.Index(i).Set(copyValue(value.Index(i)))
+		}
+		return valueCopy
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		valueCopy := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			valueCopy.SetMapIndex(iterator.Key(), copyValue(iterator.Value()))
+		}
+		return valueCopy
+	case reflect.Struct:
+		valueCopy := reflect.New(value.Type()).Elem()
+		valueCopy.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if value.Type().Field(i).PkgPath == "" {
+				valueCopy.Field(i).Set(copyValue(value.Field(i)))
+			}
+		}
+		return valueCopy
+	default:
+		return value
+	}
+}
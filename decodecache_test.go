@@ -0,0 +1,80 @@
+package configset_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDecodeCache(t *testing.T) {
+	type Server struct {
+		Port  int               `json:"port"`
+		Hosts []string          `json:"hosts"`
+		Tags  map[string]string `json:"tags"`
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+hosts: [a, b]
+tags: {env: prod}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	cs.Configure(WithDecodeCache())
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	var server1 Server
+	err = cs.ReadValue("server", &server1)
+	assert.NoError(t, err)
+	server1.Hosts[0] = "c"
+	server1.Tags["env"] = "dev"
+	var server2 Server
+	err = cs.ReadValue("server", &server2)
+	assert.NoError(t, err)
+	assert.Equal(t, Server{Port: 8080, Hosts: []string{"a", "b"}, Tags: map[string]string{"env": "prod"}}, server2)
+
+	server3 := Server{Port: 1, Tags: map[string]string{"zone": "a"}}
+	err = cs.ReadValue("server", &server3)
+	assert.NoError(t, err)
+	assert.Equal(t, Server{Port: 8080, Hosts: []string{"a", "b"}, Tags: map[string]string{"env": "prod", "zone": "a"}}, server3)
+
+	err = cs.Set("server.port", 8081)
+	assert.NoError(t, err)
+	var server4 Server
+	err = cs.ReadValue("server", &server4)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, server4.Port)
+}
+
+func BenchmarkWithDecodeCache(b *testing.B) {
+	type Key struct {
+		Number int    `json:"number"`
+		String string `json:"string"`
+	}
+	for _, decodeCache := range []bool{false, true} {
+		decodeCache := decodeCache
+		b.Run(fmt.Sprintf("decodeCache=%v", decodeCache), func(b *testing.B) {
+			fs := newBenchmarkFs(b, 1, 100)
+			var cs ConfigSet
+			if decodeCache {
+				cs.Configure(WithDecodeCache())
+			}
+			if err := cs.Load(fs, "/my_etc", nil); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var config map[string]Key
+				if err := cs.ReadValue("config0", &config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Decryptor decrypts encrypted values, e.g. with age or AES keys.
+type Decryptor interface {
+	// Decrypt decrypts the given ciphertext, which is the content within the
+	// marker ENC[...], and returns the plaintext.
+	Decrypt(ciphertext string) (plaintext string, err error)
+}
+
+// WithDecryptor returns an option that sets the decryptor for string values
+// encrypted individually, which are marked as ENC[{ciphertext}] and decrypted
+// into plaintext strings at load time, for teams encrypting individual values
+// rather than whole files. Without the decryptor, the values are kept as they
+// are.
+func WithDecryptor(decryptor Decryptor) Option {
+	return func(options *options) { options.decryptor = decryptor }
+}
+
+const (
+	encryptedValuePrefix = "ENC["
+	encryptedValueSuffix = "]"
+)
+
+// decryptValues decrypts the encrypted values within the given config set, and
+// returns the config set along with the values decrypted.
+func decryptValues(rawConfigSet json.RawMessage, decryptor Decryptor) (json.RawMessage, []secretValue, error) {
+	var secretValues []secretValue
+	rawConfigSet, err := replaceStrings(rawConfigSet, func(path string, s string) (json.RawMessage, error) {
+		if !strings.HasPrefix(s, encryptedValuePrefix) || !strings.HasSuffix(s, encryptedValueSuffix) {
+			return nil, nil
+		}
+		ciphertext := s[len(encryptedValuePrefix) : len(s)-len(encryptedValueSuffix)]
+		plaintext, err := decryptor.Decrypt(ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt value; path=%q: %w", path, err)
+		}
+		value, err := json.Marshal(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("marshal to json; path=%q: %w", path, err)
+		}
+		original, _ := json.Marshal(s)
+		secretValues = append(secretValues, secretValue{path: path, value: value, original: original})
+		return value, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return rawConfigSet, secretValues, nil
+}
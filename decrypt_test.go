@@ -0,0 +1,43 @@
+package configset_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type base64Decryptor struct{}
+
+func (base64Decryptor) Decrypt(ciphertext string) (string, error) {
+	plaintext, err := base64.StdEncoding.DecodeString(ciphertext)
+	return string(plaintext), err
+}
+
+func TestConfigSet_Decryptor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: ENC[dGVzdA==]
+replicas:
+- password: ENC[]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"password":"ENC[dGVzdA==]","replicas":[{"password":"ENC[]"}],"user":"root"}}`, string(cs.Dump("", "")))
+
+	cs.Configure(WithDecryptor(base64Decryptor{}))
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.user=ENC[YWRtaW4=]"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"password":"test","replicas":[{"password":""}],"user":"admin"}}`, string(cs.Dump("", "")))
+	assert.Equal(t, `{"db":{"password":"******","replicas":[{"password":"******"}],"user":"******"}}`, string(cs.DumpRedacted("", "")))
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.user=ENC[!]"})
+	assert.EqualError(t, err, `decrypt value; path="db.user": illegal base64 data at input byte 0`)
+}
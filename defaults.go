@@ -0,0 +1,83 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/tidwall/sjson"
+	"sigs.k8s.io/yaml"
+)
+
+// SetDefault sets the default value for the given path. Default values make up
+// the lowest-precedence layer of the config set, below configuration files and
+// environment variables, and take effect at the next load.
+func SetDefault(path string, value interface{}) error { return cs.SetDefault(path, value) }
+
+// SetDefaults likes SetDefault but sets default values for multiple paths at a
+// time.
+func SetDefaults(values map[string]interface{}) error { return cs.SetDefaults(values) }
+
+// RegisterDefaultYAML registers the default config of the given name in form of
+// YAML, which is deep-merged into the defaults layer, so that library authors
+// can ship default configs within their modules, e.g. with go:embed, and have
+// the configuration files provided by operators overlaid on top.
+func RegisterDefaultYAML(name string, data []byte) error { return cs.RegisterDefaultYAML(name, data) }
+
+func (cs *ConfigSet) SetDefault(path string, value interface{}) error {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	return cs.setDefault(path, value)
+}
+
+func (cs *ConfigSet) setDefault(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal to json; path=%q: %w", path, err)
+	}
+	defaults := cs.defaults
+	if defaults == nil {
+		defaults = json.RawMessage("{}")
+	}
+	defaults, err = sjson.SetRawBytes(defaults, path, data)
+	if err != nil {
+		return fmt.Errorf("set json value; path=%q: %w", path, err)
+	}
+	cs.defaults = defaults
+	return nil
+}
+
+func (cs *ConfigSet) SetDefaults(values map[string]interface{}) error {
+	paths := make([]string, 0, len(values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	for _, path := range paths {
+		if err := cs.setDefault(path, values[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cs *ConfigSet) RegisterDefaultYAML(name string, data []byte) error {
+	rawConfig, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		return fmt.Errorf("convert yaml to json; name=%q: %w", name, err)
+	}
+	rawDefaults, err := sjson.SetRawBytes([]byte("{}"), joinPath("", name), rawConfig)
+	if err != nil {
+		return fmt.Errorf("set json value; name=%q: %w", name, err)
+	}
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if cs.defaults == nil {
+		cs.defaults = rawDefaults
+	} else {
+		cs.defaults = mergeJSON(cs.defaults, rawDefaults)
+	}
+	return nil
+}
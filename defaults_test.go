@@ -0,0 +1,66 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_SetDefault(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tls:
+  enabled: true
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.SetDefault("server.port", 80)
+	assert.NoError(t, err)
+	err = cs.SetDefaults(map[string]interface{}{
+		"server.tls.enabled":   false,
+		"server.tls.cert_file": "server.crt",
+		"server.host":          "localhost",
+		"log":                  map[string]interface{}{"level": "info"},
+	})
+	assert.NoError(t, err)
+	err = cs.SetDefault("server.timeout", func() {})
+	assert.EqualError(t, err, `marshal to json; path="server.timeout": json: unsupported type: func()`)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.log.level=debug"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"log":{"level":"debug"},"server":{"host":"localhost","port":8080,"tls":{"cert_file":"server.crt","enabled":true}}}`, string(cs.Dump("", "")))
+}
+
+func TestConfigSet_RegisterDefaultYAML(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.SetDefault("server.host", "0.0.0.0")
+	assert.NoError(t, err)
+	err = cs.RegisterDefaultYAML("server", []byte(`
+port: 80
+tls:
+  enabled: false
+`))
+	assert.NoError(t, err)
+	err = cs.RegisterDefaultYAML("server.v2", []byte(`
+port: 81
+`))
+	assert.NoError(t, err)
+	err = cs.RegisterDefaultYAML("client", []byte(`
+port: [80
+`))
+	assert.EqualError(t, err, `convert yaml to json; name="client": yaml: line 2: did not find expected ',' or ']'`)
+
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"0.0.0.0","port":8080,"tls":{"enabled":false}},"server.v2":{"port":81}}`, string(cs.Dump("", "")))
+}
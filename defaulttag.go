@@ -0,0 +1,126 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/yaml"
+)
+
+// applyDefaultTags sets the struct fields, which are missing in the given JSON
+// value, to the default values specified by the `default` struct tags, e.g.
+//
+//	Port int `json:"port" default:"8080"`
+//
+// Default values are in form of YAML except for strings, which are taken
+// literally.
+func applyDefaultTags(value reflect.Value, rawValue gjson.Result) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return applyStructDefaultTags(value, rawValue)
+	case reflect.Slice, reflect.Array:
+		rawElements := rawValue.Array()
+		for i := 0; i < value.Len() && i < len(rawElements); i++ {
+			if err := applyDefaultTags(value.Index(i), rawElements[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applyStructDefaultTags(value reflect.Value, rawValue gjson.Result) error {
+	rawFields := rawValue.Map()
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		fieldName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if fieldName == "" {
+			if err := applyDefaultTags(fieldValue, rawValue); err != nil {
+				return err
+			}
+			continue
+		}
+		rawFieldValue, ok := lookUpRawField(rawFields, fieldName)
+		if !ok {
+			if defaultValue, ok := field.Tag.Lookup("default"); ok {
+				if err := setDefaultValue(fieldValue, defaultValue); err != nil {
+					return fmt.Errorf("set default value; fieldName=%q defaultValue=%q: %w", field.Name, defaultValue, err)
+				}
+			}
+		}
+		if err := applyDefaultTags(fieldValue, rawFieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON name of the given struct field, or an empty
+// string for embedded structs whose fields are promoted.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag != "" {
+		return tag, true
+	}
+	if field.Anonymous {
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct {
+			return "", true
+		}
+	}
+	return field.Name, true
+}
+
+func lookUpRawField(rawFields map[string]gjson.Result, fieldName string) (gjson.Result, bool) {
+	if rawField, ok := rawFields[fieldName]; ok {
+		return rawField, true
+	}
+	// encoding/json matches field names case-insensitively.
+	for key, rawField := range rawFields {
+		if strings.EqualFold(key, fieldName) {
+			return rawField, true
+		}
+	}
+	return gjson.Result{}, false
+}
+
+func setDefaultValue(value reflect.Value, defaultValue string) error {
+	if value.Kind() == reflect.String {
+		value.SetString(defaultValue)
+		return nil
+	}
+	data, err := yaml.YAMLToJSON([]byte(defaultValue))
+	if err != nil {
+		return fmt.Errorf("convert yaml to json: %w", err)
+	}
+	if err := json.Unmarshal(data, value.Addr().Interface()); err != nil {
+		return fmt.Errorf("unmarshal from json: %w", err)
+	}
+	return nil
+}
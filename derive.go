@@ -0,0 +1,37 @@
+package configset
+
+import (
+	"context"
+	"fmt"
+)
+
+// Derive returns a child config set, which inherits the options, rules,
+// validators, types, default values and sources of the config set, see Clone,
+// and is reloaded with the given environment variables applied on top of the
+// ones of the config set, which take precedence for the same keys, e.g. for
+// per-tenant or per-test variations of a base configuration. The child is
+// independent of the config set afterwards. If the config set has not been
+// loaded, ErrNotLoaded is returned.
+func Derive(environment []string) (*ConfigSet, error) { return cs.Derive(environment) }
+
+func (cs *ConfigSet) Derive(environment []string) (*ConfigSet, error) {
+	return cs.DeriveContext(context.Background(), environment)
+}
+
+// DeriveContext likes Derive but with a context, which is passed to the sources.
+func DeriveContext(ctx context.Context, environment []string) (*ConfigSet, error) {
+	return cs.DeriveContext(ctx, environment)
+}
+
+func (cs *ConfigSet) DeriveContext(ctx context.Context, environment []string) (*ConfigSet, error) {
+	child := cs.Clone()
+	input := child.input
+	if input.fs == nil {
+		return nil, ErrNotLoaded
+	}
+	input.environment = append(input.environment, environment...)
+	if err := child.load(ctx, &input); err != nil {
+		return nil, fmt.Errorf("load child config set: %w", err)
+	}
+	return child, nil
+}
@@ -0,0 +1,34 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Derive(t *testing.T) {
+	var cs ConfigSet
+	_, err := cs.Derive(nil)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+
+	cs.AddRules(Assert("server.port", Required()))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost", "CONFIGSET.server.tenant=a"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	child, err := cs.Derive([]string{"CONFIGSET.server.tenant=b", "CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8081,"tenant":"b"}}`, string(child.Dump("", "")))
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080,"tenant":"a"}}`, string(cs.Dump("", "")))
+
+	err = child.Delete("server.port")
+	assert.ErrorIs(t, err, ErrRuleViolation)
+}
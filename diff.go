@@ -0,0 +1,128 @@
+package configset
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Diff compares the config sets a and b and returns the changes from a to b,
+// sorted by path, so that deploy tooling can show what a config change will do,
+// e.g. by loading a clone of the config set from the new files. Objects are
+// compared key by key, whereas other values, including arrays, are compared as
+// a whole.
+func Diff(a, b *ConfigSet) []Change {
+	return diffValues(nil, "", gjson.ParseBytes(a.Snapshot().raw), gjson.ParseBytes(b.Snapshot().raw))
+}
+
+// DiffJSON likes Diff but compares the given values in form of JSON for the
+// given path, e.g. the values for a path prefix received before and after a
+// change, see Subscribe. A nil value means the value does not exist.
+func DiffJSON(path string, oldValue, newValue json.RawMessage) []Change {
+	return diffValues(nil, path, gjson.ParseBytes(oldValue), gjson.ParseBytes(newValue))
+}
+
+// DiffRedacted likes Diff but with the values of secrets in the changes masked
+// like DumpRedacted does, e.g. for printing the changes in CI logs. Changes of
+// secrets are still detected, though their values are masked on both sides.
+func DiffRedacted(a, b *ConfigSet) []Change {
+	changes := Diff(a, b)
+	redactedRawA, redactedRawB := a.redactedRaw(), b.redactedRaw()
+	for i := range changes {
+		change := &changes[i]
+		if change.OldValue != nil {
+			change.OldValue = json.RawMessage(gjson.GetBytes(redactedRawA, change.Path).Raw)
+		}
+		if change.NewValue != nil {
+			change.NewValue = json.RawMessage(gjson.GetBytes(redactedRawB, change.Path).Raw)
+		}
+	}
+	return changes
+}
+
+// Change represents a change of a value between two config sets. It can be
+// rendered as text with String or FormatChanges, or as JSON.
+type Change struct {
+	Kind     ChangeKind      `json:"kind"`
+	Path     string          `json:"path"`
+	OldValue json.RawMessage `json:"oldValue,omitempty"`
+	NewValue json.RawMessage `json:"newValue,omitempty"`
+}
+
+// ChangeKind represents a kind of changes of values.
+type ChangeKind string
+
+const (
+	// ChangeAdded indicates the value is added.
+	ChangeAdded ChangeKind = "added"
+
+	// ChangeRemoved indicates the value is removed.
+	ChangeRemoved ChangeKind = "removed"
+
+	// ChangeModified indicates the value is modified.
+	ChangeModified ChangeKind = "modified"
+)
+
+// String returns the change in form of text, i.e. "+ {path}={newValue}" for
+// added values, "- {path}={oldValue}" for removed values, and
+// "~ {path}={oldValue} -> {newValue}" for modified values.
+func (c Change) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + c.Path + "=" + string(c.NewValue)
+	case ChangeRemoved:
+		return "- " + c.Path + "=" + string(c.OldValue)
+	default:
+		return "~ " + c.Path + "=" + string(c.OldValue) + " -> " + string(c.NewValue)
+	}
+}
+
+// FormatChanges returns the given changes in form of text, one line for each
+// change.
+func FormatChanges(changes []Change) string {
+	var builder strings.Builder
+	for _, change := range changes {
+		builder.WriteString(change.String())
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+func diffValues(changes []Change, path string, oldValue, newValue gjson.Result) []Change {
+	switch {
+	case !oldValue.Exists() && !newValue.Exists():
+		return changes
+	case !oldValue.Exists():
+		return append(changes, Change{Kind: ChangeAdded, Path: path, NewValue: json.RawMessage(newValue.Raw)})
+	case !newValue.Exists():
+		return append(changes, Change{Kind: ChangeRemoved, Path: path, OldValue: json.RawMessage(oldValue.Raw)})
+	case oldValue.IsObject() && newValue.IsObject():
+		oldValues, newValues := oldValue.Map(), newValue.Map()
+		keys := make([]string, 0, len(oldValues)+len(newValues))
+		for key := range oldValues {
+			keys = append(keys, key)
+		}
+		for key := range newValues {
+			if _, ok := oldValues[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			changes = diffValues(changes, joinPath(path, key), oldValues[key], newValues[key])
+		}
+		return changes
+	case reflect.DeepEqual(oldValue.Value(), newValue.Value()):
+		return changes
+	default:
+		return append(changes, Change{
+			Kind:     ChangeModified,
+			Path:     path,
+			OldValue: json.RawMessage(oldValue.Raw),
+			NewValue: json.RawMessage(newValue.Raw),
+		})
+	}
+}
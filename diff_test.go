@@ -0,0 +1,90 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	var a, b ConfigSet
+	assert.Empty(t, Diff(&a, &b))
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/old_etc/server.yaml", []byte(`
+host: localhost
+port: 8080
+hosts: [a, b]
+limits: {cpu: 1}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/new_etc/server.yaml", []byte(`
+port: 8081
+hosts: [a, b]
+limits: {cpu: 1.0}
+tls: {cert: a.pem}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := a.Load(fs, "/old_etc", nil)
+	assert.NoError(t, err)
+	err = b.Load(fs, "/new_etc", nil)
+	assert.NoError(t, err)
+
+	changes := Diff(&a, &b)
+	assert.Equal(t, []Change{
+		{Kind: ChangeRemoved, Path: "server.host", OldValue: json.RawMessage(`"localhost"`)},
+		{Kind: ChangeModified, Path: "server.port", OldValue: json.RawMessage(`8080`), NewValue: json.RawMessage(`8081`)},
+		{Kind: ChangeAdded, Path: "server.tls", NewValue: json.RawMessage(`{"cert":"a.pem"}`)},
+	}, changes)
+	assert.Equal(t, `- server.host="localhost"
+~ server.port=8080 -> 8081
++ server.tls={"cert":"a.pem"}
+`, FormatChanges(changes))
+	data, err := json.Marshal(changes[0])
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kind":"removed","path":"server.host","oldValue":"localhost"}`, string(data))
+}
+
+func TestDiffRedacted(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/old_etc/db.yaml", []byte(`
+user: root
+password: hunter2
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/new_etc/db.yaml", []byte(`
+user: admin
+password: hunter3
+replica: {password: hunter4}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var a, b ConfigSet
+	a.AddSecretPaths("db.password")
+	b.AddSecretPaths("db.password", "db.replica.password")
+	err := a.Load(fs, "/old_etc", nil)
+	assert.NoError(t, err)
+	err = b.Load(fs, "/new_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Kind: ChangeModified, Path: "db.password", OldValue: json.RawMessage(`"******"`), NewValue: json.RawMessage(`"******"`)},
+		{Kind: ChangeAdded, Path: "db.replica", NewValue: json.RawMessage(`{"password":"******"}`)},
+		{Kind: ChangeModified, Path: "db.user", OldValue: json.RawMessage(`"root"`), NewValue: json.RawMessage(`"admin"`)},
+	}, DiffRedacted(&a, &b))
+}
+
+func TestDiffJSON(t *testing.T) {
+	assert.Equal(t, []Change{
+		{Kind: ChangeModified, Path: "server.port", OldValue: json.RawMessage(`8080`), NewValue: json.RawMessage(`8081`)},
+		{Kind: ChangeAdded, Path: "server.tls", NewValue: json.RawMessage(`{"cert":"a.pem"}`)},
+	}, DiffJSON("server", json.RawMessage(`{"port":8080}`), json.RawMessage(`{"port":8081,"tls":{"cert":"a.pem"}}`)))
+	assert.Equal(t, []Change{
+		{Kind: ChangeAdded, Path: "server", NewValue: json.RawMessage(`{"port":8080}`)},
+	}, DiffJSON("server", nil, json.RawMessage(`{"port":8080}`)))
+}
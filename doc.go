@@ -0,0 +1,17 @@
+// Package configset aggregates configuration files under a directory into one
+// config set, with values overridden by environment variables.
+//
+// All the functions of the package and the methods of the types are safe for
+// concurrent use. Reads are served from the immutable snapshot of the config set
+// as of the last load, so they never wait for loads or reloads in progress.
+//
+// The cost model of reads on hot paths, e.g. per request, is as follows.
+// Getting the snapshot is an atomic load, so readers never contend with each
+// other or with loads and updates, which build a new snapshot under a mutex and
+// swap it in atomically. Finding a value by path is a map lookup, once the index
+// of the snapshot has been built on the first read. Decoding the value into a
+// config is the dominant cost, which WithDecodeCache avoids for repeated reads.
+// Dump copies the config set, whereas Bytes doesn't, and Fingerprint is computed
+// once per snapshot. Explain, Status and the like take the mutex, so they are
+// meant for diagnostics rather than hot paths.
+package configset
@@ -0,0 +1,270 @@
+package configset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/yaml"
+)
+
+// DumpYAML likes Dump but returns the config set in form of YAML, with keys
+// sorted, e.g. for comparing the effective configuration against the files.
+func DumpYAML() []byte { return cs.DumpYAML() }
+
+// DumpFlat likes Dump but returns the config set in form of lines such as
+// {path}={value}, one for each leaf value, with values in form of JSON and lines
+// sorted, e.g.
+//
+//	aaa.numbers.0=1
+//	aaa.numbers.1=-2
+//	aaa.title="hello"
+//
+// so that config sets of environments can be diffed with standard text tools.
+func DumpFlat() []byte { return cs.DumpFlat() }
+
+// DumpAt likes Dump but returns the value for the given path only, so that
+// debugging output can be limited to one section of the config set.
+// If no value can be found by the path, ErrValueNotFound is returned.
+func DumpAt(path string, prefix string, indention string) (json.RawMessage, error) {
+	return cs.DumpAt(path, prefix, indention)
+}
+
+// DumpTo likes Dump but writes the config set to the given writer in the
+// format set in the given options. Config sets in form of JSON are streamed
+// to the writer rather than being materialized in memory, e.g. for writing
+// large config sets to log sinks or HTTP responses.
+// If the config set has not been loaded, ErrNotLoaded is returned.
+// If no value can be found by the path set in the options, ErrValueNotFound is
+// returned.
+func DumpTo(w io.Writer, options DumpOptions) error { return cs.DumpTo(w, options) }
+
+// DumpAs likes Dump but returns the config set in the given format, e.g.
+// DumpFormatYAML, so that the format can be chosen at run time.
+func DumpAs(format DumpFormat) ([]byte, error) { return cs.DumpAs(format) }
+
+// DumpOptions represents the options for DumpTo.
+type DumpOptions struct {
+	// Format is the format of the dump. The default format is DumpFormatJSON.
+	Format DumpFormat
+
+	// Path is the path of the value to dump, see DumpAt. An empty path refers
+	// to the whole config set.
+	Path string
+
+	// Prefix and Indention are for indenting the dump in form of JSON, see
+	// Dump.
+	Prefix    string
+	Indention string
+}
+
+// DumpFormat represents a format of dumps.
+type DumpFormat string
+
+const (
+	// DumpFormatJSON is the format of Dump.
+	DumpFormatJSON DumpFormat = "json"
+
+	// DumpFormatYAML is the format of DumpYAML.
+	DumpFormatYAML DumpFormat = "yaml"
+
+	// DumpFormatFlat is the format of DumpFlat.
+	DumpFormatFlat DumpFormat = "flat"
+
+	// DumpFormatTOML is the format of TOML, in which null values are omitted.
+	// The value to dump must be an object.
+	DumpFormatTOML DumpFormat = "toml"
+
+	// DumpFormatDotenv is the format of .env files, i.e. lines such as
+	// {NAME}={value}, one for each leaf value, where the names are the paths
+	// of the values in upper case with the other characters than letters and
+	// digits replaced with underscores, e.g. SERVER_TAGS_0="a".
+	DumpFormatDotenv DumpFormat = "dotenv"
+)
+
+func (cs *ConfigSet) DumpTo(w io.Writer, options DumpOptions) error {
+	return cs.Snapshot().DumpTo(w, options)
+}
+
+func (cs *ConfigSet) DumpAs(format DumpFormat) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := cs.DumpTo(&buffer, DumpOptions{Format: format}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (cs *ConfigSet) DumpAt(path string, prefix string, indention string) (json.RawMessage, error) {
+	return cs.Snapshot().DumpAt(path, prefix, indention)
+}
+
+func (cs *ConfigSet) DumpYAML() []byte { return cs.Snapshot().DumpYAML() }
+
+func (cs *ConfigSet) DumpFlat() []byte { return cs.Snapshot().DumpFlat() }
+
+// DumpAt likes the package-level DumpAt but dumps the snapshot.
+func (s *Snapshot) DumpAt(path string, prefix string, indention string) (json.RawMessage, error) {
+	if s.raw == nil {
+		return nil, fmt.Errorf("%w; path=%q", ErrNotLoaded, path)
+	}
+	value := s.getValue(path)
+	if value == "" {
+		return nil, fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	return (&Snapshot{raw: json.RawMessage(value)}).Dump(prefix, indention), nil
+}
+
+// DumpTo likes the package-level DumpTo but dumps the snapshot.
+func (s *Snapshot) DumpTo(w io.Writer, options DumpOptions) error {
+	if s.raw == nil {
+		return fmt.Errorf("%w; path=%q", ErrNotLoaded, options.Path)
+	}
+	raw := s.raw
+	if options.Path != "" {
+		value := gjson.GetBytes(raw, options.Path)
+		if !value.Exists() {
+			return fmt.Errorf("%w; path=%q", ErrValueNotFound, options.Path)
+		}
+		raw = json.RawMessage(value.Raw)
+	}
+	var data []byte
+	switch options.Format {
+	case "", DumpFormatJSON:
+		if len(options.Prefix)+len(options.Indention) == 0 {
+			data = raw
+			break
+		}
+		bufferedWriter := bufio.NewWriter(w)
+		writeIndentedJSON(bufferedWriter, raw, options.Prefix, options.Indention)
+		if err := bufferedWriter.Flush(); err != nil {
+			return fmt.Errorf("write dump: %w", err)
+		}
+		return nil
+	case DumpFormatYAML:
+		data = (&Snapshot{raw: raw}).DumpYAML()
+	case DumpFormatFlat:
+		if options.Path != "" {
+			return fmt.Errorf("configset: path unsupported by dump format; format=%q", options.Format)
+		}
+		data = s.DumpFlat()
+	case DumpFormatTOML:
+		var err error
+		data, err = encodeTOML(gjson.ParseBytes(raw))
+		if err != nil {
+			return err
+		}
+	case DumpFormatDotenv:
+		data = encodeDotenv(gjson.ParseBytes(raw))
+	default:
+		return fmt.Errorf("configset: unknown dump format; format=%q", options.Format)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write dump: %w", err)
+	}
+	return nil
+}
+
+// writeIndentedJSON writes the given JSON value indented like json.Indent does,
+// followed by a newline, without materializing the indented value in memory.
+func writeIndentedJSON(w *bufio.Writer, raw json.RawMessage, prefix string, indention string) {
+	depth := 0
+	newline := func() {
+		w.WriteByte('\n')
+		w.WriteString(prefix)
+		for i := 0; i < depth; i++ {
+			w.WriteString(indention)
+		}
+	}
+	for i := 0; i < len(raw); i++ {
+		switch c := raw[i]; c {
+		case ' ', '\t', '\n', '\r':
+		case '"':
+			j := i + 1
+			for ; j < len(raw) && raw[j] != '"'; j++ {
+				if raw[j] == '\\' {
+					j++
+				}
+			}
+			w.Write(raw[i : j+1])
+			i = j
+		case '{', '[':
+			w.WriteByte(c)
+			j := i + 1
+			for j < len(raw) && (raw[j] == ' ' || raw[j] == '\t' || raw[j] == '\n' || raw[j] == '\r') {
+				j++
+			}
+			if j < len(raw) && (raw[j] == '}' || raw[j] == ']') {
+				w.WriteByte(raw[j])
+				i = j
+				continue
+			}
+			depth++
+			newline()
+		case '}', ']':
+			depth--
+			newline()
+			w.WriteByte(c)
+		case ',':
+			w.WriteByte(c)
+			newline()
+		case ':':
+			w.WriteString(": ")
+		default:
+			w.WriteByte(c)
+		}
+	}
+	w.WriteByte('\n')
+}
+
+// DumpYAML likes the package-level DumpYAML but dumps the snapshot.
+func (s *Snapshot) DumpYAML() []byte {
+	if s.raw == nil {
+		return nil
+	}
+	data, _ := yaml.JSONToYAML(s.raw)
+	return data
+}
+
+// DumpFlat likes the package-level DumpFlat but dumps the snapshot.
+func (s *Snapshot) DumpFlat() []byte {
+	if s.raw == nil {
+		return nil
+	}
+	var lines []string
+	gjson.ParseBytes(s.raw).ForEach(func(key, value gjson.Result) bool {
+		lines = appendFlatLines(lines, joinPath("", key.Str), value)
+		return true
+	})
+	sort.Strings(lines)
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(line)
+		builder.WriteByte('\n')
+	}
+	return []byte(builder.String())
+}
+
+func appendFlatLines(lines []string, path string, value gjson.Result) []string {
+	n := 0
+	if value.IsObject() || value.IsArray() {
+		value.ForEach(func(key, value gjson.Result) bool {
+			if key.Type == gjson.Number {
+				lines = appendFlatLines(lines, joinPath(path, strconv.Itoa(int(key.Num))), value)
+			} else {
+				lines = appendFlatLines(lines, joinPath(path, key.Str), value)
+			}
+			n++
+			return true
+		})
+	}
+	if n == 0 {
+		lines = append(lines, path+"="+value.Raw)
+	}
+	return lines
+}
@@ -0,0 +1,211 @@
+package configset_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Dump(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`{"port": 8080, "tags": [{"b": 1, "a": 2}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.SetDefault("server.timeout", 30)
+	assert.NoError(t, err)
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080,"tags":[{"a":2,"b":1}],"timeout":30}}`, string(cs.Dump("", "")))
+	err = cs.MergeAt("", []byte(`{"client": {"timeout": 10}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"client":{"timeout":10},"server":{"host":"localhost","port":8080,"tags":[{"a":2,"b":1}],"timeout":30}}`, string(cs.Dump("", "")))
+}
+
+func TestConfigSet_DumpTo(t *testing.T) {
+	var cs ConfigSet
+	var buffer bytes.Buffer
+	err := cs.DumpTo(&buffer, DumpOptions{})
+	assert.ErrorIs(t, err, ErrNotLoaded)
+	_, err = cs.DumpAs(DumpFormatYAML)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+	assert.Empty(t, buffer.String())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+name: "a \"b\" [c]"
+tags: [a, {b: []}]
+labels: {}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	err = cs.DumpTo(&buffer, DumpOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, string(cs.Dump("", "")), buffer.String())
+	buffer.Reset()
+	err = cs.DumpTo(&buffer, DumpOptions{Prefix: ">", Indention: "\t"})
+	assert.NoError(t, err)
+	assert.Equal(t, string(cs.Dump(">", "\t")), buffer.String())
+	buffer.Reset()
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatYAML, Path: "server.tags"})
+	assert.NoError(t, err)
+	assert.Equal(t, "- a\n- b: []\n", buffer.String())
+	buffer.Reset()
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatFlat})
+	assert.NoError(t, err)
+	assert.Equal(t, string(cs.DumpFlat()), buffer.String())
+	buffer.Reset()
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatTOML})
+	assert.NoError(t, err)
+	assert.Equal(t, `[server]
+name = "a \"b\" [c]"
+port = 8080
+tags = ["a", { b = [] }]
+
+[server.labels]
+`, buffer.String())
+	buffer.Reset()
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatDotenv})
+	assert.NoError(t, err)
+	assert.Equal(t, `SERVER_LABELS={}
+SERVER_NAME="a \"b\" [c]"
+SERVER_PORT=8080
+SERVER_TAGS_0="a"
+SERVER_TAGS_1_B=[]
+`, buffer.String())
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatTOML, Path: "server.port"})
+	assert.EqualError(t, err, `configset: non-object value unsupported by dump format; format="toml"`)
+	err = cs.DumpTo(&buffer, DumpOptions{Path: "server.host"})
+	assert.ErrorIs(t, err, ErrValueNotFound)
+	err = cs.DumpTo(&buffer, DumpOptions{Format: "xml"})
+	assert.EqualError(t, err, `configset: unknown dump format; format="xml"`)
+}
+
+func TestConfigSet_DumpYAML(t *testing.T) {
+	var cs ConfigSet
+	assert.Nil(t, cs.DumpYAML())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+name: "8080"
+tags: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `server:
+  name: "8080"
+  port: 8080
+  tags:
+  - a
+  - b
+`, string(cs.DumpYAML()))
+}
+
+func TestConfigSet_DumpAs(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`port: 8080`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	data, err := cs.DumpAs(DumpFormatYAML)
+	assert.NoError(t, err)
+	assert.Equal(t, "server:\n  port: 8080\n", string(data))
+	data, err = cs.DumpAs(DumpFormatJSON)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(data))
+	_, err = cs.DumpAs("xml")
+	assert.EqualError(t, err, `configset: unknown dump format; format="xml"`)
+}
+
+func TestConfigSet_DumpAt(t *testing.T) {
+	var cs ConfigSet
+	_, err := cs.DumpAt("server", "", "")
+	assert.ErrorIs(t, err, ErrNotLoaded)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tls: {cert: a.pem}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	raw, err := cs.DumpAt("server.tls", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"cert":"a.pem"}`, string(raw))
+	raw, err = cs.DumpAt("server.tls", "", "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"cert\": \"a.pem\"\n}\n", string(raw))
+	_, err = cs.DumpAt("server.host", "", "")
+	assert.EqualError(t, err, `configset: value not found; path="server.host"`)
+}
+
+func TestConfigSet_DumpFlat(t *testing.T) {
+	var cs ConfigSet
+	assert.Nil(t, cs.DumpFlat())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/aaa.yaml", []byte(`
+title: hello
+numbers: [1, -2]
+empty: {}
+nested:
+  - {a.b: true}
+  - []
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `aaa.empty={}
+aaa.nested.0.a\.b=true
+aaa.nested.1=[]
+aaa.numbers.0=1
+aaa.numbers.1=-2
+aaa.title="hello"
+`, string(cs.DumpFlat()))
+}
+
+func TestConfigSet_DumpTo_TOML(t *testing.T) {
+	var cs ConfigSet
+	err := cs.MergeAt("", []byte(`{
+		"db": {
+			"replicas": [{"host": "r1", "tags": {"zone": "a"}}, {"host": "r2"}],
+			"primary": {"host": "p", "timeout": null, "my key": "x\ty"},
+			"ratio": 0.5
+		}
+	}`))
+	assert.NoError(t, err)
+	var buffer bytes.Buffer
+	err = cs.DumpTo(&buffer, DumpOptions{Format: DumpFormatTOML})
+	assert.NoError(t, err)
+	assert.Equal(t, `[db]
+ratio = 0.5
+
+[db.primary]
+host = "p"
+"my key" = "x\ty"
+
+[[db.replicas]]
+host = "r1"
+
+[db.replicas.tags]
+zone = "a"
+
+[[db.replicas]]
+host = "r2"
+`, buffer.String())
+}
@@ -0,0 +1,60 @@
+package configset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// DumpEncrypted returns the config set in form of JSON, secrets included,
+// encrypted with age to the given recipients, e.g. parsed from a public key by
+// age.ParseX25519Recipient, so that users can attach the real configuration to
+// support tickets which only maintainers holding the identities can read. The
+// dump is ASCII-armored and can be decrypted with `age -d -i {key file}` or
+// DecryptDump.
+func DumpEncrypted(recipients ...age.Recipient) ([]byte, error) {
+	return cs.DumpEncrypted(recipients...)
+}
+
+// DecryptDump decrypts the given config set encrypted by DumpEncrypted with the
+// given identities, and returns the config set in form of JSON.
+func DecryptDump(encryptedDump []byte, identities ...age.Identity) (json.RawMessage, error) {
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(encryptedDump)), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt dump: %w", err)
+	}
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt dump: %w", err)
+	}
+	return raw, nil
+}
+
+func (cs *ConfigSet) DumpEncrypted(recipients ...age.Recipient) ([]byte, error) {
+	snapshot := cs.Snapshot()
+	if snapshot.raw == nil {
+		return nil, ErrNotLoaded
+	}
+	raw := snapshot.Dump("", "")
+	defer wipeBuffer(raw)
+	var buffer bytes.Buffer
+	armorWriter := armor.NewWriter(&buffer)
+	w, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt dump: %w", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return nil, fmt.Errorf("encrypt dump: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("encrypt dump: %w", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, fmt.Errorf("encrypt dump: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
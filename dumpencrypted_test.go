@@ -0,0 +1,55 @@
+package configset_test
+
+import (
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_DumpEncrypted(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := age.ParseX25519Recipient(identity.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	_, err = cs.DumpEncrypted(recipient)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: test
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	encryptedDump, err := cs.DumpEncrypted(recipient)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, strings.HasPrefix(string(encryptedDump), "-----BEGIN AGE ENCRYPTED FILE-----\n"))
+	assert.NotContains(t, string(encryptedDump), "test")
+
+	raw, err := DecryptDump(encryptedDump, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"password":"test"}}`, string(raw))
+
+	otherIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptDump(encryptedDump, otherIdentity)
+	assert.EqualError(t, err, "decrypt dump: no identity matched any of the recipients")
+	_, err = DecryptDump([]byte("foo"), identity)
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package configset
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+)
+
+// encodeTOML encodes the given value, which must be an object, in form of TOML.
+// Null values are omitted as TOML has no null.
+func encodeTOML(value gjson.Result) ([]byte, error) {
+	if !value.IsObject() {
+		return nil, fmt.Errorf("configset: non-object value unsupported by dump format; format=%q", DumpFormatTOML)
+	}
+	var builder strings.Builder
+	writeTOMLTable(&builder, nil, value, false)
+	return []byte(builder.String()), nil
+}
+
+func writeTOMLTable(builder *strings.Builder, keys []string, table gjson.Result, isArrayElement bool) {
+	if len(keys) >= 1 {
+		if builder.Len() >= 1 {
+			builder.WriteByte('\n')
+		}
+		if isArrayElement {
+			builder.WriteString("[[" + formatTOMLKeys(keys) + "]]\n")
+		} else {
+			builder.WriteString("[" + formatTOMLKeys(keys) + "]\n")
+		}
+	}
+	type member struct {
+		key   string
+		value gjson.Result
+	}
+	var tables, tableArrays []member
+	table.ForEach(func(key, value gjson.Result) bool {
+		switch {
+		case value.Type == gjson.Null:
+		case value.IsObject():
+			tables = append(tables, member{key.Str, value})
+		case isTOMLTableArray(value):
+			tableArrays = append(tableArrays, member{key.Str, value})
+		default:
+			builder.WriteString(formatTOMLKey(key.Str) + " = ")
+			writeTOMLValue(builder, value)
+			builder.WriteByte('\n')
+		}
+		return true
+	})
+	for _, member := range tables {
+		writeTOMLTable(builder, append(keys[:len(keys):len(keys)], member.key), member.value, false)
+	}
+	for _, member := range tableArrays {
+		for _, element := range member.value.Array() {
+			writeTOMLTable(builder, append(keys[:len(keys):len(keys)], member.key), element, true)
+		}
+	}
+}
+
+// isTOMLTableArray reports whether the given value is a non-empty array of
+// objects only, which is encoded as an array of tables.
+func isTOMLTableArray(value gjson.Result) bool {
+	if !value.IsArray() {
+		return false
+	}
+	elements := value.Array()
+	for _, element := range elements {
+		if !element.IsObject() {
+			return false
+		}
+	}
+	return len(elements) >= 1
+}
+
+func writeTOMLValue(builder *strings.Builder, value gjson.Result) {
+	switch {
+	case value.Type == gjson.String:
+		builder.WriteString(quoteTOMLString(value.Str))
+	case value.IsObject():
+		builder.WriteByte('{')
+		n := 0
+		value.ForEach(func(key, value gjson.Result) bool {
+			if value.Type == gjson.Null {
+				return true
+			}
+			if n >= 1 {
+				builder.WriteString(", ")
+			} else {
+				builder.WriteByte(' ')
+			}
+			n++
+			builder.WriteString(formatTOMLKey(key.Str) + " = ")
+			writeTOMLValue(builder, value)
+			return true
+		})
+		if n >= 1 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteByte('}')
+	case value.IsArray():
+		builder.WriteByte('[')
+		n := 0
+		value.ForEach(func(_, element gjson.Result) bool {
+			if element.Type == gjson.Null {
+				return true
+			}
+			if n >= 1 {
+				builder.WriteString(", ")
+			}
+			n++
+			writeTOMLValue(builder, element)
+			return true
+		})
+		builder.WriteByte(']')
+	default:
+		builder.WriteString(value.Raw)
+	}
+}
+
+func formatTOMLKeys(keys []string) string {
+	formattedKeys := make([]string, len(keys))
+	for i, key := range keys {
+		formattedKeys[i] = formatTOMLKey(key)
+	}
+	return strings.Join(formattedKeys, ".")
+}
+
+func formatTOMLKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !(isLetter(c) || isDigit(c) || c == '_' || c == '-') {
+			return quoteTOMLString(key)
+		}
+	}
+	return key
+}
+
+func quoteTOMLString(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\r':
+			builder.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f || r == utf8.RuneError {
+				fmt.Fprintf(&builder, `\u%04X`, r)
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// encodeDotenv encodes the given value in form of lines such as {NAME}={value},
+// one for each leaf value, where the names are the paths of the values in upper
+// case with non-alphanumeric characters replaced with underscores, e.g.
+// SERVER_TAGS_0="a". Lines are sorted by name.
+func encodeDotenv(value gjson.Result) []byte {
+	var lines []string
+	var appendLines func(name string, value gjson.Result)
+	appendLines = func(name string, value gjson.Result) {
+		n := 0
+		if value.IsObject() || value.IsArray() {
+			value.ForEach(func(key, value gjson.Result) bool {
+				subname := key.Str
+				if key.Type == gjson.Number {
+					subname = strconv.Itoa(int(key.Num))
+				}
+				if name != "" {
+					subname = name + "_" + subname
+				}
+				appendLines(subname, value)
+				n++
+				return true
+			})
+		}
+		if n >= 1 || name == "" {
+			return
+		}
+		line := formatDotenvName(name) + "="
+		switch value.Type {
+		case gjson.String:
+			line += quoteDotenvValue(value.Str)
+		case gjson.Null:
+		default:
+			line += value.Raw
+		}
+		lines = append(lines, line)
+	}
+	appendLines("", value)
+	sort.Strings(lines)
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(line)
+		builder.WriteByte('\n')
+	}
+	return []byte(builder.String())
+}
+
+func formatDotenvName(name string) string {
+	formattedName := []byte(strings.ToUpper(name))
+	for i, c := range formattedName {
+		if !(isLetter(c) || isDigit(c)) {
+			formattedName[i] = '_'
+		}
+	}
+	if len(formattedName) >= 1 && isDigit(formattedName[0]) {
+		return "_" + string(formattedName)
+	}
+	return string(formattedName)
+}
+
+func quoteDotenvValue(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', '$', '`':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		case '\n':
+			builder.WriteString(`\n`)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
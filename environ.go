@@ -0,0 +1,56 @@
+package configset
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Environ returns the config set in form of environment variables such as
+// CONFIGSET.{path}={value}, one for each leaf value, with values in form of
+// JSON, so that supervisors can pass the effective configuration to child
+// processes, which load the same config set by applying the environment
+// variables.
+func Environ() []string { return cs.Environ() }
+
+// AppendEnviron likes Environ but appends the environment variables to a copy
+// of the given environment, e.g. os.Environ(), with the existing environment
+// variables such as CONFIGSET.{path}={value} removed, since they have been
+// applied to the config set.
+func AppendEnviron(environment []string) []string { return cs.AppendEnviron(environment) }
+
+func (cs *ConfigSet) Environ() []string {
+	return cs.AppendEnviron(nil)
+}
+
+func (cs *ConfigSet) AppendEnviron(environment []string) []string {
+	cs.mutex.Lock()
+	envPrefix := cs.envPrefix()
+	cs.mutex.Unlock()
+	var newEnvironment []string
+	for _, kv := range environment {
+		if !strings.HasPrefix(kv, envPrefix) {
+			newEnvironment = append(newEnvironment, kv)
+		}
+	}
+	raw := cs.Snapshot().raw
+	if raw == nil {
+		return newEnvironment
+	}
+	gjson.ParseBytes(raw).ForEach(func(key, value gjson.Result) bool {
+		newEnvironment = appendEnviron(newEnvironment, envPrefix, joinPath("", key.Str), value)
+		return true
+	})
+	return newEnvironment
+}
+
+func appendEnviron(environment []string, envPrefix string, path string, value gjson.Result) []string {
+	if !value.IsObject() || len(value.Map()) == 0 {
+		return append(environment, envPrefix+path+"="+value.Raw)
+	}
+	value.ForEach(func(key, value gjson.Result) bool {
+		environment = appendEnviron(environment, envPrefix, joinPath(path, key.Str), value)
+		return true
+	})
+	return environment
+}
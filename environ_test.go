@@ -0,0 +1,90 @@
+package configset_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_AppendEnviron(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tags: [a, b]
+labels: {}
+a.b: "x y"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	environment := []string{"HOME=/root", "CONFIGSET.server.host=localhost"}
+	err := cs.Load(fs, "/my_etc", environment)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	newEnvironment := cs.AppendEnviron(environment)
+	assert.Equal(t, []string{
+		"HOME=/root",
+		`CONFIGSET.server.a\.b="x y"`,
+		`CONFIGSET.server.host="localhost"`,
+		"CONFIGSET.server.labels={}",
+		"CONFIGSET.server.port=8080",
+		`CONFIGSET.server.tags=["a","b"]`,
+	}, newEnvironment)
+
+	var cs2 ConfigSet
+	if err := afero.WriteFile(fs, "/my_etc2/server.yaml", []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs2.Load(fs, "/my_etc2", newEnvironment)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(cs.Dump("", "")), string(cs2.Dump("", "")))
+}
+
+func TestConfigSet_Load_environment(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+hosts: [a, b]
+tls: off
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", []string{
+		"CONFIGSET.server.hosts.1=c",
+		"CONFIGSET.server.hosts.3=e",
+		"CONFIGSET.server.hosts.-1=f",
+		"CONFIGSET.server.tls.cert=a.pem",
+		"CONFIGSET.server.ports.1=8080",
+		`CONFIGSET.server.labels.app\.name=foo`,
+		"CONFIGSET.log={level: info}",
+		"CONFIGSET.log.format=json",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"log":{"format":"json","level":"info"},"server":{"hosts":["a","c","f","e"],`+
+		`"labels":{"app.name":"foo"},"ports":[null,8080],"tls":{"cert":"a.pem"}}}`, string(cs.Dump("", "")))
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.hosts.x=c"})
+	assert.EqualError(t, err, `set json value; path="server.hosts.x": cannot set array element for non-numeric key 'x'`)
+}
+
+func BenchmarkConfigSet_Load_environment(b *testing.B) {
+	fs := newBenchmarkFs(b, 10, 100)
+	var environment []string
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 100; j++ {
+			environment = append(environment, fmt.Sprintf("CONFIGSET.config%d.key%d.number=%d", i, j, -j))
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var cs ConfigSet
+		if err := cs.Load(fs, "/my_etc", environment); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
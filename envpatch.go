@@ -0,0 +1,160 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// envPatch is a tree of the values set by environment variables such as
+// CONFIGSET.{path}={value}, which is applied to a config set in a single pass,
+// so that loads with many environment variables take linear time. The values
+// are applied like sjson sets them one by one: missing or non-container values
+// on the way become arrays for array indexes, or objects otherwise; an index
+// beyond the end of an array pads the array with nulls, and index -1 appends to
+// the array.
+type envPatch struct {
+	value    json.RawMessage
+	keys     []string
+	children map[string]*envPatch
+}
+
+// set sets the given value for the given keys, replacing the values set for the
+// keys before.
+func (ep *envPatch) set(keys []string, value json.RawMessage) {
+	node := ep
+	for _, key := range keys {
+		child, ok := node.children[key]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[string]*envPatch)
+			}
+			child = new(envPatch)
+			node.keys = append(node.keys, key)
+			node.children[key] = child
+		}
+		node = child
+	}
+	node.value = value
+	node.keys = nil
+	node.children = nil
+}
+
+// apply returns the given config set with the patch applied.
+func (ep *envPatch) apply(rawConfigSet json.RawMessage) (json.RawMessage, error) {
+	return ep.appendPatchedJSON(make([]byte, 0, len(rawConfigSet)), "", gjson.ParseBytes(rawConfigSet))
+}
+
+func (ep *envPatch) appendPatchedJSON(buffer []byte, path string, base gjson.Result) ([]byte, error) {
+	if ep.value != nil {
+		base = gjson.ParseBytes(ep.value)
+	}
+	if len(ep.keys) == 0 {
+		return append(buffer, base.Raw...), nil
+	}
+	switch {
+	case base.IsObject():
+		return ep.appendPatchedObject(buffer, path, base)
+	case base.IsArray():
+		return ep.appendPatchedArray(buffer, path, base)
+	default:
+		if key := ep.keys[0]; key == "-1" || isArrayIndex(key) {
+			return ep.appendPatchedArray(buffer, path, gjson.Parse("[]"))
+		}
+		return ep.appendPatchedObject(buffer, path, gjson.Parse("{}"))
+	}
+}
+
+func (ep *envPatch) appendPatchedObject(buffer []byte, path string, base gjson.Result) ([]byte, error) {
+	baseKeys := make(map[string]struct{})
+	buffer = append(buffer, '{')
+	n := 0
+	var err error
+	base.ForEach(func(key, value gjson.Result) bool {
+		baseKeys[key.Str] = struct{}{}
+		if n >= 1 {
+			buffer = append(buffer, ',')
+		}
+		n++
+		buffer = append(buffer, key.Raw...)
+		buffer = append(buffer, ':')
+		child, ok := ep.children[key.Str]
+		if !ok {
+			buffer = append(buffer, value.Raw...)
+			return true
+		}
+		buffer, err = child.appendPatchedJSON(buffer, joinPath(path, key.Str), value)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range ep.keys {
+		if _, ok := baseKeys[key]; ok {
+			continue
+		}
+		if n >= 1 {
+			buffer = append(buffer, ',')
+		}
+		n++
+		rawKey, _ := json.Marshal(key)
+		buffer = append(buffer, rawKey...)
+		buffer = append(buffer, ':')
+		buffer, err = ep.children[key].appendPatchedJSON(buffer, joinPath(path, key), gjson.Result{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(buffer, '}'), nil
+}
+
+func (ep *envPatch) appendPatchedArray(buffer []byte, path string, base gjson.Result) ([]byte, error) {
+	elements := base.Array()
+	patches := make([]*envPatch, len(elements))
+	for _, key := range ep.keys {
+		var i int
+		switch {
+		case key == "-1":
+			i = len(elements)
+		case isArrayIndex(key):
+			i, _ = strconv.Atoi(key)
+		default:
+			return nil, fmt.Errorf("set json value; path=%q: cannot set array element for non-numeric key '%s'", joinPath(path, key), key)
+		}
+		for len(elements) <= i {
+			elements = append(elements, gjson.Result{Type: gjson.Null, Raw: "null"})
+			patches = append(patches, nil)
+		}
+		patches[i] = ep.children[key]
+	}
+	buffer = append(buffer, '[')
+	for i, element := range elements {
+		if i >= 1 {
+			buffer = append(buffer, ',')
+		}
+		if patches[i] == nil {
+			buffer = append(buffer, element.Raw...)
+			continue
+		}
+		var err error
+		buffer, err = patches[i].appendPatchedJSON(buffer, joinPath(path, strconv.Itoa(i)), element)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(buffer, ']'), nil
+}
+
+func isArrayIndex(key string) bool {
+	if key == "" || len(key) > 9 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if !isDigit(key[i]) {
+			return false
+		}
+	}
+	return true
+}
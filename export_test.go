@@ -1,3 +0,0 @@
-package configset
-
-type ConfigSet = configSet
@@ -0,0 +1,260 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// ErrInvalidExpression is returned when an expression within a placeholder such
+// as ${expr:{expression}} can't be evaluated.
+var ErrInvalidExpression = errors.New("configset: invalid expression")
+
+// exprEvaluator evaluates simple expressions, which consist of numbers, string
+// literals in double quotes, references to other values, the operators +, -,
+// *, / and %, and parentheses. A reference starting with a dot, e.g. .replicas,
+// is relative to the object containing the expression, otherwise it's a full
+// path, e.g. common.replicas. + concatenates strings as well as adds numbers.
+type exprEvaluator struct {
+	pr         *placeholderResolver
+	path       string
+	expression string
+	pos        int
+}
+
+// evaluateExpr evaluates the given expression within the value for the given
+// path.
+func (pr *placeholderResolver) evaluateExpr(path string, expression string) (json.RawMessage, error) {
+	ee := exprEvaluator{
+		pr:         pr,
+		path:       path,
+		expression: expression,
+	}
+	value, err := ee.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	ee.skipSpaces()
+	if ee.pos < len(ee.expression) {
+		return nil, ee.errorf("unexpected character %q", ee.expression[ee.pos])
+	}
+	switch value := value.(type) {
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, ee.errorf("result not finite")
+		}
+		return json.RawMessage(strconv.FormatFloat(value, 'f', -1, 64)), nil
+	default:
+		return json.Marshal(value)
+	}
+}
+
+func (ee *exprEvaluator) parseSum() (interface{}, error) {
+	x, err := ee.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ee.skipSpaces()
+		if ee.pos == len(ee.expression) {
+			return x, nil
+		}
+		operator := ee.expression[ee.pos]
+		if operator != '+' && operator != '-' {
+			return x, nil
+		}
+		ee.pos++
+		y, err := ee.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		if operator == '+' {
+			if s, ok := x.(string); ok {
+				if t, ok := y.(string); ok {
+					x = s + t
+					continue
+				}
+			}
+		}
+		x, err = ee.applyArithmetic(operator, x, y)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (ee *exprEvaluator) parseProduct() (interface{}, error) {
+	x, err := ee.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ee.skipSpaces()
+		if ee.pos == len(ee.expression) {
+			return x, nil
+		}
+		operator := ee.expression[ee.pos]
+		if operator != '*' && operator != '/' && operator != '%' {
+			return x, nil
+		}
+		ee.pos++
+		y, err := ee.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x, err = ee.applyArithmetic(operator, x, y)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (ee *exprEvaluator) parseUnary() (interface{}, error) {
+	ee.skipSpaces()
+	if ee.pos < len(ee.expression) && ee.expression[ee.pos] == '-' {
+		ee.pos++
+		x, err := ee.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return ee.applyArithmetic('-', 0.0, x)
+	}
+	return ee.parseOperand()
+}
+
+func (ee *exprEvaluator) parseOperand() (interface{}, error) {
+	ee.skipSpaces()
+	if ee.pos == len(ee.expression) {
+		return nil, ee.errorf("unexpected end")
+	}
+	switch c := ee.expression[ee.pos]; {
+	case c == '(':
+		ee.pos++
+		x, err := ee.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		ee.skipSpaces()
+		if ee.pos == len(ee.expression) || ee.expression[ee.pos] != ')' {
+			return nil, ee.errorf("missing ')'")
+		}
+		ee.pos++
+		return x, nil
+	case c == '"':
+		i := ee.pos + 1
+		for i < len(ee.expression) && ee.expression[i] != '"' {
+			if ee.expression[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(ee.expression) {
+			return nil, ee.errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(ee.expression[ee.pos : i+1])
+		if err != nil {
+			return nil, ee.errorf("invalid string %s", ee.expression[ee.pos:i+1])
+		}
+		ee.pos = i + 1
+		return s, nil
+	case isDigit(c):
+		i := ee.pos
+		for i < len(ee.expression) && (isDigit(ee.expression[i]) || ee.expression[i] == '.') {
+			i++
+		}
+		x, err := strconv.ParseFloat(ee.expression[ee.pos:i], 64)
+		if err != nil {
+			return nil, ee.errorf("invalid number %q", ee.expression[ee.pos:i])
+		}
+		ee.pos = i
+		return x, nil
+	case c == '.' || c == '_' || isLetter(c):
+		i := ee.pos
+		for i < len(ee.expression) && (ee.expression[i] == '.' || ee.expression[i] == '_' ||
+			isLetter(ee.expression[i]) || isDigit(ee.expression[i])) {
+			i++
+		}
+		reference := ee.expression[ee.pos:i]
+		ee.pos = i
+		return ee.readReference(reference)
+	default:
+		return nil, ee.errorf("unexpected character %q", c)
+	}
+}
+
+// readReference returns the value referenced, which must be a number or a
+// string.
+func (ee *exprEvaluator) readReference(reference string) (interface{}, error) {
+	path := reference
+	if strings.HasPrefix(reference, ".") {
+		keys := splitPath(ee.path)
+		parentPath := ""
+		for _, key := range keys[:len(keys)-1] {
+			parentPath = joinPath(parentPath, key)
+		}
+		if parentPath == "" {
+			path = reference[1:]
+		} else {
+			path = parentPath + reference
+		}
+	}
+	rawValue, err := ee.pr.resolveRef(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve reference; path=%q: %w", ee.path, err)
+	}
+	value := gjson.ParseBytes(rawValue)
+	switch value.Type {
+	case gjson.Number:
+		return value.Num, nil
+	case gjson.String:
+		return value.Str, nil
+	default:
+		return nil, ee.errorf("reference %q to %s value", reference, jsonTypeName(value))
+	}
+}
+
+func (ee *exprEvaluator) applyArithmetic(operator byte, x interface{}, y interface{}) (interface{}, error) {
+	a, ok1 := x.(float64)
+	b, ok2 := y.(float64)
+	if !ok1 || !ok2 {
+		return nil, ee.errorf("operator %q applied to non-numbers", operator)
+	}
+	switch operator {
+	case '+':
+		return a + b, nil
+	case '-':
+		return a - b, nil
+	case '*':
+		return a * b, nil
+	case '/':
+		if b == 0 {
+			return nil, ee.errorf("division by zero")
+		}
+		return a / b, nil
+	default:
+		if b == 0 {
+			return nil, ee.errorf("division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+}
+
+func (ee *exprEvaluator) skipSpaces() {
+	for ee.pos < len(ee.expression) && (ee.expression[ee.pos] == ' ' || ee.expression[ee.pos] == '\t') {
+		ee.pos++
+	}
+}
+
+func (ee *exprEvaluator) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w; path=%q expression=%q: %s", ErrInvalidExpression, ee.path, ee.expression,
+		fmt.Sprintf(format, args...))
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
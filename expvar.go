@@ -0,0 +1,22 @@
+package configset
+
+import "expvar"
+
+// PublishExpvar publishes the config set under the given name with the package
+// expvar, so that scraping /debug/vars picks up the configuration state. The
+// variable is evaluated on every read, and consists of the fingerprint, see
+// Fingerprint, and the config set redacted like DumpForLog does, e.g.
+//
+//	{"fingerprint": "...", "config": {"server": {"port": 8080}}}
+//
+// Like expvar.Publish, PublishExpvar panics if the name is already in use.
+func PublishExpvar(name string) { cs.PublishExpvar(name) }
+
+func (cs *ConfigSet) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]interface{}{
+			"fingerprint": cs.Fingerprint(),
+			"config":      cs.DumpForLog(),
+		}
+	}))
+}
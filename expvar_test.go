@@ -0,0 +1,36 @@
+package configset_test
+
+import (
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// expvarTestRuns makes the names of the variables published by tests unique
+// across runs, e.g. with -count=2, since variables can't be unpublished.
+var expvarTestRuns int32
+
+func TestConfigSet_PublishExpvar(t *testing.T) {
+	name := fmt.Sprintf("configset_test_%d", atomic.AddInt32(&expvarTestRuns, 1))
+	var cs ConfigSet
+	cs.AddSecretPaths("db.password")
+	cs.PublishExpvar(name)
+	assert.Equal(t, `{"config":null,"fingerprint":""}`, expvar.Get(name).String())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+port: 5432
+password: hunter2
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"config":{"db":{"password":"******","port":5432}},"fingerprint":"`+cs.Fingerprint()+`"}`,
+		expvar.Get(name).String())
+}
@@ -0,0 +1,33 @@
+package configset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprint returns the SHA-256 digest of the config set in the canonical form
+// of JSON, in hex, see Dump, so that services can report the exact
+// configuration they run in metrics, logs and health endpoints. Equal config
+// sets have equal fingerprints regardless of where the values come from, and
+// the fingerprint equals the digest of the current generation, see History.
+// If the config set has not been loaded, an empty string is returned.
+func Fingerprint() string { return cs.Fingerprint() }
+
+func (cs *ConfigSet) Fingerprint() string { return cs.Snapshot().Fingerprint() }
+
+// Fingerprint likes the package-level Fingerprint but returns the fingerprint of
+// the snapshot.
+func (s *Snapshot) Fingerprint() string {
+	if s.raw == nil {
+		return ""
+	}
+	if s.fingerprint != "" {
+		return s.fingerprint
+	}
+	return fingerprintJSON(s.raw)
+}
+
+func fingerprintJSON(raw []byte) string {
+	digest := sha256.Sum256(raw)
+	return hex.EncodeToString(digest[:])
+}
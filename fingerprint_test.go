@@ -0,0 +1,32 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Fingerprint(t *testing.T) {
+	var cs1, cs2 ConfigSet
+	assert.Equal(t, "", cs1.Fingerprint())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs1.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	assert.NoError(t, err)
+	err = cs2.MergeAt("", []byte(`{"server": {"host": "localhost", "port": 8080}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "e6344d1f4cbed14dc925247c664a0c51416d1e22e6352b714b4ab038b8f72501", cs1.Fingerprint())
+	assert.Equal(t, cs1.Fingerprint(), cs2.Fingerprint())
+	assert.Equal(t, cs1.History()[0].Fingerprint, cs1.Fingerprint())
+
+	err = cs2.Set("server.port", 8081)
+	assert.NoError(t, err)
+	assert.NotEqual(t, cs1.Fingerprint(), cs2.Fingerprint())
+}
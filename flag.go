@@ -0,0 +1,88 @@
+package configset
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// BindFlags registers the following flags on the given flag set, e.g.
+// flag.CommandLine, so that ad-hoc overrides don't require exporting
+// environment variables:
+//
+//	-set {path}={value}   set the value in form of YAML for the path, as the
+//	                      highest-precedence layer of loads, repeatable
+//	-config-dir {dir}     set the directory to load the config set from, like
+//	                      WithDir does
+//
+// The flags take effect on the loads after the flag set is parsed. Like WithDir,
+// -config-dir only affects Open and auto-loads, see ReadValue, whereas Load and
+// MustLoad load from the directory given. Users of
+// pflag can add the flag set with pflag.FlagSet.AddGoFlagSet.
+func BindFlags(flagSet *flag.FlagSet) { cs.BindFlags(flagSet) }
+
+func (cs *ConfigSet) BindFlags(flagSet *flag.FlagSet) {
+	flagSet.Var((*setFlag)(cs), "set", "set the value in form of YAML for `path=value` in the config set (repeatable)")
+	flagSet.Var((*configDirFlag)(cs), "config-dir", "load the config set from `dir` (with Open or auto-loads)")
+}
+
+type setFlag ConfigSet
+
+var _ flag.Value = (*setFlag)(nil)
+
+func (sf *setFlag) String() string {
+	if sf == nil {
+		return ""
+	}
+	cs := (*ConfigSet)(sf)
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	var pathValues []string
+	for _, flagPatch := range cs.options.flagPatches {
+		pathValues = append(pathValues, flagPatch.key)
+	}
+	return strings.Join(pathValues, ",")
+}
+
+func (sf *setFlag) Set(pathValue string) error {
+	i := strings.IndexByte(pathValue, '=')
+	if i < 0 {
+		return fmt.Errorf("invalid flag value; flagValue=%q: want path=value", pathValue)
+	}
+	if _, err := yaml.YAMLToJSONStrict([]byte(pathValue[i+1:])); err != nil {
+		return fmt.Errorf("invalid flag value; flagValue=%q: %w", pathValue, err)
+	}
+	cs := (*ConfigSet)(sf)
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.options.flagPatches = append(cs.options.flagPatches, valuePatch{
+		key:   pathValue[:i],
+		path:  pathValue[:i],
+		value: pathValue[i+1:],
+	})
+	return nil
+}
+
+type configDirFlag ConfigSet
+
+var _ flag.Value = (*configDirFlag)(nil)
+
+func (cdf *configDirFlag) String() string {
+	if cdf == nil {
+		return ""
+	}
+	cs := (*ConfigSet)(cdf)
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	return cs.options.dirPath
+}
+
+func (cdf *configDirFlag) Set(dirPath string) error {
+	cs := (*ConfigSet)(cdf)
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.options.dirPath = dirPath
+	return nil
+}
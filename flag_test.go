@@ -0,0 +1,49 @@
+package configset_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_BindFlags(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/overrides.yaml", []byte(`
+server: {port: 8081}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs := New(WithFS(fs))
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+	cs.BindFlags(flagSet)
+	err := flagSet.Parse([]string{"--config-dir", "/my_etc", "--set", "server.port=8082", "-set", "server.tags=[a, b]", "-set=server.tags.1=c"})
+	assert.NoError(t, err)
+	assert.Equal(t, "/my_etc", flagSet.Lookup("config-dir").Value.String())
+	assert.Equal(t, "server.port,server.tags,server.tags.1", flagSet.Lookup("set").Value.String())
+	var port int
+	err = cs.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8082, port)
+	assert.Equal(t, `{"server":{"port":8082,"tags":["a","c"]}}`, string(cs.Dump("", "")))
+	origin, err := cs.Origin("server.port")
+	assert.NoError(t, err)
+	assert.Equal(t, "flag server.port", origin.String())
+
+	err = flagSet.Parse([]string{"-set", "server.port"})
+	assert.EqualError(t, err, `invalid value "server.port" for flag -set: invalid flag value; flagValue="server.port": want path=value`)
+	err = flagSet.Parse([]string{"-set", "server.port=[8080"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value "server.port=[8080" for flag -set: invalid flag value; flagValue="server.port=[8080": `)
+	err = cs.ForceRefresh()
+	assert.NoError(t, err)
+}
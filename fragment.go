@@ -0,0 +1,152 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// sharedConfigName is the name of the reserved file _shared.yaml, in which each
+// top-level value is a fragment named by the key. Fragments are reused across
+// files by objects with the key `$use` set to the name of a fragment, or a list
+// of names, and are deep-merged beneath the other keys of the objects, e.g.
+//
+//	# _shared.yaml
+//	defaultPool:
+//	  maxOpen: 10
+//	  maxIdle: 2
+//
+//	# db.yaml
+//	pool:
+//	  $use: defaultPool
+//	  maxOpen: 20
+const sharedConfigName = "_shared"
+
+const fragmentUseKey = "$use"
+
+// ErrFragmentNotFound is returned when an object uses a fragment not defined in
+// _shared.yaml.
+var ErrFragmentNotFound = errors.New("configset: fragment not found")
+
+type fragmentExpander struct {
+	fragments map[string]gjson.Result
+	expanded  map[string]json.RawMessage
+	expanding map[string]struct{}
+}
+
+func newFragmentExpander(rawFragments json.RawMessage) *fragmentExpander {
+	return &fragmentExpander{
+		fragments: gjson.ParseBytes(rawFragments).Map(),
+		expanded:  make(map[string]json.RawMessage),
+		expanding: make(map[string]struct{}),
+	}
+}
+
+// expandConfigs expands the fragments used within the given configs of the
+// given path, e.g. a config or an object of configs.
+func (fe *fragmentExpander) expandConfigs(path string, rawConfigs json.RawMessage) (json.RawMessage, error) {
+	return fe.expandValue(path, gjson.ParseBytes(rawConfigs))
+}
+
+func (fe *fragmentExpander) expandValue(path string, value gjson.Result) (json.RawMessage, error) {
+	switch {
+	case value.IsObject():
+		buffer := []byte{'{'}
+		n := 0
+		var base json.RawMessage
+		var err error
+		value.ForEach(func(key, value gjson.Result) bool {
+			if key.Str == fragmentUseKey {
+				base, err = fe.useFragments(path, value)
+				return err == nil
+			}
+			var childValue json.RawMessage
+			childValue, err = fe.expandValue(joinPath(path, key.Str), value)
+			if err != nil {
+				return false
+			}
+			if n >= 1 {
+				buffer = append(buffer, ',')
+			}
+			n++
+			buffer = append(buffer, key.Raw...)
+			buffer = append(buffer, ':')
+			buffer = append(buffer, childValue...)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, '}')
+		if base != nil {
+			return mergeJSON(base, buffer), nil
+		}
+		return buffer, nil
+	case value.IsArray():
+		buffer := []byte{'['}
+		var err error
+		for i, element := range value.Array() {
+			var elementValue json.RawMessage
+			elementValue, err = fe.expandValue(joinPath(path, fmt.Sprint(i)), element)
+			if err != nil {
+				return nil, err
+			}
+			if i >= 1 {
+				buffer = append(buffer, ',')
+			}
+			buffer = append(buffer, elementValue...)
+		}
+		return append(buffer, ']'), nil
+	default:
+		return json.RawMessage(value.Raw), nil
+	}
+}
+
+// useFragments returns the merged fragments with the given names.
+func (fe *fragmentExpander) useFragments(path string, names gjson.Result) (json.RawMessage, error) {
+	var nameList []gjson.Result
+	if names.IsArray() {
+		nameList = names.Array()
+	} else {
+		nameList = []gjson.Result{names}
+	}
+	var merged json.RawMessage
+	for _, name := range nameList {
+		if name.Type != gjson.String {
+			return nil, fmt.Errorf("configset: invalid fragment name; path=%q name=%s", path, name.Raw)
+		}
+		fragment, err := fe.expandFragment(name.Str)
+		if err != nil {
+			return nil, fmt.Errorf("use fragment; path=%q: %w", path, err)
+		}
+		if merged == nil {
+			merged = fragment
+		} else {
+			merged = mergeJSON(merged, fragment)
+		}
+	}
+	return merged, nil
+}
+
+func (fe *fragmentExpander) expandFragment(name string) (json.RawMessage, error) {
+	if fragment, ok := fe.expanded[name]; ok {
+		return fragment, nil
+	}
+	if _, ok := fe.expanding[name]; ok {
+		return nil, fmt.Errorf("%w; name=%q", ErrReferenceCycle, name)
+	}
+	value, ok := fe.fragments[name]
+	if !ok {
+		return nil, fmt.Errorf("%w; name=%q", ErrFragmentNotFound, name)
+	}
+	fe.expanding[name] = struct{}{}
+	fragment, err := fe.expandValue(joinPath(sharedConfigName, name), value)
+	delete(fe.expanding, name)
+	if err != nil {
+		return nil, err
+	}
+	fe.expanded[name] = fragment
+	return fragment, nil
+}
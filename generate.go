@@ -0,0 +1,214 @@
+package configset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/yaml"
+)
+
+// GenerateExample generates annotated example configuration files, from the
+// registered default values and types, into the given directory, so that ops
+// can scaffold a config directory for a new deployment. Each value is annotated
+// with its path and type, and values of struct fields with `deprecated` struct
+// tags are annotated with deprecations, e.g.
+//
+//	Listen string `json:"listen" default:":8080"`
+//	Port   int    `json:"port" deprecated:"use listen instead"`
+func GenerateExample(fs afero.Fs, dirPath string) error { return cs.GenerateExample(fs, dirPath) }
+
+func (cs *ConfigSet) GenerateExample(fs afero.Fs, dirPath string) error {
+	files, err := cs.generateExample()
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("make dir; dirPath=%q: %w", dirPath, err)
+	}
+	fileNames := make([]string, 0, len(files))
+	for fileName := range files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(dirPath, fileName)
+		if err := afero.WriteFile(fs, filePath, files[fileName], 0644); err != nil {
+			return fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+		}
+	}
+	return nil
+}
+
+type exampleNode struct {
+	path        string
+	key         string
+	typeName    string
+	deprecation string
+	rawValue    json.RawMessage
+	children    []*exampleNode
+}
+
+func (cs *ConfigSet) generateExample() (map[string][]byte, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	root := exampleNode{}
+	if cs.defaults != nil {
+		root.addValue(gjson.ParseBytes(cs.defaults))
+	}
+	for _, typeRegistration := range cs.types {
+		node := &root
+		for _, key := range splitPath(typeRegistration.path) {
+			node = node.child(key)
+		}
+		if err := node.addType(typeRegistration.configType); err != nil {
+			return nil, fmt.Errorf("add type; path=%q configType=%q: %w", typeRegistration.path, typeRegistration.configType, err)
+		}
+	}
+	files := make(map[string][]byte, len(root.children))
+	for _, node := range root.children {
+		var buffer bytes.Buffer
+		if len(node.children) == 0 {
+			node.writeComments(&buffer, "")
+			writeExampleValue(&buffer, node.value())
+		} else {
+			for _, child := range node.children {
+				child.write(&buffer, "")
+			}
+		}
+		files[node.key+".yaml"] = buffer.Bytes()
+	}
+	return files, nil
+}
+
+func (n *exampleNode) child(key string) *exampleNode {
+	for _, child := range n.children {
+		if child.key == key {
+			return child
+		}
+	}
+	child := &exampleNode{
+		path: joinPath(n.path, key),
+		key:  key,
+	}
+	n.children = append(n.children, child)
+	return child
+}
+
+func (n *exampleNode) addValue(value gjson.Result) {
+	if !value.IsObject() || len(value.Map()) == 0 {
+		n.rawValue = json.RawMessage(value.Raw)
+		return
+	}
+	value.ForEach(func(key, value gjson.Result) bool {
+		n.child(key.Str).addValue(value)
+		return true
+	})
+}
+
+func (n *exampleNode) addType(typ reflect.Type) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if n.typeName == "" {
+		n.typeName = typ.String()
+	}
+	if typ.Kind() != reflect.Struct || typ.Implements(jsonUnmarshalerType) || reflect.PtrTo(typ).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		fieldName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		if fieldName == "" {
+			if err := n.addType(field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+		child := n.child(fieldName)
+		child.deprecation = field.Tag.Get("deprecated")
+		if defaultValue, ok := field.Tag.Lookup("default"); ok && child.rawValue == nil && len(child.children) == 0 {
+			value := reflect.New(field.Type)
+			if err := setDefaultValue(value.Elem(), defaultValue); err != nil {
+				return fmt.Errorf("set default value; fieldName=%q defaultValue=%q: %w", field.Name, defaultValue, err)
+			}
+			data, err := json.Marshal(value.Interface())
+			if err != nil {
+				return fmt.Errorf("marshal to json; fieldName=%q: %w", field.Name, err)
+			}
+			child.rawValue = data
+		}
+		if err := child.addType(field.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func (n *exampleNode) value() json.RawMessage {
+	if n.rawValue != nil {
+		return n.rawValue
+	}
+	switch {
+	case strings.HasPrefix(n.typeName, "[]"):
+		return json.RawMessage("[]")
+	case strings.HasPrefix(n.typeName, "map["):
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage("null")
+}
+
+func (n *exampleNode) write(buffer *bytes.Buffer, indention string) {
+	n.writeComments(buffer, indention)
+	buffer.WriteString(indention)
+	writeExampleKey(buffer, n.key)
+	buffer.WriteByte(':')
+	if len(n.children) == 0 {
+		buffer.WriteByte(' ')
+		writeExampleValue(buffer, n.value())
+		return
+	}
+	buffer.WriteByte('\n')
+	for _, child := range n.children {
+		child.write(buffer, indention+"  ")
+	}
+}
+
+func (n *exampleNode) writeComments(buffer *bytes.Buffer, indention string) {
+	buffer.WriteString(indention)
+	buffer.WriteString("# ")
+	buffer.WriteString(n.path)
+	typeName := n.typeName
+	if typeName == "" {
+		typeName = jsonTypeName(gjson.ParseBytes(n.value()))
+	}
+	fmt.Fprintf(buffer, " (%s)\n", typeName)
+	if n.deprecation != "" {
+		fmt.Fprintf(buffer, "%s# DEPRECATED: %s\n", indention, n.deprecation)
+	}
+}
+
+func writeExampleKey(buffer *bytes.Buffer, key string) {
+	data, _ := yaml.Marshal(key)
+	buffer.Write(bytes.TrimSuffix(data, []byte("\n")))
+}
+
+func writeExampleValue(buffer *bytes.Buffer, value json.RawMessage) {
+	// JSON values are valid YAML values in flow style.
+	buffer.Write(value)
+	buffer.WriteByte('\n')
+}
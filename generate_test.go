@@ -0,0 +1,68 @@
+package configset_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_GenerateExample(t *testing.T) {
+	type TLS struct {
+		Enabled  bool   `json:"enabled"`
+		CertFile string `json:"cert_file" default:"server.crt"`
+	}
+	type Server struct {
+		Listen  string        `json:"listen" default:":8080"`
+		Port    int           `json:"port" deprecated:"use listen instead"`
+		Timeout time.Duration `json:"timeout"`
+		Tags    []string      `json:"tags"`
+		TLS     TLS           `json:"tls"`
+	}
+	var cs ConfigSet
+	if err := cs.RegisterDefaultYAML("server", []byte(`
+timeout: 30000000000
+tls:
+  enabled: true
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.SetDefault("log.level", "info"); err != nil {
+		t.Fatal(err)
+	}
+	cs.RegisterType("server", Server{})
+	fs := afero.NewMemMapFs()
+
+	err := cs.GenerateExample(fs, "/my_etc")
+	assert.NoError(t, err)
+	data, err := afero.ReadFile(fs, "/my_etc/server.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `# server.timeout (time.Duration)
+timeout: 30000000000
+# server.tls (configset_test.TLS)
+tls:
+  # server.tls.enabled (bool)
+  enabled: true
+  # server.tls.cert_file (string)
+  cert_file: "server.crt"
+# server.listen (string)
+listen: ":8080"
+# server.port (int)
+# DEPRECATED: use listen instead
+port: null
+# server.tags ([]string)
+tags: []
+`, string(data))
+	data, err = afero.ReadFile(fs, "/my_etc/log.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `# log.level (string)
+level: "info"
+`, string(data))
+
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	report := cs.Vet(fs, "/my_etc", nil)
+	assert.True(t, report.OK())
+}
@@ -0,0 +1,126 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// Handler returns an HTTP handler serving the state of the config set in form
+// of JSON, ready to be mounted at e.g. /debug/config, which consists of the
+// status, see Status, the config set redacted like DumpRedacted does, and the
+// effective origins of the leaf values, see Explain, e.g.
+//
+//	{
+//	  "status": {"loaded": true, "lastLoadTime": "...", "generation": 1, "fingerprint": "...", "sources": []},
+//	  "config": {"server": {"port": 8081}},
+//	  "provenance": {"server.port": "env CONFIGSET.server.port"}
+//	}
+//
+// The query parameter `path` limits the config and the provenance to the value
+// for the path. If the option WithHandlerReload is set, POST requests reload the
+// config set, see ForceRefresh, and are responded with the status.
+func Handler() http.Handler { return cs.Handler() }
+
+// WithHandlerReload returns an option that makes the handler returned by
+// Handler accept POST requests to reload the config set.
+func WithHandlerReload() Option {
+	return func(options *options) { options.handlerReload = true }
+}
+
+type handlerResponse struct {
+	Status     *handlerStatus    `json:"status,omitempty"`
+	Config     json.RawMessage   `json:"config,omitempty"`
+	Provenance map[string]string `json:"provenance,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type handlerStatus struct {
+	Loaded       bool       `json:"loaded"`
+	LastLoadTime *time.Time `json:"lastLoadTime,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	Generation   int        `json:"generation"`
+	Fingerprint  string     `json:"fingerprint"`
+	Sources      []string   `json:"sources"`
+}
+
+func (cs *ConfigSet) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			cs.serveState(w, r.URL.Query().Get("path"))
+		case http.MethodPost:
+			cs.mutex.Lock()
+			handlerReload := cs.options.handlerReload
+			cs.mutex.Unlock()
+			if !handlerReload {
+				writeHandlerResponse(w, http.StatusMethodNotAllowed, handlerResponse{Error: "reload not allowed"})
+				return
+			}
+			if err := cs.ForceRefresh(); err != nil {
+				writeHandlerResponse(w, http.StatusInternalServerError, handlerResponse{Status: cs.handlerStatus(), Error: err.Error()})
+				return
+			}
+			writeHandlerResponse(w, http.StatusOK, handlerResponse{Status: cs.handlerStatus()})
+		default:
+			writeHandlerResponse(w, http.StatusMethodNotAllowed, handlerResponse{Error: "method not allowed"})
+		}
+	})
+}
+
+func (cs *ConfigSet) serveState(w http.ResponseWriter, path string) {
+	response := handlerResponse{Status: cs.handlerStatus()}
+	if raw := cs.redactedRaw(); raw != nil {
+		if path == "" {
+			response.Config = raw
+		} else {
+			value := gjson.GetBytes(raw, path)
+			if !value.Exists() {
+				writeHandlerResponse(w, http.StatusNotFound, handlerResponse{Error: ErrValueNotFound.Error()})
+				return
+			}
+			response.Config = json.RawMessage(value.Raw)
+		}
+		explanations, err := cs.Explain(path)
+		if err != nil && !errors.Is(err, ErrValueNotFound) {
+			writeHandlerResponse(w, http.StatusInternalServerError, handlerResponse{Error: err.Error()})
+			return
+		}
+		response.Provenance = make(map[string]string, len(explanations))
+		for _, explanation := range explanations {
+			response.Provenance[explanation.Path] = explanation.Origins[0].String()
+		}
+	}
+	writeHandlerResponse(w, http.StatusOK, response)
+}
+
+func (cs *ConfigSet) handlerStatus() *handlerStatus {
+	statusReport := cs.Status()
+	handlerStatus := handlerStatus{
+		Loaded:      statusReport.Loaded(),
+		Generation:  statusReport.Generation,
+		Fingerprint: statusReport.Fingerprint,
+		Sources:     statusReport.Sources,
+	}
+	if !statusReport.LastLoadTime.IsZero() {
+		handlerStatus.LastLoadTime = &statusReport.LastLoadTime
+	}
+	if statusReport.LastErr != nil {
+		handlerStatus.LastError = statusReport.LastErr.Error()
+	}
+	if handlerStatus.Sources == nil {
+		handlerStatus.Sources = []string{}
+	}
+	return &handlerStatus
+}
+
+func writeHandlerResponse(w http.ResponseWriter, statusCode int, response handlerResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(response)
+}
@@ -0,0 +1,85 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Handler(t *testing.T) {
+	var cs ConfigSet
+	recorder := httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/config", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status": {"loaded": false, "generation": 0, "fingerprint": "", "sources": []}}`, recorder.Body.String())
+
+	cs.AddSecretPaths("db.password")
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: hunter2
+port: 5432
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.port=5433"})
+	assert.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/config", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	var response struct {
+		Status     map[string]interface{} `json:"status"`
+		Config     json.RawMessage        `json:"config"`
+		Provenance map[string]string      `json:"provenance"`
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, true, response.Status["loaded"])
+	assert.Equal(t, cs.Fingerprint(), response.Status["fingerprint"])
+	assert.NotEmpty(t, response.Status["lastLoadTime"])
+	assert.JSONEq(t, `{"db": {"password": "******", "port": 5433}}`, string(response.Config))
+	assert.Equal(t, map[string]string{
+		"db.password": "file /my_etc/db.yaml",
+		"db.port":     "env CONFIGSET.db.port",
+	}, response.Provenance)
+
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/config?path=db.port", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	response.Provenance = nil
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "5433", string(response.Config))
+	assert.Equal(t, map[string]string{"db.port": "env CONFIGSET.db.port"}, response.Provenance)
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/config?path=db.host", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("POST", "/debug/config", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	cs.Configure(WithHandlerReload())
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+port: [5432
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("POST", "/debug/config", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"lastError": "convert yaml to json`)
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+port: 5432
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recorder = httptest.NewRecorder()
+	cs.Handler().ServeHTTP(recorder, httptest.NewRequest("POST", "/debug/config", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"generation": 2`)
+}
@@ -0,0 +1,155 @@
+package configset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// History returns the generations of the config set kept, from the newest to
+// the oldest, with the current generation first. Besides loads, programmatic
+// changes, i.e. Set, Delete, MergeAt, Update, Rollback and secret rotations,
+// create generations too, which carry the source digest of the files loaded
+// last.
+func History() []Generation { return cs.History() }
+
+// Rollback reverts the config set to the generation n generations before the
+// current one, e.g. 1 for the previous generation, and notifies subscribers of
+// changed values, so a bad config push can be reverted without redeploying
+// files. The overrides, including the file overrides.yaml if persisted, the
+// provenance and the secrets of the config set are reverted along with the
+// values. The reverted config set is recorded as a new generation. Rollback does
+// not pin the config set, the next reload, e.g. triggered by Watch or Poll,
+// loads the configuration again.
+// If there is no such generation, ErrGenerationNotFound is returned.
+func Rollback(n int) error { return cs.Rollback(n) }
+
+// WithHistorySize returns an option that sets the number of generations of the
+// config set kept for History and Rollback. The default size is 10.
+func WithHistorySize(historySize int) Option {
+	return func(options *options) { options.historySize = historySize }
+}
+
+const defaultHistorySize = 10
+
+// Generation represents a version of the config set.
+type Generation struct {
+	// Number is the sequence number of the generation, starting from 1.
+	Number int
+
+	// LoadedAt is the time the generation was created at.
+	LoadedAt time.Time
+
+	// Digest is the aggregate digest of the configuration files the generation
+	// has been loaded from, see Digest, so that a generation can be tied to a
+	// push of files. It is empty if no files have been loaded.
+	Digest string
+
+	// Fingerprint is the fingerprint of the config set of the generation, see
+	// Fingerprint.
+	Fingerprint string
+
+	raw          json.RawMessage
+	provenance   *provenanceNode
+	overrides    json.RawMessage
+	secretValues []secretValue
+	secretRefs   []secretRef
+}
+
+// Dump likes the package-level Dump but dumps the config set of the
+// generation.
+func (g *Generation) Dump(prefix string, indention string) json.RawMessage {
+	return (&Snapshot{raw: g.raw}).Dump(prefix, indention)
+}
+
+// ErrGenerationNotFound is returned when the generation to roll back to does
+// not exist.
+var ErrGenerationNotFound = errors.New("configset: generation not found")
+
+func (cs *ConfigSet) History() []Generation {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	history := make([]Generation, len(cs.history))
+	for i := range cs.history {
+		history[i] = cs.history[len(cs.history)-1-i]
+	}
+	return history
+}
+
+func (cs *ConfigSet) Rollback(n int) error {
+	cs.mutex.Lock()
+	i := len(cs.history) - 1 - n
+	if n < 1 || i < 0 {
+		cs.mutex.Unlock()
+		return fmt.Errorf("%w; n=%d", ErrGenerationNotFound, n)
+	}
+	generation := cs.history[i]
+	if cs.options.persistentOverrides && cs.input.fs != nil && !bytes.Equal(generation.overrides, cs.overrides) {
+		if err := cs.writeOverrides(generation.overrides); err != nil {
+			cs.mutex.Unlock()
+			return err
+		}
+	}
+	oldSnapshot := cs.Snapshot()
+	cs.provenance = generation.provenance
+	cs.overrides = generation.overrides
+	cs.digest = generation.Digest
+	cs.secretValues = generation.secretValues
+	cs.secretRefs = append([]secretRef(nil), generation.secretRefs...)
+	cs.storeSnapshot(generation.raw)
+	newSnapshot := cs.Snapshot()
+	auditSink := cs.options.auditSink
+	cs.mutex.Unlock()
+	cs.subscriptions.Notify(oldSnapshot.raw, newSnapshot.raw)
+	if auditSink != nil {
+		auditSink(newAuditEvent(AuditRollback, "", "", oldSnapshot.raw, newSnapshot.raw))
+	}
+	return nil
+}
+
+// storeSnapshot makes the given config set current and records it as a new
+// generation.
+func (cs *ConfigSet) storeSnapshot(raw json.RawMessage) {
+	raw = canonicalizeJSON(raw)
+	fingerprint := fingerprintJSON(raw)
+	snapshot := Snapshot{
+		raw:         raw,
+		fingerprint: fingerprint,
+		decodeMode:  cs.options.decodeMode,
+		jsonCodec:   cs.jsonCodec(),
+		index:       new(valueIndex),
+		loaded:      cs.loaded,
+	}
+	if cs.options.decodeCache {
+		snapshot.decodeCache = new(decodeCache)
+	}
+	if cs.options.secretsOnDemand {
+		snapshot.secretProviders = make(map[string]SecretProvider, len(cs.secretProviders))
+		for name, secretProvider := range cs.secretProviders {
+			snapshot.secretProviders[name] = secretProvider
+		}
+	}
+	cs.snapshot.Store(&snapshot)
+	historySize := cs.options.historySize
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	cs.generationCount++
+	cs.history = append(cs.history, Generation{
+		Number:       cs.generationCount,
+		LoadedAt:     time.Now(),
+		Digest:       cs.digest,
+		Fingerprint:  fingerprint,
+		raw:          raw,
+		provenance:   cs.provenance,
+		overrides:    cs.overrides,
+		secretValues: cs.secretValues,
+		// Secret references are updated in place on rotation.
+		secretRefs: append([]secretRef(nil), cs.secretRefs...),
+	})
+	if n := len(cs.history) - historySize; n >= 1 {
+		cs.history = append(cs.history[:0:0], cs.history[n:]...)
+	}
+}
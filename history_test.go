@@ -0,0 +1,110 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Rollback(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithHistorySize(2))
+	err := cs.Rollback(1)
+	assert.ErrorIs(t, err, ErrGenerationNotFound)
+
+	fs := afero.NewMemMapFs()
+	for i := 0; i < 3; i++ {
+		if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(fmt.Sprintf(`
+# version %d
+port: 8080
+`, i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err = cs.Load(fs, "/my_etc", []string{fmt.Sprintf("CONFIGSET.server.port=%d", 8081+i)})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	history := cs.History()
+	if !assert.Len(t, history, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, history[0].Number)
+	assert.Equal(t, `{"server":{"port":8083}}`, string(history[0].Dump("", "")))
+	assert.Equal(t, cs.Digest(), history[0].Digest)
+	assert.Equal(t, cs.Fingerprint(), history[0].Fingerprint)
+	assert.Equal(t, 2, history[1].Number)
+	assert.NotEqual(t, history[0].Digest, history[1].Digest)
+	assert.Equal(t, `{"server":{"port":8082}}`, string(history[1].Dump("", "")))
+	assert.False(t, history[1].LoadedAt.After(history[0].LoadedAt))
+
+	var newPort json.RawMessage
+	cs.Subscribe("server.port", func(_, newValue json.RawMessage) { newPort = newValue })
+	err = cs.Rollback(2)
+	assert.ErrorIs(t, err, ErrGenerationNotFound)
+	err = cs.Rollback(1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8082}}`, string(cs.Dump("", "")))
+	assert.Equal(t, `8082`, string(newPort))
+	digest, fingerprint := history[1].Digest, history[1].Fingerprint
+	history = cs.History()
+	assert.Equal(t, 4, history[0].Number)
+	assert.Equal(t, digest, history[0].Digest)
+	assert.Equal(t, fingerprint, history[0].Fingerprint)
+	assert.Equal(t, digest, cs.Digest())
+
+	err = cs.Set("server.port", 9090)
+	assert.NoError(t, err)
+	history = cs.History()
+	assert.Equal(t, 5, history[0].Number)
+	assert.Equal(t, digest, history[0].Digest)
+	assert.Equal(t, cs.Fingerprint(), history[0].Fingerprint)
+}
+
+func TestConfigSet_Rollback_overrides(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithPersistentOverrides())
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Set("server.port", 8081)
+	assert.NoError(t, err)
+	origin, err := cs.Origin("server.port")
+	assert.NoError(t, err)
+	assert.Equal(t, OriginUpdate, origin.Layer)
+	exists, err := afero.Exists(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	err = cs.Rollback(1)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+	origin, err = cs.Origin("server.port")
+	assert.NoError(t, err)
+	assert.Equal(t, OriginFile, origin.Layer)
+	exists, err = afero.Exists(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+
+	err = cs.Rollback(2)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8081}}`, string(cs.Dump("", "")))
+	data, err := afero.ReadFile(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "server:\n  port: 8081\n", string(data))
+}
@@ -0,0 +1,26 @@
+package configset
+
+import "context"
+
+// WithBeforeLoadHook returns an option that sets the hook called before every
+// load of the config set, including reloads, e.g. to fetch files the config set
+// depends on. If the hook returns an error, the load is aborted and fails with
+// the error.
+func WithBeforeLoadHook(beforeLoadHook func(ctx context.Context) error) Option {
+	return func(options *options) { options.beforeLoadHook = beforeLoadHook }
+}
+
+// WithAfterLoadHook returns an option that sets the hook called after every
+// successful load of the config set, including reloads, with the snapshot
+// loaded, e.g. to warm caches.
+func WithAfterLoadHook(afterLoadHook func(snapshot *Snapshot)) Option {
+	return func(options *options) { options.afterLoadHook = afterLoadHook }
+}
+
+// WithAfterReloadHook returns an option that sets the hook called after every
+// successful reload, i.e. load of an already loaded config set, with the old
+// and new snapshots, e.g. to notify other components. Unlike subscribers, see
+// Subscribe, the hook is called even if the config set stays unchanged.
+func WithAfterReloadHook(afterReloadHook func(oldSnapshot, newSnapshot *Snapshot)) Option {
+	return func(options *options) { options.afterReloadHook = afterReloadHook }
+}
@@ -0,0 +1,53 @@
+package configset_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Hooks(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var events []string
+	var beforeLoadErr error
+	var cs ConfigSet
+	cs.Configure(
+		WithBeforeLoadHook(func(context.Context) error {
+			events = append(events, "beforeLoad")
+			return beforeLoadErr
+		}),
+		WithAfterLoadHook(func(snapshot *Snapshot) {
+			events = append(events, "afterLoad "+string(snapshot.Dump("", "")))
+		}),
+		WithAfterReloadHook(func(oldSnapshot, newSnapshot *Snapshot) {
+			events = append(events, "afterReload "+string(oldSnapshot.Dump("", ""))+" "+string(newSnapshot.Dump("", "")))
+		}),
+	)
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"beforeLoad",
+		`afterLoad {"server":{"port":8080}}`,
+		"beforeLoad",
+		`afterLoad {"server":{"port":8081}}`,
+		`afterReload {"server":{"port":8080}} {"server":{"port":8081}}`,
+	}, events)
+
+	events = nil
+	beforeLoadErr = errors.New("something wrong")
+	err = cs.ForceRefresh()
+	assert.EqualError(t, err, "reload config set: run before-load hook: something wrong")
+	assert.Equal(t, []string{"beforeLoad"}, events)
+	assert.ErrorIs(t, cs.Status().LastErr, beforeLoadErr)
+}
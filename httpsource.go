@@ -0,0 +1,87 @@
+package configset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// HTTPSource is a source fetching configs in form of YAML or JSON from a URL.
+// Conditional requests are made with the ETag and Last-Modified headers of the
+// last response, so unchanged configs are not transferred again.
+type HTTPSource struct {
+	url    string
+	client *http.Client
+
+	mutex        sync.Mutex
+	etag         string
+	lastModified string
+	rawConfigs   json.RawMessage
+}
+
+var _ Source = (*HTTPSource)(nil)
+
+// NewHTTPSource creates a HTTP source for the given URL with the given client.
+// If the client is nil, http.DefaultClient is used.
+func NewHTTPSource(url string, client *http.Client) *HTTPSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSource{
+		url:    url,
+		client: client,
+	}
+}
+
+// String returns the URL of the source, which describes the source, see Status.
+func (hs *HTTPSource) String() string { return hs.url }
+
+// Fetch implements Source.Fetch.
+func (hs *HTTPSource) Fetch(ctx context.Context) (json.RawMessage, error) {
+	hs.mutex.Lock()
+	defer hs.mutex.Unlock()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, hs.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request; url=%q: %w", hs.url, err)
+	}
+	if hs.rawConfigs != nil {
+		if hs.etag != "" {
+			request.Header.Set("If-None-Match", hs.etag)
+		}
+		if hs.lastModified != "" {
+			request.Header.Set("If-Modified-Since", hs.lastModified)
+		}
+	}
+	response, err := hs.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("do request; url=%q: %w", hs.url, err)
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if hs.rawConfigs != nil {
+			return hs.rawConfigs, nil
+		}
+		fallthrough
+	default:
+		return nil, fmt.Errorf("unexpected status; url=%q statusCode=%d", hs.url, response.StatusCode)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response body; url=%q: %w", hs.url, err)
+	}
+	rawConfigs, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		return nil, fmt.Errorf("convert yaml to json; url=%q: %w", hs.url, err)
+	}
+	hs.etag = response.Header.Get("ETag")
+	hs.lastModified = response.Header.Get("Last-Modified")
+	hs.rawConfigs = rawConfigs
+	return rawConfigs, nil
+}
@@ -0,0 +1,48 @@
+package configset
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// valueIndex indexes the values of a config set by path, so that reads don't
+// scan the config set in form of JSON over and over again. The index is built
+// on the first read, and is dropped along with the snapshot on the next load or
+// update.
+type valueIndex struct {
+	once   sync.Once
+	values map[string]string
+}
+
+// getValue returns the value for the given path in form of JSON, or an empty
+// string if the value can't be found. Paths beyond plain keys and array indexes,
+// e.g. queries, fall back to gjson.
+func (vi *valueIndex) getValue(raw []byte, path string) string {
+	vi.once.Do(func() {
+		vi.values = make(map[string]string)
+		indexValues(vi.values, "", gjson.Parse(string(raw)))
+	})
+	if value, ok := vi.values[path]; ok {
+		return value
+	}
+	return gjson.GetBytes(raw, path).Raw
+}
+
+func indexValues(values map[string]string, path string, value gjson.Result) {
+	if path != "" {
+		values[path] = value.Raw
+	}
+	if !value.IsObject() && !value.IsArray() {
+		return
+	}
+	value.ForEach(func(key, value gjson.Result) bool {
+		if key.Type == gjson.Number {
+			indexValues(values, joinPath(path, strconv.Itoa(int(key.Num))), value)
+		} else {
+			indexValues(values, joinPath(path, key.Str), value)
+		}
+		return true
+	})
+}
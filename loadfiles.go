@@ -0,0 +1,31 @@
+package configset
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/spf13/afero"
+)
+
+// LoadFiles likes Load but loads the config set from the given files in memory,
+// whose keys are file paths relative to the directory, e.g. "server.yaml" or
+// "_defaults/server.yaml", and values are file contents, which exercises the full
+// pipeline of loads without touching the file system, e.g. for fuzzing
+// configuration handling against hostile YAML.
+func LoadFiles(files map[string][]byte) error { return cs.LoadFiles(files, cs.environment()) }
+
+const memDirPath = "/configset"
+
+func (cs *ConfigSet) LoadFiles(files map[string][]byte, environment []string) error {
+	fs := afero.NewMemMapFs()
+	if err := fs.MkdirAll(memDirPath, 0755); err != nil {
+		return fmt.Errorf("create directory; dirPath=%q: %w", memDirPath, err)
+	}
+	for filePath, data := range files {
+		filePath = path.Join(memDirPath, path.Clean("/"+filePath))
+		if err := afero.WriteFile(fs, filePath, data, 0644); err != nil {
+			return fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+		}
+	}
+	return cs.Load(fs, memDirPath, environment)
+}
@@ -0,0 +1,31 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_LoadFiles(t *testing.T) {
+	var cs ConfigSet
+	err := cs.LoadFiles(map[string][]byte{
+		"server.yaml":           []byte("port: 8080\n"),
+		"_defaults/server.yaml": []byte("timeout: 30\n"),
+		"../client.yaml":        []byte("timeout: 10\n"),
+	}, []string{"CONFIGSET.server.host=localhost"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"client":{"timeout":10},"server":{"host":"localhost","port":8080,"timeout":30}}`, string(cs.Dump("", "")))
+
+	for _, data := range []string{
+		"a: &a [*a]",
+		"{",
+		"a: b: c",
+		"a: !!binary 0",
+		"\x00",
+	} {
+		var cs ConfigSet
+		err := cs.LoadFiles(map[string][]byte{"server.yaml": []byte(data)}, nil)
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,137 @@
+package configset
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// DumpForLog returns the config set in form of a map, e.g. to be attached to a
+// structured startup log entry, with the values for the paths marked by
+// AddSecretPaths and the values decrypted or resolved from secret references at
+// load time masked, and strings longer than the limit set by WithLogValueLimit
+// truncated. Numbers are in form of json.Number.
+func DumpForLog() map[string]interface{} { return cs.DumpForLog() }
+
+// WithLogValueLimit returns an option that sets the maximum size in bytes of
+// strings in dumps returned by DumpForLog. The default limit is 256.
+func WithLogValueLimit(logValueLimit int) Option {
+	return func(options *options) { options.logValueLimit = logValueLimit }
+}
+
+const (
+	defaultLogValueLimit = 256
+	maskedLogValue       = "******"
+	truncatedLogSuffix   = "...(truncated)"
+)
+
+// DumpRedacted likes Dump but with the values for the paths marked by
+// AddSecretPaths and the values decrypted or resolved from secret references at
+// load time masked, like DumpForLog does, e.g. for golden files checked into
+// version control.
+func DumpRedacted(prefix string, indention string) json.RawMessage {
+	return cs.DumpRedacted(prefix, indention)
+}
+
+func (cs *ConfigSet) DumpRedacted(prefix string, indention string) json.RawMessage {
+	raw := cs.redactedRaw()
+	if raw == nil {
+		return nil
+	}
+	return (&Snapshot{raw: raw}).Dump(prefix, indention)
+}
+
+func (cs *ConfigSet) DumpForLog() map[string]interface{} {
+	raw := cs.redactedRaw()
+	cs.mutex.Lock()
+	logValueLimit := cs.options.logValueLimit
+	cs.mutex.Unlock()
+	if raw == nil {
+		return nil
+	}
+	if logValueLimit <= 0 {
+		logValueLimit = defaultLogValueLimit
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var dump map[string]interface{}
+	if err := decoder.Decode(&dump); err != nil {
+		return nil
+	}
+	truncateLogValues(dump, logValueLimit)
+	return dump
+}
+
+// redactedRaw returns the config set in form of JSON with the values of secrets
+// masked, or nil if the config set has not been loaded.
+func (cs *ConfigSet) redactedRaw() json.RawMessage {
+	cs.mutex.Lock()
+	raw := cs.Snapshot().raw
+	maskedPaths := cs.maskedPaths()
+	cs.mutex.Unlock()
+	return maskSecrets("", raw, maskedPaths)
+}
+
+// maskedPaths returns the paths of the values to mask, i.e. the ones marked by
+// AddSecretPaths and the ones of the values decrypted or resolved from secret
+// references at load time.
+func (cs *ConfigSet) maskedPaths() []string {
+	maskedPaths := append([]string(nil), cs.secretPaths...)
+	for _, secretValue := range cs.allSecretValues() {
+		maskedPaths = append(maskedPaths, secretValue.path)
+	}
+	return maskedPaths
+}
+
+// maskSecrets returns the given value for the given path with the values for the
+// given masked paths masked. If the path is one of the masked paths or under
+// one, the value is masked as a whole.
+func maskSecrets(path string, value json.RawMessage, maskedPaths []string) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	for _, maskedPath := range maskedPaths {
+		if path != "" {
+			if maskedPath == path || strings.HasPrefix(path, maskedPath+".") {
+				return json.RawMessage(`"` + maskedLogValue + `"`)
+			}
+			if !strings.HasPrefix(maskedPath, path+".") {
+				continue
+			}
+			maskedPath = maskedPath[len(path)+1:]
+		}
+		if !gjson.GetBytes(value, maskedPath).Exists() {
+			continue
+		}
+		// sjson.SetBytes doesn't modify the given JSON in place.
+		value, _ = sjson.SetBytes(value, maskedPath, maskedLogValue)
+	}
+	return value
+}
+
+func truncateLogValues(value interface{}, logValueLimit int) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, childValue := range value {
+			value[key] = truncateLogValues(childValue, logValueLimit)
+		}
+	case []interface{}:
+		for i, element := range value {
+			value[i] = truncateLogValues(element, logValueLimit)
+		}
+	case string:
+		if len(value) <= logValueLimit {
+			return value
+		}
+		n := logValueLimit
+		for n >= 1 && !utf8.RuneStart(value[n]) {
+			n--
+		}
+		return value[:n] + truncatedLogSuffix
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_DumpForLog(t *testing.T) {
+	var cs ConfigSet
+	assert.Nil(t, cs.DumpForLog())
+
+	cs.Configure(WithLogValueLimit(8))
+	cs.AddSecretPaths("db.password", "db.nonexistent")
+	cs.RegisterSecretProvider("vault", mapSecretProvider{"db/token": "s3cr3t"})
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: hunter2
+token: secretref://vault/db/token
+port: 5432
+dsn: postgres://localhost/db
+tags: [short, 日本語日本語]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"db": map[string]interface{}{
+			"password": "******",
+			"token":    "******",
+			"port":     json.Number("5432"),
+			"dsn":      "postgres...(truncated)",
+			"tags":     []interface{}{"short", "日本...(truncated)"},
+		},
+	}, cs.DumpForLog())
+	assert.False(t, strings.Contains(string(cs.Dump("", "")), "******"))
+}
+
+func TestConfigSet_DumpRedacted(t *testing.T) {
+	var cs ConfigSet
+	assert.Nil(t, cs.DumpRedacted("", ""))
+
+	cs.AddSecretPaths("db.password")
+	cs.RegisterSecretProvider("vault", mapSecretProvider{"db/token": "s3cr3t"})
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: hunter2
+token: secretref://vault/db/token
+dsn: postgres://localhost/db
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"dsn":"postgres://localhost/db","password":"******","token":"******"}}`, string(cs.DumpRedacted("", "")))
+	assert.Equal(t, "{\n  \"db\": {\n    \"dsn\": \"postgres://localhost/db\",\n    \"password\": \"******\",\n    \"token\": \"******\"\n  }\n}\n", string(cs.DumpRedacted("", "  ")))
+}
@@ -0,0 +1,59 @@
+package configset
+
+import "strings"
+
+// Logger represents a leveled logger taking key-value pairs after messages,
+// which *slog.Logger of the package log/slog satisfies.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+// WithLogger returns an option that sets the logger for the config set, which
+// logs the results of loads and reloads, warnings, e.g. insecure secret files,
+// environment variables ignored, and files skipped. By default nothing is
+// logged.
+func WithLogger(logger Logger) Option {
+	return func(options *options) { options.logger = logger }
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+
+// logger returns the logger set by WithLogger, or a logger logging nothing.
+func (cs *ConfigSet) logger() Logger {
+	if cs.options.logger == nil {
+		return nopLogger{}
+	}
+	return cs.options.logger
+}
+
+// warn reports the given warning to the handler set by WithWarningHandler and
+// the logger.
+func (cs *ConfigSet) warn(warning error) {
+	if cs.options.warningHandler != nil {
+		cs.options.warningHandler(warning)
+	}
+	cs.logger().Warn("configset: warning", "err", warning)
+}
+
+// logEnviron logs the environment variables such as CONFIGSET.{path}={value}
+// applied, and the ones ignored since they are malformed.
+func (cs *ConfigSet) logEnviron(environment []string) {
+	logger := cs.logger()
+	envPrefix := cs.envPrefix()
+	for _, kv := range environment {
+		if !strings.HasPrefix(kv, envPrefix) {
+			continue
+		}
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			logger.Debug("configset: apply environment variable", "key", kv[:i])
+		} else {
+			logger.Warn("configset: ignore environment variable without value", "key", kv)
+		}
+	}
+}
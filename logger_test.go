@@ -0,0 +1,53 @@
+package configset_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	mutex sync.Mutex
+	lines []string
+}
+
+func (rl *recordingLogger) Debug(msg string, args ...interface{}) { rl.log("DEBUG", msg, args) }
+func (rl *recordingLogger) Info(msg string, args ...interface{})  { rl.log("INFO", msg, args) }
+func (rl *recordingLogger) Warn(msg string, args ...interface{})  { rl.log("WARN", msg, args) }
+
+func (rl *recordingLogger) log(level string, msg string, args []interface{}) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	line := level + " " + msg
+	for i := 0; i+1 < len(args); i += 2 {
+		line += fmt.Sprintf(" %v=%v", args[i], args[i+1])
+	}
+	rl.lines = append(rl.lines, line)
+}
+
+func TestWithLogger(t *testing.T) {
+	var cs ConfigSet
+	var logger recordingLogger
+	cs.Configure(WithLogger(&logger))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/README.md", []byte(`...`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost", "CONFIGSET.server.tls"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"DEBUG configset: skip non-yaml file filePath=/my_etc/README.md",
+		"DEBUG configset: apply environment variable key=CONFIGSET.server.host",
+		"WARN configset: ignore environment variable without value key=CONFIGSET.server.tls",
+		"INFO configset: loaded generation=1 fingerprint=" + cs.Fingerprint(),
+	}, logger.lines)
+}
@@ -0,0 +1,107 @@
+package configset
+
+import (
+	"encoding/json"
+
+	"github.com/tidwall/gjson"
+)
+
+// mergeJSON deep-merges the given overlay into the given base. Objects are
+// merged key by key, whereas other values from the overlay replace the ones
+// from the base.
+func mergeJSON(base json.RawMessage, overlay json.RawMessage) json.RawMessage {
+	return appendMergedJSON(nil, gjson.ParseBytes(base), gjson.ParseBytes(overlay))
+}
+
+func appendMergedJSON(buffer []byte, base gjson.Result, overlay gjson.Result) []byte {
+	if !overlay.Exists() {
+		return append(buffer, base.Raw...)
+	}
+	if !base.IsObject() || !overlay.IsObject() {
+		return append(buffer, overlay.Raw...)
+	}
+	overlayValues := overlay.Map()
+	baseKeys := make(map[string]struct{})
+	buffer = append(buffer, '{')
+	n := 0
+	base.ForEach(func(key, baseValue gjson.Result) bool {
+		baseKeys[key.Str] = struct{}{}
+		if n >= 1 {
+			buffer = append(buffer, ',')
+		}
+		n++
+		buffer = append(buffer, key.Raw...)
+		buffer = append(buffer, ':')
+		buffer = appendMergedJSON(buffer, baseValue, overlayValues[key.Str])
+		return true
+	})
+	overlay.ForEach(func(key, overlayValue gjson.Result) bool {
+		if _, ok := baseKeys[key.Str]; ok {
+			return true
+		}
+		if n >= 1 {
+			buffer = append(buffer, ',')
+		}
+		n++
+		buffer = append(buffer, key.Raw...)
+		buffer = append(buffer, ':')
+		buffer = append(buffer, overlayValue.Raw...)
+		return true
+	})
+	buffer = append(buffer, '}')
+	return buffer
+}
+
+// applyMergePatch applies the given patch to the given base following JSON merge
+// patches (RFC 7386), i.e. like mergeJSON but null values in the patch delete
+// the values from the base.
+func applyMergePatch(base json.RawMessage, patch json.RawMessage) json.RawMessage {
+	return appendPatchedJSON(nil, gjson.ParseBytes(base), gjson.ParseBytes(patch))
+}
+
+func appendPatchedJSON(buffer []byte, base gjson.Result, patch gjson.Result) []byte {
+	if !patch.IsObject() {
+		return append(buffer, patch.Raw...)
+	}
+	patchValues := patch.Map()
+	baseKeys := make(map[string]struct{})
+	buffer = append(buffer, '{')
+	n := 0
+	appendValue := func(key gjson.Result, value gjson.Result, patchValue gjson.Result) {
+		if n >= 1 {
+			buffer = append(buffer, ',')
+		}
+		n++
+		buffer = append(buffer, key.Raw...)
+		buffer = append(buffer, ':')
+		buffer = appendPatchedJSON(buffer, value, patchValue)
+	}
+	if base.IsObject() {
+		base.ForEach(func(key, baseValue gjson.Result) bool {
+			baseKeys[key.Str] = struct{}{}
+			patchValue, ok := patchValues[key.Str]
+			switch {
+			case !ok:
+				if n >= 1 {
+					buffer = append(buffer, ',')
+				}
+				n++
+				buffer = append(buffer, key.Raw...)
+				buffer = append(buffer, ':')
+				buffer = append(buffer, baseValue.Raw...)
+			case patchValue.Type != gjson.Null:
+				appendValue(key, baseValue, patchValue)
+			}
+			return true
+		})
+	}
+	patch.ForEach(func(key, patchValue gjson.Result) bool {
+		if _, ok := baseKeys[key.Str]; ok || patchValue.Type == gjson.Null {
+			return true
+		}
+		appendValue(key, gjson.Result{}, patchValue)
+		return true
+	})
+	buffer = append(buffer, '}')
+	return buffer
+}
@@ -0,0 +1,60 @@
+package configset
+
+import "encoding/json"
+
+// WithMergeTrace returns an option that makes loads record a step-by-step trace
+// of the merges of the layers of the config set, e.g. a file set a value and
+// an environment variable replaced it, retrievable with MergeTrace, for
+// diagnosing precedence surprises. Tracing merges is for debugging, as traces
+// can be large.
+func WithMergeTrace() Option {
+	return func(options *options) { options.mergeTrace = true }
+}
+
+// MergeTrace returns the trace of the merges of the last successful load, in
+// order, if the option WithMergeTrace is set. The values of secrets are masked,
+// see Origin.
+func MergeTrace() []MergeStep { return cs.MergeTrace() }
+
+// MergeStep represents a step of merges of the layers of the config set.
+type MergeStep struct {
+	// Path is the path of the value merged.
+	Path string
+
+	// Origin is the layer supplying the value.
+	Origin Origin
+
+	// OldValue is the value replaced in form of JSON, if any, masked like
+	// Origin.Value.
+	OldValue json.RawMessage
+
+	// Deleted indicates the value is deleted rather than merged, e.g. by null
+	// in overrides.yaml.
+	Deleted bool
+}
+
+// String returns the step in form of text, e.g.
+// "env CONFIGSET.server.port replaced server.port=8080 with 8081".
+func (ms MergeStep) String() string {
+	switch {
+	case ms.Deleted:
+		return ms.Origin.String() + " deleted " + ms.Path
+	case ms.OldValue != nil:
+		return ms.Origin.String() + " replaced " + ms.Path + "=" + string(ms.OldValue) + " with " + string(ms.Origin.Value)
+	default:
+		return ms.Origin.String() + " set " + ms.Path + "=" + string(ms.Origin.Value)
+	}
+}
+
+func (cs *ConfigSet) MergeTrace() []MergeStep {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	maskedPaths := cs.maskedPaths()
+	var mergeTrace []MergeStep
+	for _, mergeStep := range cs.mergeTrace {
+		mergeStep.Origin.Value = maskSecrets(mergeStep.Path, mergeStep.Origin.Value, maskedPaths)
+		mergeStep.OldValue = maskSecrets(mergeStep.Path, mergeStep.OldValue, maskedPaths)
+		mergeTrace = append(mergeTrace, mergeStep)
+	}
+	return mergeTrace
+}
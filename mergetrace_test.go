@@ -0,0 +1,50 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMergeTrace(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/defaults.yaml", []byte(`
+server:
+  port: 80
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tls: {cert: a.pem}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/overrides.yaml", []byte(`
+server:
+  tls: null
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+	assert.Empty(t, cs.MergeTrace())
+
+	cs.Configure(WithMergeTrace())
+	err = cs.ForceRefresh()
+	assert.NoError(t, err)
+	var lines []string
+	for _, mergeStep := range cs.MergeTrace() {
+		lines = append(lines, mergeStep.String())
+	}
+	assert.Equal(t, []string{
+		"defaultsFile /my_etc/defaults.yaml set server.port=80",
+		"file /my_etc/server.yaml replaced server.port=80 with 8080",
+		"file /my_etc/server.yaml set server.tls.cert=\"a.pem\"",
+		"env CONFIGSET.server.port replaced server.port=8080 with 8081",
+		"overrides /my_etc/overrides.yaml deleted server.tls",
+	}, lines)
+}
@@ -0,0 +1,105 @@
+package configset
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// MetricsHandler returns an HTTP handler serving the metrics of the config set
+// in the Prometheus text format, so that dashboards can alert on stale or
+// failing reloads, e.g. mounted at /metrics or added to an existing endpoint
+// with WriteMetrics. The metrics are:
+//
+//	configset_loads_total{result="success|failure"}  loads, including reloads
+//	configset_load_duration_seconds                   duration of the last load
+//	configset_last_load_timestamp_seconds             time of the last successful load
+//	configset_keys                                    number of leaf values
+//	configset_info{fingerprint="..."}                 fingerprint, see Fingerprint
+func MetricsHandler() http.Handler { return cs.MetricsHandler() }
+
+// WriteMetrics likes MetricsHandler but writes the metrics to the given writer.
+func WriteMetrics(w io.Writer) error { return cs.WriteMetrics(w) }
+
+// loadStats represents the statistics of loads of the config set.
+type loadStats struct {
+	successCount int
+	failureCount int
+	lastDuration time.Duration
+	lastLoadTime time.Time
+	lastErr      error
+}
+
+func (cs *ConfigSet) recordLoad(duration time.Duration, err error) {
+	cs.loadStats.lastDuration = duration
+	cs.loadStats.lastErr = err
+	if err != nil {
+		cs.loadStats.failureCount++
+		return
+	}
+	cs.loadStats.successCount++
+	cs.loadStats.lastLoadTime = time.Now()
+}
+
+func (cs *ConfigSet) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		cs.WriteMetrics(w)
+	})
+}
+
+func (cs *ConfigSet) WriteMetrics(w io.Writer) error {
+	cs.mutex.Lock()
+	loadStats := cs.loadStats
+	snapshot := cs.Snapshot()
+	cs.mutex.Unlock()
+	var lastLoadTimestamp float64
+	if !loadStats.lastLoadTime.IsZero() {
+		lastLoadTimestamp = float64(loadStats.lastLoadTime.UnixNano()) / 1e9
+	}
+	_, err := fmt.Fprintf(w, `# HELP configset_loads_total Total number of loads of the config set, including reloads.
+# TYPE configset_loads_total counter
+configset_loads_total{result="success"} %d
+configset_loads_total{result="failure"} %d
+# HELP configset_load_duration_seconds Duration of the last load of the config set.
+# TYPE configset_load_duration_seconds gauge
+configset_load_duration_seconds %g
+# HELP configset_last_load_timestamp_seconds Time of the last successful load of the config set.
+# TYPE configset_last_load_timestamp_seconds gauge
+configset_last_load_timestamp_seconds %g
+# HELP configset_keys Number of leaf values of the config set.
+# TYPE configset_keys gauge
+configset_keys %d
+# HELP configset_info Information of the config set.
+# TYPE configset_info gauge
+configset_info{fingerprint=%q} 1
+`, loadStats.successCount, loadStats.failureCount, loadStats.lastDuration.Seconds(), lastLoadTimestamp,
+		countLeafValues(gjson.ParseBytes(snapshot.raw), true), snapshot.Fingerprint())
+	if err != nil {
+		return fmt.Errorf("write metrics: %w", err)
+	}
+	return nil
+}
+
+// countLeafValues returns the number of leaf values within the given value,
+// including empty objects unless it's the root.
+func countLeafValues(value gjson.Result, isRoot bool) int {
+	if !value.IsObject() {
+		if value.Exists() {
+			return 1
+		}
+		return 0
+	}
+	n := 0
+	value.ForEach(func(_, value gjson.Result) bool {
+		n += countLeafValues(value, false)
+		return true
+	})
+	if n == 0 && !isRoot {
+		return 1
+	}
+	return n
+}
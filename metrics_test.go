@@ -0,0 +1,56 @@
+package configset_test
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_MetricsHandler(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tags: [a, b]
+tls: {}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: [8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.ForceRefresh()
+	assert.Error(t, err)
+
+	recorder := httptest.NewRecorder()
+	cs.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	body := recorder.Body.String()
+	body = regexp.MustCompile(`(?m)^(configset_load_duration_seconds|configset_last_load_timestamp_seconds) .+$`).
+		ReplaceAllString(body, "$1 X")
+	assert.Equal(t, `# HELP configset_loads_total Total number of loads of the config set, including reloads.
+# TYPE configset_loads_total counter
+configset_loads_total{result="success"} 1
+configset_loads_total{result="failure"} 1
+# HELP configset_load_duration_seconds Duration of the last load of the config set.
+# TYPE configset_load_duration_seconds gauge
+configset_load_duration_seconds X
+# HELP configset_last_load_timestamp_seconds Time of the last successful load of the config set.
+# TYPE configset_last_load_timestamp_seconds gauge
+configset_last_load_timestamp_seconds X
+# HELP configset_keys Number of leaf values of the config set.
+# TYPE configset_keys gauge
+configset_keys 3
+# HELP configset_info Information of the config set.
+# TYPE configset_info gauge
+configset_info{fingerprint="`+cs.Fingerprint()+`"} 1
+`, body)
+}
@@ -0,0 +1,38 @@
+package configset
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// FingerprintHeader is the header of responses set by Middleware to the
+	// fingerprint of the config set, see Fingerprint.
+	FingerprintHeader = "X-Config-Fingerprint"
+
+	// GenerationHeader is the header of responses set by Middleware to the
+	// sequence number of the current generation of the config set, see History.
+	GenerationHeader = "X-Config-Generation"
+)
+
+// Middleware returns an HTTP handler that stamps the responses of the given
+// handler with the headers FingerprintHeader and GenerationHeader, so that
+// behavior changes can be correlated with config pushes across a fleet. The
+// middleware can wrap a whole server or health endpoints only. The headers are
+// omitted if the config set has not been loaded.
+func Middleware(next http.Handler) http.Handler { return cs.Middleware(next) }
+
+func (cs *ConfigSet) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cs.mutex.Lock()
+		snapshot := cs.Snapshot()
+		generation := cs.generationCount
+		cs.mutex.Unlock()
+		if snapshot.loaded {
+			header := w.Header()
+			header.Set(FingerprintHeader, snapshot.Fingerprint())
+			header.Set(GenerationHeader, strconv.Itoa(generation))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
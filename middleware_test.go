@@ -0,0 +1,60 @@
+package configset_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Middleware(t *testing.T) {
+	var cs ConfigSet
+	handler := cs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.NotContains(t, recorder.Header(), FingerprintHeader)
+	assert.NotContains(t, recorder.Header(), GenerationHeader)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, cs.Fingerprint(), recorder.Header().Get(FingerprintHeader))
+	assert.Equal(t, "1", recorder.Header().Get(GenerationHeader))
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8081\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := cs.Fingerprint()
+	err = cs.ForceRefresh()
+	assert.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, cs.Fingerprint(), recorder.Header().Get(FingerprintHeader))
+	assert.NotEqual(t, fingerprint, recorder.Header().Get(FingerprintHeader))
+	assert.Equal(t, "2", recorder.Header().Get(GenerationHeader))
+}
+
+func TestConfigSet_Middleware_set(t *testing.T) {
+	var cs ConfigSet
+	err := cs.Set("server.port", 8080)
+	assert.NoError(t, err)
+	handler := cs.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/healthz", nil))
+	assert.NotContains(t, recorder.Header(), FingerprintHeader)
+	assert.NotContains(t, recorder.Header(), GenerationHeader)
+}
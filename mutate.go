@@ -0,0 +1,168 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// Set sets the value for the given path in the config set to the given value
+// in form of JSON, and notifies subscribers of changed values, e.g. for tests
+// and admin endpoints tweaking configuration at runtime. The config set is
+// checked against the rules and validators, the value is not set if the check
+// fails. Values set are discarded on the next reload, unless the option
+// WithPersistentOverrides is set.
+func Set(path string, value interface{}) error { return cs.Set(path, value) }
+
+// Delete likes Set but deletes the value for the given path from the config
+// set. If no value can be found by the path, ErrValueNotFound is returned.
+func Delete(path string) error { return cs.Delete(path) }
+
+// MergeAt likes Set but deep-merges the given partial value in form of JSON
+// into the value for the given path, rather than replacing the value, e.g. for
+// plugins contributing configuration at runtime. Objects are merged key by key,
+// whereas other values replace the existing ones. An empty path refers to the
+// whole config set.
+func MergeAt(path string, partial json.RawMessage) error { return cs.MergeAt(path, partial) }
+
+// Update runs the given function with a transaction, which stages multiple
+// updates to the config set, and then applies all the updates atomically, i.e.
+// the config set is checked and replaced once, like Set does, so that partially
+// applied updates can't be observed. If the given function or the check fails,
+// none of the updates is applied and the error is returned.
+// The given function must not call any other function of the config set.
+func Update(f func(tx *Txn) error) error { return cs.Update(f) }
+
+// Txn represents a transaction of updates to the config set.
+type Txn struct {
+	raw     json.RawMessage
+	paths   []string
+	changes []txnChange
+	actor   string
+}
+
+// txnChange records an update staged in a transaction for the provenance of
+// values.
+type txnChange struct {
+	path  string
+	value json.RawMessage // nil for deletions
+	merge bool
+}
+
+// SetActor sets the actor of the transaction, e.g. the name of the user, which
+// is reported in audit events, see WithAuditSink.
+func (tx *Txn) SetActor(actor string) { tx.actor = actor }
+
+// Set likes the package-level Set but stages the update in the transaction.
+func (tx *Txn) Set(path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal to json; path=%q valueType=\"%T\": %w", path, value, err)
+	}
+	raw, err := sjson.SetRawBytes(tx.raw, path, data)
+	if err != nil {
+		return fmt.Errorf("set json value; path=%q: %w", path, err)
+	}
+	tx.raw = raw
+	tx.paths = append(tx.paths, path)
+	tx.changes = append(tx.changes, txnChange{path: path, value: data})
+	return nil
+}
+
+// Delete likes the package-level Delete but stages the update in the
+// transaction.
+func (tx *Txn) Delete(path string) error {
+	if !gjson.GetBytes(tx.raw, path).Exists() {
+		return fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	raw, err := sjson.DeleteBytes(tx.raw, path)
+	if err != nil {
+		return fmt.Errorf("delete json value; path=%q: %w", path, err)
+	}
+	tx.raw = raw
+	tx.paths = append(tx.paths, path)
+	tx.changes = append(tx.changes, txnChange{path: path})
+	return nil
+}
+
+// MergeAt likes the package-level MergeAt but stages the update in the
+// transaction.
+func (tx *Txn) MergeAt(path string, partial json.RawMessage) error {
+	if !json.Valid(partial) {
+		return fmt.Errorf("configset: invalid json; path=%q partial=%q", path, partial)
+	}
+	change := txnChange{path: path, value: partial, merge: true}
+	if path == "" {
+		tx.raw = mergeJSON(tx.raw, partial)
+		tx.paths = append(tx.paths, path)
+		tx.changes = append(tx.changes, change)
+		return nil
+	}
+	value := gjson.GetBytes(tx.raw, path)
+	if value.Exists() {
+		partial = mergeJSON(json.RawMessage(value.Raw), partial)
+	}
+	raw, err := sjson.SetRawBytes(tx.raw, path, partial)
+	if err != nil {
+		return fmt.Errorf("set json value; path=%q: %w", path, err)
+	}
+	tx.raw = raw
+	tx.paths = append(tx.paths, path)
+	tx.changes = append(tx.changes, change)
+	return nil
+}
+
+// ReadValue likes the package-level ReadValue but reads the value from the
+// config set with the updates staged in the transaction.
+func (tx *Txn) ReadValue(path string, config interface{}) error {
+	return (&Snapshot{raw: tx.raw}).ReadValue(path, config)
+}
+
+func (cs *ConfigSet) Set(path string, value interface{}) error {
+	return cs.Update(func(tx *Txn) error { return tx.Set(path, value) })
+}
+
+func (cs *ConfigSet) Delete(path string) error {
+	return cs.Update(func(tx *Txn) error { return tx.Delete(path) })
+}
+
+func (cs *ConfigSet) MergeAt(path string, partial json.RawMessage) error {
+	return cs.Update(func(tx *Txn) error { return tx.MergeAt(path, partial) })
+}
+
+func (cs *ConfigSet) Update(f func(tx *Txn) error) error {
+	cs.mutex.Lock()
+	oldSnapshot := cs.Snapshot()
+	tx := Txn{raw: oldSnapshot.Dump("", "")}
+	if len(tx.raw) == 0 {
+		tx.raw = json.RawMessage("{}")
+	}
+	err := f(&tx)
+	var provenance *provenanceNode
+	if err == nil {
+		provenance = updateProvenance(cs.provenance, tx.changes, tx.actor)
+		err = cs.check(tx.raw, provenance)
+	}
+	if err == nil && cs.options.persistentOverrides && cs.input.fs != nil {
+		err = cs.persistOverrides(tx.raw, tx.paths)
+	}
+	if err != nil {
+		cs.mutex.Unlock()
+		return err
+	}
+	cs.provenance = provenance
+	cs.storeSnapshot(tx.raw)
+	auditSink := cs.options.auditSink
+	cs.mutex.Unlock()
+	if oldSnapshot.raw != nil {
+		cs.subscriptions.Notify(oldSnapshot.raw, tx.raw)
+	}
+	if auditSink != nil {
+		for _, path := range tx.paths {
+			auditSink(newAuditEvent(AuditUpdate, tx.actor, path, oldSnapshot.raw, tx.raw))
+		}
+	}
+	return nil
+}
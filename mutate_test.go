@@ -0,0 +1,131 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Set(t *testing.T) {
+	var cs ConfigSet
+	cs.AddRules(Assert("server.port", Required()))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var newHost json.RawMessage
+	cs.Subscribe("server.host", func(_, newValue json.RawMessage) { newHost = newValue })
+
+	err = cs.Set("server.host", "localhost")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+	assert.Equal(t, `"localhost"`, string(newHost))
+
+	err = cs.Set("server.tags", map[string]int{"a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080,"tags":{"a":1}}}`, string(cs.Dump("", "")))
+
+	err = cs.Set("server.tags", func() {})
+	assert.EqualError(t, err, `marshal to json; path="server.tags" valueType="func()": json: unsupported type: func()`)
+
+	err = cs.Delete("server.host")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080,"tags":{"a":1}}}`, string(cs.Dump("", "")))
+	assert.Nil(t, newHost)
+
+	err = cs.Delete("server.host")
+	assert.ErrorIs(t, err, ErrValueNotFound)
+	err = cs.Delete("server.port")
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	assert.Equal(t, `{"server":{"port":8080,"tags":{"a":1}}}`, string(cs.Dump("", "")))
+
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+}
+
+func TestConfigSet_Update(t *testing.T) {
+	var cs ConfigSet
+	cs.AddRules(Assert("server.port", Required()))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var notificationCount int
+	cs.Subscribe("", func(json.RawMessage, json.RawMessage) { notificationCount++ })
+
+	err = cs.Update(func(tx *Txn) error {
+		if err := tx.Set("server.host", "localhost"); err != nil {
+			return err
+		}
+		var host string
+		if err := tx.ReadValue("server.host", &host); err != nil {
+			return err
+		}
+		return tx.Set("client.server", host+":8080")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"client":{"server":"localhost:8080"},"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+	assert.Equal(t, 1, notificationCount)
+
+	err = cs.Update(func(tx *Txn) error {
+		if err := tx.Delete("client"); err != nil {
+			return err
+		}
+		return tx.Delete("client")
+	})
+	assert.ErrorIs(t, err, ErrValueNotFound)
+	err = cs.Update(func(tx *Txn) error {
+		if err := tx.Delete("client"); err != nil {
+			return err
+		}
+		return tx.Delete("server.port")
+	})
+	assert.ErrorIs(t, err, ErrRuleViolation)
+	assert.Equal(t, `{"client":{"server":"localhost:8080"},"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+	assert.Equal(t, 1, notificationCount)
+}
+
+func TestConfigSet_MergeAt(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tls:
+  enabled: false
+  cert: /etc/cert.pem
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.MergeAt("server", json.RawMessage(`{"tls":{"enabled":true},"host":"localhost"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080,"tls":{"cert":"/etc/cert.pem","enabled":true}}}`, string(cs.Dump("", "")))
+
+	err = cs.MergeAt("plugins.foo", json.RawMessage(`{"enabled":true}`))
+	assert.NoError(t, err)
+	err = cs.MergeAt("", json.RawMessage(`{"plugins":{"bar":{"enabled":false}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"plugins":{"bar":{"enabled":false},"foo":{"enabled":true}},"server":{"host":"localhost","port":8080,"tls":{"cert":"/etc/cert.pem","enabled":true}}}`, string(cs.Dump("", "")))
+
+	err = cs.MergeAt("server", json.RawMessage(`{`))
+	assert.EqualError(t, err, `configset: invalid json; path="server" partial="{"`)
+}
@@ -0,0 +1,124 @@
+package configset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// New creates a config set with the given options, e.g.
+//
+//	cs := configset.New(configset.WithDir("/etc/app"), configset.WithLogger(logger))
+//	if err := cs.Open(ctx); err != nil {
+//		// ...
+//	}
+//
+// so that applications can pass config sets around rather than sharing the
+// global one behind the package-level functions.
+func New(options ...Option) *ConfigSet {
+	configSet := new(ConfigSet)
+	configSet.Configure(options...)
+	return configSet
+}
+
+// WithFS returns an option that sets the file system Open loads the config set
+// from. The default file system is the one of the OS.
+func WithFS(fs afero.Fs) Option {
+	return func(options *options) { options.fs = fs }
+}
+
+// WithDir returns an option that sets the directory Open loads the config set
+// from. Configuring the directory also makes the first ReadValue load the config
+// set if it has not been loaded, e.g.
+//
+//	configset.Configure(configset.WithDir("/etc/app"))
+//	configset.MustReadValue("server", &serverConfig) // loads the config set once
+func WithDir(dirPath string) Option {
+	return func(options *options) { options.dirPath = dirPath }
+}
+
+// WithEnvironment returns an option that sets the environment the package-level
+// Load and Open load the config set with, so that tests can inject environments
+// without mutating the one of the process, which is the default environment.
+func WithEnvironment(environment []string) Option {
+	return func(options *options) { options.environment = environment }
+}
+
+// WithEnvPrefix returns an option that sets the prefix of the environment
+// variables overwriting the config set, e.g. "MYAPP." for environment variables
+// such as MYAPP.{path}={value}, see Load. The prefix also applies to Environ and
+// AppendEnviron. The default prefix is "CONFIGSET.".
+func WithEnvPrefix(envPrefix string) Option {
+	return func(options *options) { options.envPrefix = envPrefix }
+}
+
+// envPrefix returns the prefix set by WithEnvPrefix, or the default one.
+func (cs *ConfigSet) envPrefix() string {
+	if cs.options.envPrefix == "" {
+		return keyPrefix
+	}
+	return cs.options.envPrefix
+}
+
+var errDirNotSet = errors.New("configset: directory not set")
+
+// Open loads the config set from the file system and the directory set by
+// WithFS and WithDir, with the environment set by WithEnvironment, like LoadContext
+// does.
+func (cs *ConfigSet) Open(ctx context.Context) error {
+	cs.mutex.Lock()
+	input := loadInput{
+		fs:      cs.options.fs,
+		dirPath: cs.options.dirPath,
+	}
+	cs.mutex.Unlock()
+	if input.dirPath == "" {
+		return errDirNotSet
+	}
+	if input.fs == nil {
+		input.fs = afero.NewOsFs()
+	}
+	input.environment = cs.environment()
+	return cs.load(ctx, &input)
+}
+
+// environment returns the environment set by WithEnvironment, or the one of the
+// process.
+func (cs *ConfigSet) environment() []string {
+	cs.mutex.Lock()
+	environment := cs.options.environment
+	cs.mutex.Unlock()
+	if environment == nil {
+		return os.Environ()
+	}
+	return environment
+}
+
+// autoLoad loads the config set, see Open, if the config set has not been
+// loaded but the directory has been set with WithDir. Failed loads are not
+// remembered, so that the next call tries again, e.g. once a missing file has
+// been mounted.
+func (cs *ConfigSet) autoLoad() error {
+	if cs.Snapshot().loaded {
+		return nil
+	}
+	cs.autoLoadMutex.Lock()
+	defer cs.autoLoadMutex.Unlock()
+	if cs.autoLoaded {
+		return nil
+	}
+	cs.mutex.Lock()
+	dirPath := cs.options.dirPath
+	cs.mutex.Unlock()
+	if dirPath == "" {
+		return nil
+	}
+	if err := cs.Open(context.Background()); err != nil {
+		return fmt.Errorf("auto-load config set: %w", err)
+	}
+	cs.autoLoaded = true
+	return nil
+}
@@ -0,0 +1,111 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs := New(WithFS(fs))
+	err := cs.Open(context.Background())
+	assert.EqualError(t, err, "configset: directory not set")
+
+	cs = New(
+		WithFS(fs),
+		WithDir("/my_etc"),
+		WithEnvironment([]string{"MYAPP.server.host=localhost", "CONFIGSET.server.port=8081"}),
+		WithEnvPrefix("MYAPP."),
+		WithSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+			return json.RawMessage(`{"server":{"timeout":"1s"}}`), nil
+		})),
+	)
+	err = cs.Open(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080,"timeout":"1s"}}`, string(cs.Dump("", "")))
+	assert.Equal(t, []string{
+		"PATH=/bin",
+		`MYAPP.server.host="localhost"`,
+		"MYAPP.server.port=8080",
+		`MYAPP.server.timeout="1s"`,
+	}, cs.AppendEnviron([]string{"PATH=/bin", "MYAPP.server.port=1"}))
+
+	err = cs.ForceRefresh()
+	assert.NoError(t, err)
+}
+
+func TestConfigSet_ReadValue_autoLoad(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var loadCount int
+	cs := New(WithFS(fs), WithDir("/my_etc"), WithEnvironment([]string{}), WithBeforeLoadHook(func(context.Context) error {
+		loadCount++
+		return nil
+	}))
+	for i := 0; i < 2; i++ {
+		var port int
+		err := cs.ReadValue("server.port", &port)
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, port)
+	}
+	assert.Equal(t, 1, loadCount)
+
+	cs = New(WithFS(fs), WithDir("/your_etc"))
+	var port int
+	err := cs.ReadValue("server.port", &port)
+	assert.EqualError(t, err, `auto-load config set: read dir; dirPath="/your_etc": open /your_etc: file does not exist`)
+	if err := afero.WriteFile(fs, "/your_etc/server.yaml", []byte(`
+port: 8081
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, port)
+
+	cs = New(WithFS(fs), WithDir("/my_etc"))
+	err = cs.Set("server.host", "localhost")
+	assert.NoError(t, err)
+	err = cs.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	cs = New(WithFS(fs))
+	err = cs.ReadValue("server.port", &port)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+}
+
+func TestWithEnvironment(t *testing.T) {
+	dirPath := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dirPath, "server.yaml"), []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer Reset()
+	Configure(WithEnvironment([]string{"CONFIGSET.server.port=8081"}))
+	err := Load(dirPath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var port int
+	err = ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, port)
+}
@@ -0,0 +1,111 @@
+package configset
+
+import (
+	"context"
+	"crypto/ed25519"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Configure configures the config set with the given options.
+func Configure(options ...Option) { cs.Configure(options...) }
+
+// Option represents an option of the config set.
+type Option func(options *options)
+
+type options struct {
+	typeStabilityCheck    bool
+	decodeMode            DecodeMode
+	watchInterval         time.Duration
+	watchQuietPeriod      time.Duration
+	pollInterval          time.Duration
+	pollJitter            time.Duration
+	reloadErrorHandler    func(error)
+	historySize           int
+	roundTrip             bool
+	persistentOverrides   bool
+	auditSink             func(AuditEvent)
+	sopsDecryptor         func([]byte, string) ([]byte, error)
+	decryptor             Decryptor
+	secretFilePolicy      SecretFilePolicy
+	warningHandler        func(error)
+	signatureKey          ed25519.PublicKey
+	checksumManifest      bool
+	secretRefreshInterval time.Duration
+	secretsOnDemand       bool
+	template              *templateOptions
+	logValueLimit         int
+	logger                Logger
+	tracer                Tracer
+	mergeTrace            bool
+	beforeLoadHook        func(context.Context) error
+	afterLoadHook         func(*Snapshot)
+	afterReloadHook       func(*Snapshot, *Snapshot)
+	loadConcurrency       int
+	decodeCache           bool
+	jsonCodec             JSONCodec
+	fileSizeLimit         int64
+	totalSizeLimit        int64
+	fs                    afero.Fs
+	dirPath               string
+	environment           []string
+	envPrefix             string
+	sources               []Source
+	handlerReload         bool
+	flagPatches           []valuePatch
+	fileRoots             []string
+}
+
+// WithTypeStabilityCheck returns an option that makes reloads, i.e. loads of
+// an already loaded config set, fail with ErrTypeChanged if the JSON type of
+// any value changes, e.g. `timeout` goes from number to string, which protects
+// long-running services from shape-breaking config pushes.
+func WithTypeStabilityCheck() Option {
+	return func(options *options) { options.typeStabilityCheck = true }
+}
+
+// WithLoadConcurrency returns an option that sets the maximum number of files
+// read and converted concurrently during loads, which speeds up loading large
+// directories, especially on networked file systems. The default concurrency
+// is 1, i.e. files are read one by one. With a concurrency above 1, the SOPS
+// decryptor and template functions, if any, must be safe for concurrent use.
+// Errors and warnings are reported in the order of files regardless.
+func WithLoadConcurrency(loadConcurrency int) Option {
+	return func(options *options) { options.loadConcurrency = loadConcurrency }
+}
+
+func (cs *ConfigSet) Configure(options ...Option) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	for _, option := range options {
+		option(&cs.options)
+	}
+}
+
+// DecodeMode represents the way ReadValue decodes values into configs.
+type DecodeMode int
+
+const (
+	// DecodeOverlay decodes values into configs following encoding/json, which
+	// keeps the fields absent in values, but replaces slices and map entries
+	// present in values without looking into them. This is the default.
+	DecodeOverlay DecodeMode = iota
+
+	// DecodeReplace resets configs to zero values before decoding, so nothing
+	// pre-populated in configs survives.
+	DecodeReplace
+
+	// DecodeMerge deep-merges values into the pre-populated configs, so absent
+	// keys keep the pre-populated values at any depth, including the ones of
+	// structs within maps, whereas slices present in values are replaced as a
+	// whole. Since all fields of the pre-populated configs count as present,
+	// `default` struct tags take no effect.
+	DecodeMerge
+)
+
+// WithDecodeMode returns an option that sets the way ReadValue decodes values
+// into configs.
+func WithDecodeMode(decodeMode DecodeMode) Option {
+	return func(options *options) { options.decodeMode = decodeMode }
+}
@@ -0,0 +1,105 @@
+package configset_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDecodeMode(t *testing.T) {
+	type Backend struct {
+		Host    string `json:"host"`
+		Timeout int    `json:"timeout"`
+	}
+	type Config struct {
+		Name     string             `json:"name"`
+		Tags     []string           `json:"tags"`
+		Backends map[string]Backend `json:"backends"`
+	}
+	newConfig := func() *Config {
+		return &Config{
+			Name: "default",
+			Tags: []string{"x", "y", "z"},
+			Backends: map[string]Backend{
+				"primary": {Host: "localhost", Timeout: 30},
+			},
+		}
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/app.yaml", []byte(`
+tags: [a]
+backends:
+  primary:
+    host: example.com
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testCase := range []struct {
+		decodeMode     DecodeMode
+		expectedConfig *Config
+	}{
+		{
+			decodeMode: DecodeOverlay,
+			expectedConfig: &Config{
+				Name:     "default",
+				Tags:     []string{"a"},
+				Backends: map[string]Backend{"primary": {Host: "example.com"}},
+			},
+		},
+		{
+			decodeMode: DecodeReplace,
+			expectedConfig: &Config{
+				Tags:     []string{"a"},
+				Backends: map[string]Backend{"primary": {Host: "example.com"}},
+			},
+		},
+		{
+			decodeMode: DecodeMerge,
+			expectedConfig: &Config{
+				Name:     "default",
+				Tags:     []string{"a"},
+				Backends: map[string]Backend{"primary": {Host: "example.com", Timeout: 30}},
+			},
+		},
+	} {
+		var cs ConfigSet
+		cs.Configure(WithDecodeMode(testCase.decodeMode))
+		if err := cs.Load(fs, "/my_etc", nil); err != nil {
+			t.Fatal(err)
+		}
+		config := newConfig()
+		err := cs.ReadValue("app", config)
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expectedConfig, config, "decodeMode=%v", testCase.decodeMode)
+	}
+}
+
+func TestWithLoadConcurrency(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for i := 0; i < 20; i++ {
+		if err := afero.WriteFile(fs, fmt.Sprintf("/my_etc/app%02d.yaml", i), []byte(fmt.Sprintf("id: %d\n", i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var cs1, cs2 ConfigSet
+	cs2.Configure(WithLoadConcurrency(4))
+	err := cs1.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	err = cs2.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, string(cs1.Dump("", "")), string(cs2.Dump("", "")))
+
+	for _, i := range []int{15, 5} {
+		if err := afero.WriteFile(fs, fmt.Sprintf("/my_etc/app%02d.yaml", i), []byte("id: [\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cs2.ForceRefresh()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `filePath="/my_etc/app05.yaml"`)
+	}
+}
@@ -0,0 +1,106 @@
+package configset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"sigs.k8s.io/yaml"
+)
+
+// WithPersistentOverrides returns an option that makes Set, Delete and Update
+// persist the updates to the reserved file overrides.yaml under the directory
+// the config set has been loaded from, which is applied last on loads, so that
+// runtime updates survive reloads and restarts without rewriting the other
+// configuration files. Secrets are persisted in their original forms, i.e.
+// ENC[...] or secretref://..., and values of SOPS-encrypted files are not
+// persisted unless changed.
+func WithPersistentOverrides() Option {
+	return func(options *options) { options.persistentOverrides = true }
+}
+
+const overridesConfigName = "overrides"
+
+// persistOverrides records the values for the given updated paths of the given
+// config set into the overrides, and writes the overrides to the reserved file
+// overrides.yaml. Secrets decrypted or resolved at load time are recorded in
+// their original forms, and values of SOPS-encrypted files are left out, see
+// sealSecrets.
+func (cs *ConfigSet) persistOverrides(rawConfigSet json.RawMessage, paths []string) error {
+	overrides := cs.overrides
+	if overrides == nil {
+		overrides = json.RawMessage("{}")
+	}
+	secretValues := cs.allSecretValues()
+	for _, path := range paths {
+		keys := overrideKeys(rawConfigSet, splitPath(path))
+		value := gjson.GetBytes(rawConfigSet, joinKeys(keys))
+		rawValue := json.RawMessage("null")
+		if value.Exists() {
+			rawValue, _ = sealSecrets(keys, json.RawMessage(value.Raw), secretValues)
+			if rawValue == nil {
+				// sjson.DeleteBytes doesn't modify the given JSON in place.
+				overrides, _ = sjson.DeleteBytes(overrides, joinKeys(keys))
+				continue
+			}
+		}
+		overrides = mergeJSON(overrides, nestJSON(keys, rawValue))
+	}
+	if err := cs.writeOverrides(overrides); err != nil {
+		return err
+	}
+	cs.overrides = overrides
+	return nil
+}
+
+// writeOverrides writes the given overrides to the reserved file overrides.yaml,
+// or removes the file if there are no overrides.
+func (cs *ConfigSet) writeOverrides(overrides json.RawMessage) error {
+	filePath := filepath.Join(cs.input.dirPath, overridesConfigName+".yaml")
+	if overrides == nil {
+		if err := cs.input.fs.Remove(filePath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove file; filePath=%q: %w", filePath, err)
+		}
+		return nil
+	}
+	data, err := yaml.JSONToYAML(overrides)
+	if err != nil {
+		return fmt.Errorf("convert json to yaml: %w", err)
+	}
+	if err := afero.WriteFile(cs.input.fs, filePath, data, 0644); err != nil {
+		return fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+	}
+	return nil
+}
+
+// overrideKeys truncates the given keys of a path at the first array, since
+// merge patches replace arrays as a whole.
+func overrideKeys(rawConfigSet json.RawMessage, keys []string) []string {
+	for i := range keys {
+		if gjson.GetBytes(rawConfigSet, joinKeys(keys[:i])).IsArray() {
+			return keys[:i]
+		}
+	}
+	return keys
+}
+
+func joinKeys(keys []string) string {
+	var path string
+	for _, key := range keys {
+		path = joinPath(path, key)
+	}
+	return path
+}
+
+// nestJSON nests the given value in objects with the given keys.
+func nestJSON(keys []string, value json.RawMessage) json.RawMessage {
+	for i := len(keys) - 1; i >= 0; i-- {
+		rawKey, _ := json.Marshal(keys[i])
+		value = json.RawMessage(fmt.Sprintf("{%s:%s}", rawKey, value))
+	}
+	return value
+}
@@ -0,0 +1,126 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_PersistentOverrides(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithPersistentOverrides())
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+host: localhost
+tags: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Update(func(tx *Txn) error {
+		if err := tx.Set("server.port", 8082); err != nil {
+			return err
+		}
+		if err := tx.Set("server.tags.2", "c"); err != nil {
+			return err
+		}
+		return tx.Delete("server.host")
+	})
+	assert.NoError(t, err)
+	err = cs.Set("client.timeout", 5)
+	assert.NoError(t, err)
+	data, err := afero.ReadFile(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `client:
+  timeout: 5
+server:
+  host: null
+  port: 8082
+  tags:
+  - a
+  - b
+  - c
+`, string(data))
+	dump := string(cs.Dump("", ""))
+	assert.Equal(t, `{"client":{"timeout":5},"server":{"port":8082,"tags":["a","b","c"]}}`, dump)
+
+	var cs2 ConfigSet
+	err = cs2.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+	assert.Equal(t, dump, string(cs2.Dump("", "")))
+
+	var cs3 ConfigSet
+	err = cs3.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	err = cs3.Set("server.port", 8083)
+	assert.NoError(t, err)
+	data, err = afero.ReadFile(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "port: 8082")
+}
+
+func TestConfigSet_PersistentOverrides_secrets(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(
+		WithPersistentOverrides(),
+		WithDecryptor(base64Decryptor{}),
+		WithSOPSDecryptor(func([]byte, string) ([]byte, error) {
+			return []byte("password: test\n"), nil
+		}),
+	)
+	cs.RegisterSecretProvider("vault", mapSecretProvider{"db#token": "abc"})
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: ENC[dGVzdA==]
+token: secretref://vault/db#token
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/cache.yaml", []byte(`
+password: ENC[AES256_GCM,data:dGVzdA==,iv:aXY=,tag:dGFn,type:str]
+sops:
+    mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Update(func(tx *Txn) error {
+		if err := tx.Set("db.user", "admin"); err != nil {
+			return err
+		}
+		return tx.Set("cache.port", 6379)
+	})
+	assert.NoError(t, err)
+	err = cs.Set("db", map[string]string{"user": "admin", "password": "test", "token": "abc"})
+	assert.NoError(t, err)
+	err = cs.Set("cache.password", "test")
+	assert.NoError(t, err)
+	data, err := afero.ReadFile(fs, "/my_etc/overrides.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `cache:
+  port: 6379
+db:
+  password: ENC[dGVzdA==]
+  token: secretref://vault/db#token
+  user: admin
+`, string(data))
+
+	var cs2 ConfigSet
+	cs2.Configure(WithDecryptor(base64Decryptor{}), WithSOPSDecryptor(func([]byte, string) ([]byte, error) {
+		return []byte("password: test\n"), nil
+	}))
+	cs2.RegisterSecretProvider("vault", mapSecretProvider{"db#token": "abc"})
+	err = cs2.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, string(cs.Dump("", "")), string(cs2.Dump("", "")))
+}
@@ -0,0 +1,69 @@
+package configset
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// joinPath joins the given parent path and key into a path, escaping the
+// special characters within the key.
+func joinPath(parentPath string, key string) string {
+	var builder strings.Builder
+	builder.Grow(len(parentPath) + 1 + len(key))
+	if parentPath != "" {
+		builder.WriteString(parentPath)
+		builder.WriteByte('.')
+	}
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '.', '*', '?', '|', '#', '@', '\\', ':':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+// splitPath splits the given path into keys, unescaping the special characters
+// within the keys.
+func splitPath(path string) []string {
+	var keys []string
+	var builder strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			if i+1 < len(path) {
+				i++
+				builder.WriteByte(path[i])
+			}
+		case '.':
+			keys = append(keys, builder.String())
+			builder.Reset()
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	keys = append(keys, builder.String())
+	return keys
+}
+
+func jsonTypeName(value gjson.Result) string {
+	switch value.Type {
+	case gjson.Null:
+		return "null"
+	case gjson.False, gjson.True:
+		return "boolean"
+	case gjson.Number:
+		return "number"
+	case gjson.String:
+		return "string"
+	default:
+		if value.IsArray() {
+			return "array"
+		}
+		return "object"
+	}
+}
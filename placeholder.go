@@ -0,0 +1,226 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+)
+
+// ErrReferenceCycle is returned when references between values form a cycle.
+var ErrReferenceCycle = errors.New("configset: reference cycle")
+
+// ErrFileNotAllowed is returned when a placeholder such as ${file:{path}}
+// refers to a file outside the config directory and the directories set by
+// WithFileRoots, or to any file if loads verify the configuration files with
+// WithSignatureKey or WithChecksumManifest, which don't cover inlined files.
+var ErrFileNotAllowed = errors.New("configset: file not allowed")
+
+// WithFileRoots returns an option that sets the directories, besides the config
+// directory, from which placeholders such as ${file:{path}} may inline files,
+// e.g. /etc/ssl/certs.
+func WithFileRoots(dirPaths ...string) Option {
+	return func(options *options) { options.fileRoots = dirPaths }
+}
+
+// placeholderResolver resolves placeholders such as ${kind:argument} within
+// string values of a config set. If a string value consists of a placeholder
+// only, the value is replaced with the value the placeholder resolves to, which
+// keeps the JSON type, otherwise the placeholders are interpolated into the
+// string. `$${` escapes `${`, and placeholders of unknown kinds are kept as they
+// are. Only the placeholders within values from configuration files and default
+// values are resolved, so that whoever controls sources, environment variables
+// or flags can't inline arbitrary files.
+type placeholderResolver struct {
+	fs           afero.Fs
+	dirPath      string
+	fileRoots    []string
+	rawConfigSet json.RawMessage
+	provenance   *provenanceNode
+	resolved     map[string]json.RawMessage
+	resolving    map[string]struct{}
+}
+
+const (
+	placeholderPrefix = "${"
+	placeholderSuffix = "}"
+)
+
+// resolvePlaceholders resolves the placeholders within the given config set. If
+// the given file system is nil, no files may be inlined.
+func resolvePlaceholders(fs afero.Fs, dirPath string, fileRoots []string, rawConfigSet json.RawMessage, provenance *provenanceNode) (json.RawMessage, error) {
+	if !strings.Contains(string(rawConfigSet), placeholderPrefix) {
+		return rawConfigSet, nil
+	}
+	pr := placeholderResolver{
+		fs:           fs,
+		dirPath:      dirPath,
+		fileRoots:    fileRoots,
+		rawConfigSet: rawConfigSet,
+		provenance:   provenance,
+		resolved:     make(map[string]json.RawMessage),
+		resolving:    make(map[string]struct{}),
+	}
+	return appendReplacedStrings(nil, "", gjson.ParseBytes(rawConfigSet), pr.expandString)
+}
+
+// resolveRef returns the value for the given path with the placeholders within
+// resolved.
+func (pr *placeholderResolver) resolveRef(path string) (json.RawMessage, error) {
+	if value, ok := pr.resolved[path]; ok {
+		return value, nil
+	}
+	if _, ok := pr.resolving[path]; ok {
+		return nil, fmt.Errorf("%w; path=%q", ErrReferenceCycle, path)
+	}
+	value := gjson.GetBytes(pr.rawConfigSet, path)
+	if !value.Exists() {
+		return nil, fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	pr.resolving[path] = struct{}{}
+	resolvedValue, err := appendReplacedStrings(nil, path, value, pr.expandString)
+	delete(pr.resolving, path)
+	if err != nil {
+		return nil, err
+	}
+	pr.resolved[path] = resolvedValue
+	return resolvedValue, nil
+}
+
+// readFile returns the contents of the file with the given path, relative to
+// the config directory unless absolute, as a string value. The file must be
+// under the config directory or the directories set by WithFileRoots.
+func (pr *placeholderResolver) readFile(filePath string) (json.RawMessage, error) {
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(pr.dirPath, filePath)
+	}
+	filePath = filepath.Clean(filePath)
+	if pr.fs == nil || !pr.isFileAllowed(filePath) {
+		return nil, fmt.Errorf("%w; filePath=%q", ErrFileNotAllowed, filePath)
+	}
+	data, err := afero.ReadFile(pr.fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+	}
+	return json.Marshal(string(data))
+}
+
+func (pr *placeholderResolver) isFileAllowed(filePath string) bool {
+	for _, dirPath := range append([]string{pr.dirPath}, pr.fileRoots...) {
+		relativePath, err := filepath.Rel(filepath.Clean(dirPath), filePath)
+		if err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFromFiles reports whether the value for the given path comes from
+// configuration files or default values.
+func (pr *placeholderResolver) isFromFiles(path string) bool {
+	origin, err := pr.provenance.lookUpOrigin(path)
+	if err != nil {
+		return false
+	}
+	switch origin.Layer {
+	case OriginDefaults, OriginDefaultsFile, OriginFile:
+		return true
+	default:
+		return false
+	}
+}
+
+func (pr *placeholderResolver) resolvePlaceholder(path string, kind string, argument string) (json.RawMessage, bool, error) {
+	switch kind {
+	case "ref":
+		value, err := pr.resolveRef(strings.TrimSpace(argument))
+		if err != nil {
+			return nil, false, fmt.Errorf("resolve reference; path=%q: %w", path, err)
+		}
+		return value, true, nil
+	case "file":
+		value, err := pr.readFile(strings.TrimSpace(argument))
+		if err != nil {
+			return nil, false, fmt.Errorf("inline file; path=%q: %w", path, err)
+		}
+		return value, true, nil
+	case "expr":
+		value, err := pr.evaluateExpr(path, strings.TrimSpace(argument))
+		if err != nil {
+			return nil, false, err
+		}
+		return value, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// expandString expands the placeholders within the given string of the value
+// for the given path, and returns nil if there are no placeholders.
+func (pr *placeholderResolver) expandString(path string, s string) (json.RawMessage, error) {
+	if !strings.Contains(s, placeholderPrefix) || !pr.isFromFiles(path) {
+		return nil, nil
+	}
+	var builder strings.Builder
+	expanded := false
+	for rest := s; ; {
+		i := strings.Index(rest, placeholderPrefix)
+		if i < 0 {
+			builder.WriteString(rest)
+			break
+		}
+		if i >= 1 && rest[i-1] == '$' {
+			builder.WriteString(rest[:i])
+			builder.WriteString("{")
+			rest = rest[i+len(placeholderPrefix):]
+			expanded = true
+			continue
+		}
+		j := strings.Index(rest[i:], placeholderSuffix)
+		if j < 0 {
+			builder.WriteString(rest)
+			break
+		}
+		placeholder := rest[i : i+j+len(placeholderSuffix)]
+		var value json.RawMessage
+		var ok bool
+		if k := strings.IndexByte(placeholder, ':'); k >= 0 {
+			kind := placeholder[len(placeholderPrefix):k]
+			argument := placeholder[k+1 : len(placeholder)-len(placeholderSuffix)]
+			var err error
+			value, ok, err = pr.resolvePlaceholder(path, kind, argument)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !ok {
+			builder.WriteString(rest[:i+len(placeholder)])
+			rest = rest[i+len(placeholder):]
+			continue
+		}
+		if placeholder == s {
+			return value, nil
+		}
+		result := gjson.ParseBytes(value)
+		switch result.Type {
+		case gjson.String:
+			builder.WriteString(rest[:i])
+			builder.WriteString(result.Str)
+		case gjson.Number, gjson.True, gjson.False:
+			builder.WriteString(rest[:i])
+			builder.WriteString(result.Raw)
+		default:
+			return nil, fmt.Errorf("configset: non-scalar value interpolated; path=%q placeholder=%q", path, placeholder)
+		}
+		rest = rest[i+len(placeholder):]
+		expanded = true
+	}
+	if !expanded {
+		return nil, nil
+	}
+	return json.Marshal(builder.String())
+}
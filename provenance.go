@@ -0,0 +1,361 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// OriginOf returns the effective origin of the leaf value for the given path,
+// i.e. the layer of the config set which supplied the value, e.g. a file or an
+// environment variable. Values within arrays have the origins of the arrays.
+// If no value can be found by the path, ErrValueNotFound is returned, and if
+// the value is a non-empty object, ErrNotLeafValue is returned, see Explain.
+func OriginOf(path string) (Origin, error) { return cs.Origin(path) }
+
+// ErrNotLeafValue is returned when the value for a path is a non-empty object
+// rather than a leaf value.
+var ErrNotLeafValue = errors.New("configset: not leaf value")
+
+// Explain returns the explanations of the values for the given path, one for
+// each leaf value under the path, sorted by path, which tell which layers of
+// the config set, e.g. files or environment variables, supplied the values, and
+// which ones they overrode. Arrays are leaf values as they are replaced as a
+// whole. An empty path refers to the whole config set.
+// If no value can be found by the path, ErrValueNotFound is returned.
+func Explain(path string) ([]Explanation, error) { return cs.Explain(path) }
+
+// DumpWithProvenance likes DumpFlat but annotates each line with the layers of
+// the config set which supplied the value, the effective one first, e.g.
+//
+//	server.port=8081 # env CONFIGSET.server.port, file /etc/app/server.yaml
+//
+// The values of secrets are masked like DumpRedacted does.
+func DumpWithProvenance() []byte { return cs.DumpWithProvenance() }
+
+// Explanation explains where a value of the config set comes from.
+type Explanation struct {
+	// Path is the path of the value.
+	Path string
+
+	// Origins are the layers which supplied the value, the effective one first,
+	// followed by the ones overridden.
+	Origins []Origin
+}
+
+// Origin represents a layer of the config set which supplied a value.
+type Origin struct {
+	// Layer is the kind of the layer.
+	Layer OriginLayer
+
+	// Name identifies the layer within its kind, e.g. the file path or the name
+	// of the environment variable.
+	Name string
+
+	// Value is the value supplied in form of JSON, before placeholders, encrypted
+	// values and secret references are resolved. The values of secrets, i.e.
+	// the ones for the paths marked by AddSecretPaths and the ones decrypted
+	// or resolved from secret references at load time, including the ones from
+	// SOPS-encrypted files, are masked like DumpRedacted does.
+	Value json.RawMessage
+}
+
+// String returns the layer and the name of the origin, e.g.
+// "file /etc/app/server.yaml".
+func (o Origin) String() string {
+	if o.Name == "" {
+		return string(o.Layer)
+	}
+	return string(o.Layer) + " " + o.Name
+}
+
+// OriginLayer represents a kind of layers of the config set.
+type OriginLayer string
+
+const (
+	// OriginDefaults is the layer of default values registered
+	// programmatically, e.g. with SetDefault.
+	OriginDefaults OriginLayer = "defaults"
+
+	// OriginDefaultsFile is the layer of the files of default values, i.e.
+	// defaults.yaml and the files under the directory _defaults.
+	OriginDefaultsFile OriginLayer = "defaultsFile"
+
+	// OriginFile is the layer of configuration files.
+	OriginFile OriginLayer = "file"
+
+	// OriginSource is the layer of sources added with AddSources.
+	OriginSource OriginLayer = "source"
+
+	// OriginEnv is the layer of environment variables such as
+	// CONFIGSET.{path}={value}.
+	OriginEnv OriginLayer = "env"
+
+	// OriginOverrides is the layer of the file overrides.yaml.
+	OriginOverrides OriginLayer = "overrides"
+
+	// OriginFlag is the layer of command-line flags such as -set {path}={value},
+	// see BindFlags.
+	OriginFlag OriginLayer = "flag"
+
+	// OriginUpdate is the layer of values set at runtime, e.g. with Set, named
+	// after the actor of the transaction, if any.
+	OriginUpdate OriginLayer = "update"
+)
+
+func (cs *ConfigSet) Origin(path string) (Origin, error) {
+	cs.mutex.Lock()
+	provenance := cs.provenance
+	maskedPaths := cs.maskedPaths()
+	cs.mutex.Unlock()
+	origin, err := provenance.lookUpOrigin(path)
+	if err != nil {
+		return Origin{}, err
+	}
+	_, nodePath := provenance.lookUp(path)
+	origin.Value = maskSecrets(nodePath, origin.Value, maskedPaths)
+	return origin, nil
+}
+
+func (cs *ConfigSet) Explain(path string) ([]Explanation, error) {
+	cs.mutex.Lock()
+	provenance := cs.provenance
+	maskedPaths := cs.maskedPaths()
+	cs.mutex.Unlock()
+	node, nodePath := provenance.lookUp(path)
+	if node == nil {
+		return nil, fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	var explanations []Explanation
+	node.walk(nodePath, func(path string, origins []Origin) {
+		explanation := Explanation{
+			Path:    path,
+			Origins: make([]Origin, len(origins)),
+		}
+		for i := range origins {
+			origin := origins[len(origins)-1-i]
+			origin.Value = maskSecrets(path, origin.Value, maskedPaths)
+			explanation.Origins[i] = origin
+		}
+		explanations = append(explanations, explanation)
+	})
+	return explanations, nil
+}
+
+func (cs *ConfigSet) DumpWithProvenance() []byte {
+	raw := cs.redactedRaw()
+	cs.mutex.Lock()
+	provenance := cs.provenance
+	cs.mutex.Unlock()
+	if raw == nil {
+		return nil
+	}
+	var builder strings.Builder
+	provenance.walk("", func(path string, origins []Origin) {
+		builder.WriteString(path)
+		builder.WriteByte('=')
+		builder.WriteString(gjson.GetBytes(raw, path).Raw)
+		builder.WriteString(" #")
+		for i := len(origins) - 1; i >= 0; i-- {
+			if i < len(origins)-1 {
+				builder.WriteByte(',')
+			}
+			builder.WriteByte(' ')
+			builder.WriteString(origins[i].String())
+		}
+		builder.WriteByte('\n')
+	})
+	return []byte(builder.String())
+}
+
+// updateProvenance returns a copy of the given provenance with the given changes
+// of a transaction recorded.
+func updateProvenance(provenance *provenanceNode, changes []txnChange, actor string) *provenanceNode {
+	provenance = provenance.clone()
+	origin := Origin{Layer: OriginUpdate, Name: actor}
+	for _, change := range changes {
+		var keys []string
+		if change.path != "" {
+			keys = splitPath(change.path)
+		}
+		if change.value == nil {
+			provenance.deleteDescendant(keys)
+			continue
+		}
+		provenance.descendant(keys).mergeValue(gjson.ParseBytes(change.value), origin, !change.merge)
+	}
+	return provenance
+}
+
+// provenanceNode is a node of the tree recording the origins of the values of
+// a config set, which mirrors the merges of the layers. Leaf nodes hold the
+// origins, oldest first, whereas the other nodes hold the children. Trees are
+// immutable once stored in the config set.
+type provenanceNode struct {
+	origins  []Origin
+	children map[string]*provenanceNode
+
+	// trace is set, along with path, when tracing merges, see WithMergeTrace.
+	trace *[]MergeStep
+	path  string
+}
+
+// mergeValue records that the given value from the given origin is deep-merged
+// into the value of the node, see mergeJSON. If replace is true, the value
+// replaces the one of the node instead.
+func (pn *provenanceNode) mergeValue(value gjson.Result, origin Origin, replace bool) {
+	if replace {
+		pn.children = nil
+	}
+	if value.IsObject() && (len(value.Map()) >= 1 || len(pn.children) >= 1) {
+		pn.origins = nil
+		value.ForEach(func(key, value gjson.Result) bool {
+			pn.child(key.Str).mergeValue(value, origin, false)
+			return true
+		})
+		return
+	}
+	pn.children = nil
+	origin.Value = json.RawMessage(value.Raw)
+	if pn.trace != nil {
+		mergeStep := MergeStep{Path: pn.path, Origin: origin}
+		if n := len(pn.origins); n >= 1 {
+			mergeStep.OldValue = pn.origins[n-1].Value
+		}
+		*pn.trace = append(*pn.trace, mergeStep)
+	}
+	pn.origins = append(pn.origins, origin)
+}
+
+// patchValue records that the given patch from the given origin is applied to
+// the value of the node, see applyMergePatch.
+func (pn *provenanceNode) patchValue(patch gjson.Result, origin Origin) {
+	if !patch.IsObject() {
+		pn.mergeValue(patch, origin, true)
+		return
+	}
+	pn.origins = nil
+	patch.ForEach(func(key, value gjson.Result) bool {
+		if value.Type == gjson.Null {
+			if _, ok := pn.children[key.Str]; ok && pn.trace != nil {
+				*pn.trace = append(*pn.trace, MergeStep{
+					Path:    joinPath(pn.path, key.Str),
+					Origin:  origin,
+					Deleted: true,
+				})
+			}
+			delete(pn.children, key.Str)
+		} else {
+			pn.child(key.Str).patchValue(value, origin)
+		}
+		return true
+	})
+}
+
+// lookUp returns the node for the given path, or the leaf node on the way, along
+// with the path of the node. If there is no such node, nil is returned.
+func (pn *provenanceNode) lookUp(path string) (*provenanceNode, string) {
+	var keys []string
+	if path != "" {
+		keys = splitPath(path)
+	}
+	node, nodePath := pn, ""
+	for _, key := range keys {
+		if node == nil || len(node.origins) >= 1 {
+			break
+		}
+		node, nodePath = node.children[key], joinPath(nodePath, key)
+	}
+	if node == nil || (len(node.origins) == 0 && len(node.children) == 0) {
+		return nil, ""
+	}
+	return node, nodePath
+}
+
+// lookUpOrigin returns the effective origin of the leaf value for the given
+// path.
+func (pn *provenanceNode) lookUpOrigin(path string) (Origin, error) {
+	node, _ := pn.lookUp(path)
+	if node == nil {
+		return Origin{}, fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	if len(node.origins) == 0 {
+		return Origin{}, fmt.Errorf("%w; path=%q", ErrNotLeafValue, path)
+	}
+	return node.origins[len(node.origins)-1], nil
+}
+
+// descendant returns the descendant node for the given keys, creating nodes as
+// needed. Leaf nodes on the way become objects.
+func (pn *provenanceNode) descendant(keys []string) *provenanceNode {
+	node := pn
+	for _, key := range keys {
+		node.origins = nil
+		node = node.child(key)
+	}
+	return node
+}
+
+// deleteDescendant deletes the descendant node for the given keys, if any.
+func (pn *provenanceNode) deleteDescendant(keys []string) {
+	node := pn
+	for _, key := range keys[:len(keys)-1] {
+		if node = node.children[key]; node == nil {
+			return
+		}
+	}
+	delete(node.children, keys[len(keys)-1])
+}
+
+func (pn *provenanceNode) child(key string) *provenanceNode {
+	child, ok := pn.children[key]
+	if !ok {
+		if pn.children == nil {
+			pn.children = make(map[string]*provenanceNode)
+		}
+		child = new(provenanceNode)
+		if pn.trace != nil {
+			child.trace = pn.trace
+			child.path = joinPath(pn.path, key)
+		}
+		pn.children[key] = child
+	}
+	return child
+}
+
+// walk calls the given function for each leaf node, sorted by path.
+func (pn *provenanceNode) walk(path string, f func(path string, origins []Origin)) {
+	if pn == nil {
+		return
+	}
+	if len(pn.origins) >= 1 {
+		f(path, pn.origins)
+		return
+	}
+	keys := make([]string, 0, len(pn.children))
+	for key := range pn.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		pn.children[key].walk(joinPath(path, key), f)
+	}
+}
+
+func (pn *provenanceNode) clone() *provenanceNode {
+	if pn == nil {
+		return new(provenanceNode)
+	}
+	clone := provenanceNode{origins: pn.origins[:len(pn.origins):len(pn.origins)]}
+	if pn.children != nil {
+		clone.children = make(map[string]*provenanceNode, len(pn.children))
+		for key, child := range pn.children {
+			clone.children[key] = child.clone()
+		}
+	}
+	return &clone
+}
@@ -0,0 +1,125 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Explain(t *testing.T) {
+	var cs ConfigSet
+	_, err := cs.Explain("")
+	assert.ErrorIs(t, err, ErrValueNotFound)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/defaults.yaml", []byte(`
+server:
+  port: 80
+  hosts: [a]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+hosts: [b, c]
+tls: {cert: a.pem}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/overrides.yaml", []byte(`
+server:
+  tls: null
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.SetDefault("server.timeout", "1s")
+	assert.NoError(t, err)
+	cs.AddSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+		return json.RawMessage(`{"server":{"timeout":"2s"}}`), nil
+	}))
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+
+	explanations, err := cs.Explain("server.port")
+	assert.NoError(t, err)
+	assert.Equal(t, []Explanation{
+		{
+			Path: "server.port",
+			Origins: []Origin{
+				{Layer: OriginEnv, Name: "CONFIGSET.server.port", Value: json.RawMessage(`8081`)},
+				{Layer: OriginFile, Name: "/my_etc/server.yaml", Value: json.RawMessage(`8080`)},
+				{Layer: OriginDefaultsFile, Name: "/my_etc/defaults.yaml", Value: json.RawMessage(`80`)},
+			},
+		},
+	}, explanations)
+	explanations, err = cs.Explain("server.hosts.1")
+	assert.NoError(t, err)
+	if assert.Len(t, explanations, 1) {
+		assert.Equal(t, "server.hosts", explanations[0].Path)
+		assert.Equal(t, `["b","c"]`, string(explanations[0].Origins[0].Value))
+	}
+	_, err = cs.Explain("server.tls")
+	assert.EqualError(t, err, `configset: value not found; path="server.tls"`)
+
+	tx := func(tx *Txn) error {
+		tx.SetActor("alice")
+		return tx.Set("server.port", 9090)
+	}
+	err = cs.Update(tx)
+	assert.NoError(t, err)
+	explanations, err = cs.Explain("server.port")
+	assert.NoError(t, err)
+	if assert.Len(t, explanations, 1) && assert.Len(t, explanations[0].Origins, 4) {
+		assert.Equal(t, Origin{Layer: OriginUpdate, Name: "alice", Value: json.RawMessage(`9090`)}, explanations[0].Origins[0])
+	}
+
+	assert.Equal(t, `server.hosts=["b","c"] # file /my_etc/server.yaml, defaultsFile /my_etc/defaults.yaml
+server.port=9090 # update alice, env CONFIGSET.server.port, file /my_etc/server.yaml, defaultsFile /my_etc/defaults.yaml
+server.timeout="2s" # source 0 (configset_test.sourceFunc), defaults
+`, string(cs.DumpWithProvenance()))
+	cs.AddSecretPaths("server.port")
+	assert.Contains(t, string(cs.DumpWithProvenance()), `server.port="******" # update alice,`)
+
+	err = cs.Rollback(1)
+	assert.NoError(t, err)
+	explanations, err = cs.Explain("server.port")
+	assert.NoError(t, err)
+	if assert.Len(t, explanations, 1) {
+		assert.Equal(t, OriginEnv, explanations[0].Origins[0].Layer)
+	}
+}
+
+func TestConfigSet_Origin(t *testing.T) {
+	var cs ConfigSet
+	_, err := cs.Origin("server.port")
+	assert.ErrorIs(t, err, ErrValueNotFound)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+hosts: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+
+	origin, err := cs.Origin("server.port")
+	assert.NoError(t, err)
+	assert.Equal(t, Origin{Layer: OriginEnv, Name: "CONFIGSET.server.port", Value: json.RawMessage(`8081`)}, origin)
+	origin, err = cs.Origin("server.hosts.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "file /my_etc/server.yaml", origin.String())
+	_, err = cs.Origin("server")
+	assert.EqualError(t, err, `configset: not leaf value; path="server"`)
+	_, err = cs.Origin("server.timeout")
+	assert.ErrorIs(t, err, ErrValueNotFound)
+}
+
+type sourceFunc func(ctx context.Context) (json.RawMessage, error)
+
+func (sf sourceFunc) Fetch(ctx context.Context) (json.RawMessage, error) { return sf(ctx) }
@@ -0,0 +1,39 @@
+package configset
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Register registers the given config set under the given name, so that large
+// applications with several independent config sets, e.g. app configs, tenant
+// configs and feature flags, can share them across packages with Named rather
+// than passing pointers around. Like sql.Register, Register panics if the name
+// is already registered or the config set is nil.
+func Register(name string, configSet *ConfigSet) {
+	if configSet == nil {
+		panic(fmt.Sprintf("configset: nil config set; name=%q", name))
+	}
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	if _, ok := registry.configSets[name]; ok {
+		panic(fmt.Sprintf("configset: config set already registered; name=%q", name))
+	}
+	if registry.configSets == nil {
+		registry.configSets = make(map[string]*ConfigSet)
+	}
+	registry.configSets[name] = configSet
+}
+
+// Named returns the config set registered under the given name with Register,
+// or nil if there is no such config set.
+func Named(name string) *ConfigSet {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	return registry.configSets[name]
+}
+
+var registry struct {
+	mutex      sync.Mutex
+	configSets map[string]*ConfigSet
+}
@@ -0,0 +1,22 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	assert.Nil(t, Named("test.flags"))
+	cs := New()
+	Register("test.flags", cs)
+	assert.Same(t, cs, Named("test.flags"))
+	assert.PanicsWithValue(t, `configset: config set already registered; name="test.flags"`, func() {
+		Register("test.flags", New())
+	})
+	assert.PanicsWithValue(t, `configset: nil config set; name="test.tenants"`, func() {
+		Register("test.tenants", nil)
+	})
+	assert.Nil(t, Named("test.tenants"))
+}
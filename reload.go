@@ -0,0 +1,21 @@
+package configset
+
+// WithReloadErrorHandler returns an option that sets the handler for failures
+// of reloads done in the background, e.g. by Watch, Poll and BindAndWatch.
+// When a reload fails, due to invalid configuration files or validation errors
+// for example, the config set keeps the last good state and the handler is
+// called with the error, which can be logged or counted as a metric.
+func WithReloadErrorHandler(reloadErrorHandler func(err error)) Option {
+	return func(options *options) { options.reloadErrorHandler = reloadErrorHandler }
+}
+
+func (cs *ConfigSet) reportReloadError(err error) {
+	cs.mutex.Lock()
+	reloadErrorHandler := cs.options.reloadErrorHandler
+	logger := cs.logger()
+	cs.mutex.Unlock()
+	logger.Warn("configset: reload failed", "err", err)
+	if reloadErrorHandler != nil {
+		reloadErrorHandler(err)
+	}
+}
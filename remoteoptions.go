@@ -0,0 +1,110 @@
+package configset
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RemoteOptions represents the options of transport security and
+// authentication shared by remote sources, so that they can be configured in
+// one place, e.g.
+//
+//	client, err := remoteOptions.NewHTTPClient()
+//	if err != nil {
+//		return err
+//	}
+//	configset.AddSources(configset.NewHTTPSource(url, client))
+type RemoteOptions struct {
+	// CABundle is the bundle of CA certificates in PEM format to verify the
+	// certificates of servers with. If empty, the system pool is used.
+	CABundle []byte
+
+	// ClientCertificate and ClientKey are the client certificate and key in
+	// PEM format for mTLS, if any.
+	ClientCertificate []byte
+	ClientKey         []byte
+
+	// TokenProvider provides the bearer token sent in the Authorization header
+	// of each request, if any. It is called for each request, so that tokens
+	// can be refreshed.
+	TokenProvider func(ctx context.Context) (token string, err error)
+
+	// Proxy returns the URL of the proxy for each request, if any. If nil, the
+	// proxy is taken from the environment variables HTTPS_PROXY, HTTP_PROXY and
+	// NO_PROXY.
+	Proxy func(request *http.Request) (*url.URL, error)
+
+	// Timeout is the time limit of each request. If zero, there is no time
+	// limit other than the one of the context.
+	Timeout time.Duration
+}
+
+// NewHTTPClient creates a HTTP client with the options.
+func (ro *RemoteOptions) NewHTTPClient() (*http.Client, error) {
+	tlsConfig, err := ro.newTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if ro.Proxy != nil {
+		transport.Proxy = ro.Proxy
+	}
+	var roundTripper http.RoundTripper = transport
+	if ro.TokenProvider != nil {
+		roundTripper = &bearerTokenRoundTripper{
+			base:          transport,
+			tokenProvider: ro.TokenProvider,
+		}
+	}
+	return &http.Client{
+		Transport: roundTripper,
+		Timeout:   ro.Timeout,
+	}, nil
+}
+
+func (ro *RemoteOptions) newTLSConfig() (*tls.Config, error) {
+	tlsConfig := tls.Config{MinVersion: tls.VersionTLS12}
+	if len(ro.CABundle) >= 1 {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(ro.CABundle) {
+			return nil, errors.New("configset: no valid ca certificate")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+	if len(ro.ClientCertificate)+len(ro.ClientKey) >= 1 {
+		certificate, err := tls.X509KeyPair(ro.ClientCertificate, ro.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return &tlsConfig, nil
+}
+
+type bearerTokenRoundTripper struct {
+	base          http.RoundTripper
+	tokenProvider func(ctx context.Context) (string, error)
+}
+
+var _ http.RoundTripper = (*bearerTokenRoundTripper)(nil)
+
+// RoundTrip implements http.RoundTripper.RoundTrip.
+func (btrt *bearerTokenRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := btrt.tokenProvider(request.Context())
+	if err != nil {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+		return nil, fmt.Errorf("provide token: %w", err)
+	}
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "Bearer "+token)
+	return btrt.base.RoundTrip(request)
+}
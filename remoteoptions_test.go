@@ -0,0 +1,54 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteOptions_NewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer my_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("server:\n  port: 8081\n"))
+	}))
+	defer server.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	remoteOptions := RemoteOptions{
+		CABundle:      caBundle,
+		TokenProvider: func(context.Context) (string, error) { return "my_token", nil },
+	}
+	client, err := remoteOptions.NewHTTPClient()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var cs ConfigSet
+	cs.AddSources(NewHTTPSource(server.URL, client))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8081}}`, string(cs.Dump("", "")))
+
+	var cs2 ConfigSet
+	cs2.AddSources(NewHTTPSource(server.URL, nil))
+	err = cs2.Load(fs, "/my_etc", nil)
+	assert.Error(t, err)
+
+	remoteOptions = RemoteOptions{CABundle: []byte("foo")}
+	_, err = remoteOptions.NewHTTPClient()
+	assert.EqualError(t, err, "configset: no valid ca certificate")
+}
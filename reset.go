@@ -0,0 +1,16 @@
+package configset
+
+// Reset closes the package-level config set, see Close, and replaces it with a
+// new one, and unregisters all config sets registered with Register, so that
+// integration tests and long-lived tools can reinitialize the package-level
+// state cleanly. The config sets registered are not closed, as they are owned
+// by the callers. Reset must not be called concurrently with other
+// package-level functions.
+func Reset() error {
+	err := cs.Close()
+	cs = new(ConfigSet)
+	registry.mutex.Lock()
+	registry.configSets = nil
+	registry.mutex.Unlock()
+	return err
+}
@@ -0,0 +1,35 @@
+package configset_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-tk/configset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReset(t *testing.T) {
+	dirPath := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dirPath, "server.yaml"), []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := configset.Load(dirPath)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, configset.IsLoaded())
+	var cs configset.ConfigSet
+	configset.Register("reset", &cs)
+
+	err = configset.Reset()
+	assert.NoError(t, err)
+	assert.False(t, configset.IsLoaded())
+	assert.Nil(t, configset.Named("reset"))
+	configset.Register("reset", &cs)
+	assert.Same(t, &cs, configset.Named("reset"))
+	err = configset.Reset()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,195 @@
+package configset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// WithRoundTrip returns an option that makes loads keep the original YAML of
+// configuration files, so that Save preserves comments and key order of the
+// files, updating only the values changed, which matters if humans keep editing
+// the same files.
+func WithRoundTrip() Option {
+	return func(options *options) { options.roundTrip = true }
+}
+
+// readYAMLFiles reads the *.yaml files under the given directory, keyed by
+// config names.
+func readYAMLFiles(fs afero.Fs, dirPath string) (map[string][]byte, error) {
+	fileInfoSet, err := afero.ReadDir(fs, dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dir; dirPath=%q: %w", dirPath, err)
+	}
+	yamlFiles := make(map[string][]byte)
+	for _, fileInfo := range fileInfoSet {
+		if fileInfo.IsDir() {
+			continue
+		}
+		fileName := fileInfo.Name()
+		configName := strings.TrimSuffix(fileName, ".yaml")
+		if len(configName) == len(fileName) || configName == defaultsConfigName || configName == overridesConfigName || configName == sharedConfigName {
+			continue
+		}
+		filePath := filepath.Join(dirPath, fileName)
+		data, err := afero.ReadFile(fs, filePath)
+		if err != nil {
+			return nil, fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+		}
+		if rawConfig, err := yaml.YAMLToJSON(data); err == nil && isSOPSFile(rawConfig) {
+			continue
+		}
+		yamlFiles[configName] = data
+	}
+	return yamlFiles, nil
+}
+
+// roundTripYAML updates the given original YAML with the given value in form of
+// JSON, preserving comments and key order of the original YAML.
+func roundTripYAML(yamlFile []byte, value gjson.Result) ([]byte, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(yamlFile, &document); err != nil {
+		return nil, fmt.Errorf("unmarshal from yaml: %w", err)
+	}
+	if len(document.Content) == 0 {
+		node, err := newYAMLNode(value)
+		if err != nil {
+			return nil, err
+		}
+		document = yamlv3.Node{
+			Kind:    yamlv3.DocumentNode,
+			Content: []*yamlv3.Node{node},
+		}
+	} else {
+		node, err := updateYAMLNode(document.Content[0], value)
+		if err != nil {
+			return nil, err
+		}
+		document.Content[0] = node
+	}
+	var buffer bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, fmt.Errorf("marshal to yaml: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("marshal to yaml: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// updateYAMLNode returns the given node updated with the given value. Mappings
+// and sequences are updated in place, and scalars are replaced only if their
+// values differ.
+func updateYAMLNode(node *yamlv3.Node, value gjson.Result) (*yamlv3.Node, error) {
+	switch {
+	case node.Kind == yamlv3.MappingNode && value.IsObject():
+		content := make([]*yamlv3.Node, 0, len(node.Content))
+		keys := make(map[string]struct{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			childValue := value.Get(joinPath("", keyNode.Value))
+			if !childValue.Exists() {
+				continue
+			}
+			valueNode, err := updateYAMLNode(valueNode, childValue)
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, keyNode, valueNode)
+			keys[keyNode.Value] = struct{}{}
+		}
+		var err error
+		value.ForEach(func(key, childValue gjson.Result) bool {
+			if _, ok := keys[key.Str]; ok {
+				return true
+			}
+			var valueNode *yamlv3.Node
+			valueNode, err = newYAMLNode(childValue)
+			if err != nil {
+				return false
+			}
+			keyNode := &yamlv3.Node{Kind: yamlv3.ScalarNode}
+			keyNode.SetString(key.Str)
+			content = append(content, keyNode, valueNode)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		node.Content = content
+		return node, nil
+	case node.Kind == yamlv3.SequenceNode && value.IsArray():
+		elementValues := value.Array()
+		content := make([]*yamlv3.Node, 0, len(elementValues))
+		for i, elementValue := range elementValues {
+			var elementNode *yamlv3.Node
+			var err error
+			if i < len(node.Content) {
+				elementNode, err = updateYAMLNode(node.Content[i], elementValue)
+			} else {
+				elementNode, err = newYAMLNode(elementValue)
+			}
+			if err != nil {
+				return nil, err
+			}
+			content = append(content, elementNode)
+		}
+		node.Content = content
+		return node, nil
+	}
+	var oldValue interface{}
+	if err := node.Decode(&oldValue); err == nil && jsonEqual(oldValue, value) {
+		return node, nil
+	}
+	newNode, err := newYAMLNode(value)
+	if err != nil {
+		return nil, err
+	}
+	newNode.HeadComment = node.HeadComment
+	newNode.LineComment = node.LineComment
+	newNode.FootComment = node.FootComment
+	return newNode, nil
+}
+
+// jsonEqual reports whether the given value decoded from YAML equals the given
+// JSON value.
+func jsonEqual(yamlValue interface{}, jsonValue gjson.Result) bool {
+	data, err := json.Marshal(yamlValue)
+	if err != nil {
+		return false
+	}
+	var x, y interface{}
+	if json.Unmarshal(data, &x) != nil || json.Unmarshal([]byte(jsonValue.Raw), &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// newYAMLNode creates a node in block style for the given value.
+func newYAMLNode(value gjson.Result) (*yamlv3.Node, error) {
+	var document yamlv3.Node
+	// JSON values are valid YAML values in flow style.
+	if err := yamlv3.Unmarshal([]byte(value.Raw), &document); err != nil {
+		return nil, fmt.Errorf("unmarshal from yaml: %w", err)
+	}
+	node := document.Content[0]
+	resetYAMLStyle(node)
+	return node, nil
+}
+
+func resetYAMLStyle(node *yamlv3.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
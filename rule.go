@@ -0,0 +1,161 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/tidwall/gjson"
+)
+
+// AddRules adds the given rules to the config set. The rules are evaluated at
+// every load, and ErrRuleViolation is returned if any rule is violated.
+func AddRules(rules ...Rule) { cs.AddRules(rules...) }
+
+// Rule represents a constraint on a value of the config set.
+type Rule struct {
+	path   string
+	checks []Check
+}
+
+// Check checks a value of the config set.
+// The given value may not exist, which can be told by value.Exists().
+type Check func(value gjson.Result) error
+
+// Assert returns a rule that the value for the given path should pass all the
+// given checks.
+func Assert(path string, checks ...Check) Rule {
+	return Rule{
+		path:   path,
+		checks: checks,
+	}
+}
+
+// OneOf returns a rule that the value for the given path, if exists, should be
+// equal to one of the given values.
+func OneOf(path string, values ...interface{}) Rule { return Assert(path, In(values...)) }
+
+// Required returns a check that the value should exist.
+func Required() Check {
+	return func(value gjson.Result) error {
+		if !value.Exists() {
+			return errors.New("value is required")
+		}
+		return nil
+	}
+}
+
+// NotEmpty returns a check that the value, if exists, should not be null, an
+// empty string, an empty array or an empty object.
+func NotEmpty() Check {
+	return func(value gjson.Result) error {
+		if !value.Exists() {
+			return nil
+		}
+		switch {
+		case value.Type == gjson.Null,
+			value.Type == gjson.String && value.Str == "",
+			value.IsArray() && len(value.Array()) == 0,
+			value.IsObject() && len(value.Map()) == 0:
+			return fmt.Errorf("value %s is empty", value.Raw)
+		}
+		return nil
+	}
+}
+
+// Between returns a check that the value, if exists, should be a number
+// within the range [min, max].
+func Between(min, max float64) Check {
+	return func(value gjson.Result) error {
+		if !value.Exists() {
+			return nil
+		}
+		if value.Type != gjson.Number {
+			return fmt.Errorf("value %s is not a number", value.Raw)
+		}
+		if value.Num < min || value.Num > max {
+			return fmt.Errorf("value %s is not between %v and %v", value.Raw, min, max)
+		}
+		return nil
+	}
+}
+
+// In returns a check that the value, if exists, should be equal to one of the
+// given values. Values are compared in form of JSON.
+func In(values ...interface{}) Check {
+	candidates := make([]interface{}, len(values))
+	for i, value := range values {
+		candidates[i] = normalizeValue(value)
+	}
+	return func(value gjson.Result) error {
+		if !value.Exists() {
+			return nil
+		}
+		x := value.Value()
+		for _, candidate := range candidates {
+			if reflect.DeepEqual(x, candidate) {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %s is not one of %v", value.Raw, values)
+	}
+}
+
+func normalizeValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("marshal to json: %v", err))
+	}
+	return gjson.ParseBytes(data).Value()
+}
+
+// Matches returns a check that the value, if exists, should be a string
+// matching the given regular expression.
+func Matches(pattern string) Check {
+	re := regexp.MustCompile(pattern)
+	return func(value gjson.Result) error {
+		if !value.Exists() {
+			return nil
+		}
+		if value.Type != gjson.String {
+			return fmt.Errorf("value %s is not a string", value.Raw)
+		}
+		if !re.MatchString(value.Str) {
+			return fmt.Errorf("value %s does not match %q", value.Raw, pattern)
+		}
+		return nil
+	}
+}
+
+func (cs *ConfigSet) AddRules(rules ...Rule) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.rules = append(cs.rules, rules...)
+}
+
+type ruleViolation struct {
+	path string
+	err  error
+}
+
+func checkRules(rawConfigSet json.RawMessage, rules []Rule) []ruleViolation {
+	var ruleViolations []ruleViolation
+	for _, rule := range rules {
+		value := gjson.GetBytes(rawConfigSet, rule.path)
+		for _, check := range rule.checks {
+			if err := check(value); err != nil {
+				ruleViolations = append(ruleViolations, ruleViolation{
+					path: rule.path,
+					err:  err,
+				})
+				break
+			}
+		}
+	}
+	return ruleViolations
+}
+
+// ErrRuleViolation is returned when a rule is violated.
+var ErrRuleViolation = errors.New("configset: rule violation")
@@ -0,0 +1,104 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/go-tk/testcase"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_AddRules(t *testing.T) {
+	type C struct {
+		rules          []Rule
+		expectedErrStr string
+		expectedErr    error
+	}
+	tc := testcase.New(func(t *testing.T, c *C) {
+		t.Parallel()
+
+		var cs ConfigSet
+		fs := afero.NewMemMapFs()
+		if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+host: localhost
+`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := afero.WriteFile(fs, "/my_etc/log.yaml", []byte(`
+level: info
+`), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		testcase.DoCallback(0, t, c)
+
+		cs.AddRules(c.rules...)
+		err := cs.Load(fs, "/my_etc", nil)
+		if c.expectedErrStr != "" {
+			assert.EqualError(t, err, c.expectedErrStr)
+			if c.expectedErr != nil {
+				assert.ErrorIs(t, err, c.expectedErr)
+			}
+			return
+		}
+		assert.NoError(t, err)
+	})
+
+	// satisfied rules
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.rules = []Rule{
+				Assert("server.port", Required(), Between(1, 65535)),
+				Assert("server.host", NotEmpty(), Matches(`^[a-z]+$`)),
+				Assert("server.timeout", Between(1, 60)),
+				OneOf("log.level", "debug", "info", "warn", "error"),
+			}
+		}).
+		Run(t)
+
+	// required value missing
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.rules = []Rule{
+				Assert("server.timeout", Required()),
+			}
+			c.expectedErrStr = `configset: rule violation; path="server.timeout": value is required`
+			c.expectedErr = ErrRuleViolation
+		}).
+		Run(t)
+
+	// value out of range
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.rules = []Rule{
+				Assert("server.port", Between(1, 1024)),
+			}
+			c.expectedErrStr = `configset: rule violation; path="server.port" origin="file /my_etc/server.yaml": value 8080 is not between 1 and 1024`
+			c.expectedErr = ErrRuleViolation
+		}).
+		Run(t)
+
+	// value not in candidates
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.rules = []Rule{
+				OneOf("log.level", "warn", "error"),
+			}
+			c.expectedErrStr = `configset: rule violation; path="log.level" origin="file /my_etc/log.yaml": value "info" is not one of [warn error]`
+			c.expectedErr = ErrRuleViolation
+		}).
+		Run(t)
+
+	// value not matching pattern
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.rules = []Rule{
+				Assert("server.port", Matches(`^\d+$`)),
+			}
+			c.expectedErrStr = `configset: rule violation; path="server.port" origin="file /my_etc/server.yaml": value 8080 is not a string`
+			c.expectedErr = ErrRuleViolation
+		}).
+		Run(t)
+}
@@ -0,0 +1,101 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/yaml"
+)
+
+// Save saves the config set into the given directory, one {name}.yaml file for
+// each top-level value, so that tools can load, migrate or patch, and persist
+// configuration. The files contain the effective values, i.e. with default
+// values, configs from sources, environment variables and values set applied,
+// and existing files of the same names are overwritten.
+// If the option WithRoundTrip is set, comments and key order of the files the
+// config set has been loaded from are preserved.
+// Values decrypted or resolved from secret references at load time are saved in
+// their original forms, i.e. ENC[...] or secretref://..., unless changed since.
+// Values of SOPS-encrypted files can't be saved, nor can SOPS-encrypted files be
+// overwritten, both of which are refused with ErrSOPSFile.
+// If a top-level key can't be used as a file name within the directory, e.g.
+// "../etc/passwd", ErrInvalidConfigName is returned and no file is written.
+func Save(fs afero.Fs, dirPath string) error { return cs.Save(fs, dirPath) }
+
+// ErrInvalidConfigName is returned when a top-level key of the config set to
+// save can't be used as a file name within the directory.
+var ErrInvalidConfigName = errors.New("configset: invalid config name")
+
+func (cs *ConfigSet) Save(fs afero.Fs, dirPath string) error {
+	cs.mutex.Lock()
+	raw := cs.Snapshot().raw
+	yamlFiles := cs.yamlFiles
+	secretValues := cs.allSecretValues()
+	cs.mutex.Unlock()
+	if raw == nil {
+		return ErrNotLoaded
+	}
+	var err error
+	gjson.ParseBytes(raw).ForEach(func(key, _ gjson.Result) bool {
+		err = checkConfigName(dirPath, key.Str)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(dirPath, 0755); err != nil {
+		return fmt.Errorf("make dir; dirPath=%q: %w", dirPath, err)
+	}
+	gjson.ParseBytes(raw).ForEach(func(key, value gjson.Result) bool {
+		filePath := filepath.Join(dirPath, key.Str+".yaml")
+		if err = checkSOPSFile(fs, filePath); err != nil {
+			return false
+		}
+		rawValue, sopsPaths := sealSecrets([]string{key.Str}, json.RawMessage(value.Raw), secretValues)
+		if len(sopsPaths) >= 1 {
+			err = fmt.Errorf("%w; path=%q", ErrSOPSFile, sopsPaths[0])
+			return false
+		}
+		value = gjson.ParseBytes(rawValue)
+		var data []byte
+		if yamlFile, ok := yamlFiles[key.Str]; ok {
+			data, err = roundTripYAML(yamlFile, value)
+			if err != nil {
+				err = fmt.Errorf("round trip yaml; configName=%q: %w", key.Str, err)
+				return false
+			}
+		} else {
+			data, err = yaml.JSONToYAML([]byte(value.Raw))
+			if err != nil {
+				err = fmt.Errorf("convert json to yaml; configName=%q: %w", key.Str, err)
+				return false
+			}
+		}
+		if err = afero.WriteFile(fs, filePath, data, 0644); err != nil {
+			err = fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// checkConfigName checks whether the file for the given config name resides
+// directly in the given directory.
+func checkConfigName(dirPath string, configName string) error {
+	if configName == "" || configName == "." || strings.Contains(configName, "..") ||
+		strings.ContainsAny(configName, "/\\"+string(filepath.Separator)) {
+		return fmt.Errorf("%w; configName=%q", ErrInvalidConfigName, configName)
+	}
+	dirPath = filepath.Clean(dirPath)
+	filePath := filepath.Clean(filepath.Join(dirPath, configName+".yaml"))
+	if filepath.Dir(filePath) != dirPath {
+		return fmt.Errorf("%w; configName=%q", ErrInvalidConfigName, configName)
+	}
+	return nil
+}
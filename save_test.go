@@ -0,0 +1,178 @@
+package configset_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Save(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	err := cs.Save(fs, "/my_etc2")
+	assert.EqualError(t, err, "configset: config set not loaded")
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/debug.yaml", []byte(`
+false
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Save(fs, "/my_etc2")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	data, err := afero.ReadFile(fs, "/my_etc2/server.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "host: localhost\nport: 8080\n", string(data))
+	data, err = afero.ReadFile(fs, "/my_etc2/debug.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "false\n", string(data))
+
+	var cs2 ConfigSet
+	err = cs2.Load(fs, "/my_etc2", nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(cs.Dump("", "")), string(cs2.Dump("", "")))
+}
+
+func TestConfigSet_Save_RoundTrip(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithRoundTrip())
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`# Server settings.
+port: 8080 # The port to listen on.
+# The host name.
+host: "localhost"
+tags:
+- a
+- b
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Set("server.port", 8081)
+	assert.NoError(t, err)
+	err = cs.Set("server.tags.2", "c")
+	assert.NoError(t, err)
+	err = cs.Set("server.timeout", map[string]int{"read": 5})
+	assert.NoError(t, err)
+	err = cs.Save(fs, "/my_etc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	data, err := afero.ReadFile(fs, "/my_etc/server.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `# Server settings.
+port: 8081 # The port to listen on.
+# The host name.
+host: "localhost"
+tags:
+- a
+- b
+- c
+timeout:
+  read: 5
+`, string(data))
+}
+
+func TestConfigSet_Save_secrets(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithDecryptor(base64Decryptor{}), WithRoundTrip())
+	cs.RegisterSecretProvider("vault", mapSecretProvider{"db#token": "abc"})
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: ENC[dGVzdA==] # The password.
+token: secretref://vault/db#token
+replicas:
+- password: ENC[YWRtaW4=]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"db":{"password":"test","replicas":[{"password":"admin"}],"token":"abc","user":"root"}}`, string(cs.Dump("", "")))
+	err = cs.Set("db.replicas.0.password", "changed")
+	assert.NoError(t, err)
+	err = cs.Save(fs, "/my_etc")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	data, err := afero.ReadFile(fs, "/my_etc/db.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `user: root
+password: ENC[dGVzdA==] # The password.
+token: secretref://vault/db#token
+replicas:
+- password: changed
+`, string(data))
+}
+
+func TestConfigSet_Save_SOPS(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithSOPSDecryptor(func([]byte, string) ([]byte, error) {
+		return []byte("password: test\n"), nil
+	}))
+	fs := afero.NewMemMapFs()
+	sopsData := []byte(`
+password: ENC[AES256_GCM,data:dGVzdA==,iv:aXY=,tag:dGFn,type:str]
+sops:
+    mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+`)
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", sopsData, 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Save(fs, "/my_etc")
+	assert.ErrorIs(t, err, ErrSOPSFile)
+	assert.EqualError(t, err, `configset: sops file; filePath="/my_etc/db.yaml"`)
+	err = cs.Save(fs, "/my_etc2")
+	assert.EqualError(t, err, `configset: sops file; path="db.password"`)
+	exists, err := afero.Exists(fs, "/my_etc2/db.yaml")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	data, err := afero.ReadFile(fs, "/my_etc/db.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, sopsData, data)
+
+	err = cs.Set("db.password", "changed")
+	assert.NoError(t, err)
+	err = cs.Save(fs, "/my_etc2")
+	assert.NoError(t, err)
+}
+
+func TestConfigSet_Save_invalidConfigName(t *testing.T) {
+	for _, configName := range []string{"../etc", "etc/passwd", `etc\passwd`, "..", "a..b", ""} {
+		var cs ConfigSet
+		err := cs.MergeAt("", []byte(fmt.Sprintf(`{"server": {"port": 8080}, %q: {"x": 1}}`, configName)))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		fs := afero.NewMemMapFs()
+		err = cs.Save(fs, "/my_etc")
+		assert.ErrorIs(t, err, ErrInvalidConfigName)
+		assert.EqualError(t, err, fmt.Sprintf("configset: invalid config name; configName=%q", configName))
+		exists, err := afero.Exists(fs, "/my_etc/server.yaml")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
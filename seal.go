@@ -0,0 +1,103 @@
+package configset
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// secretValue represents a value of the config set decrypted or resolved at load
+// time, along with its original form in the configuration files in form of
+// JSON, e.g. "ENC[...]", which is nil for the values of SOPS-encrypted files.
+type secretValue struct {
+	path     string
+	value    json.RawMessage
+	original json.RawMessage
+}
+
+// appendSOPSSecretValues appends the leaf values of the given config decrypted
+// from a SOPS-encrypted file, with the paths prefixed by the given path.
+func appendSOPSSecretValues(secretValues []secretValue, path string, value gjson.Result) []secretValue {
+	n := 0
+	if value.IsObject() || value.IsArray() {
+		value.ForEach(func(key, childValue gjson.Result) bool {
+			childKey := key.Str
+			if value.IsArray() {
+				childKey = strconv.Itoa(n)
+			}
+			secretValues = appendSOPSSecretValues(secretValues, joinPath(path, childKey), childValue)
+			n++
+			return true
+		})
+	}
+	if n == 0 {
+		secretValues = append(secretValues, secretValue{path: path, value: json.RawMessage(value.Raw)})
+	}
+	return secretValues
+}
+
+// allSecretValues returns the values of the config set decrypted or resolved
+// from secret references at load time.
+func (cs *ConfigSet) allSecretValues() []secretValue {
+	secretValues := append([]secretValue(nil), cs.secretValues...)
+	for _, secretRef := range cs.secretRefs {
+		secretValues = append(secretValues, secretValue{
+			path:     secretRef.path,
+			value:    secretRef.value,
+			original: secretRef.original,
+		})
+	}
+	return secretValues
+}
+
+// sealSecrets returns the given value for the path of the given keys with the
+// secret values, which are unchanged since loaded, replaced with their original
+// forms, so that secrets are not written back to files in plaintext. Secret
+// values without original forms, i.e. from SOPS-encrypted files, are deleted
+// instead, and their paths are returned.
+func sealSecrets(keys []string, value json.RawMessage, secretValues []secretValue) (json.RawMessage, []string) {
+	var sopsPaths []string
+	for _, secretValue := range secretValues {
+		secretKeys := splitPath(secretValue.path)
+		if len(secretKeys) < len(keys) || joinKeys(secretKeys[:len(keys)]) != joinKeys(keys) {
+			continue
+		}
+		subpath := joinKeys(secretKeys[len(keys):])
+		var currentValue gjson.Result
+		if subpath == "" {
+			currentValue = gjson.ParseBytes(value)
+		} else {
+			currentValue = gjson.GetBytes(value, subpath)
+		}
+		if !currentValue.Exists() || !jsonValueEqual(currentValue, gjson.ParseBytes(secretValue.value)) {
+			continue
+		}
+		if secretValue.original == nil {
+			sopsPaths = append(sopsPaths, secretValue.path)
+			if subpath == "" {
+				value = nil
+			} else {
+				// sjson.DeleteBytes doesn't modify the given JSON in place.
+				value, _ = sjson.DeleteBytes(value, subpath)
+			}
+			continue
+		}
+		if subpath == "" {
+			value = secretValue.original
+		} else {
+			value, _ = sjson.SetRawBytes(value, subpath, secretValue.original)
+		}
+	}
+	return value, sopsPaths
+}
+
+// jsonValueEqual reports whether the given JSON values are equal, comparing
+// strings by their contents rather than their escaped forms.
+func jsonValueEqual(x, y gjson.Result) bool {
+	if x.Type == gjson.String || y.Type == gjson.String {
+		return x.Type == y.Type && x.Str == y.Str
+	}
+	return string(canonicalizeJSON(json.RawMessage(x.Raw))) == string(canonicalizeJSON(json.RawMessage(y.Raw)))
+}
@@ -0,0 +1,104 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+)
+
+// AddSecretPaths marks the values for the given paths, along with the values
+// within them, as secrets.
+func AddSecretPaths(paths ...string) { cs.AddSecretPaths(paths...) }
+
+// SecretFilePolicy represents the way loads treat insecure configuration files
+// containing secrets, which are world-readable or not owned by the user of the
+// process.
+type SecretFilePolicy int
+
+const (
+	// SecretFileIgnore ignores insecure files. This is the default.
+	SecretFileIgnore SecretFilePolicy = iota
+
+	// SecretFileWarn reports insecure files, with ErrInsecureSecretFile, to the
+	// handler set by WithWarningHandler.
+	SecretFileWarn
+
+	// SecretFileRefuse makes loads fail with ErrInsecureSecretFile on insecure
+	// files.
+	SecretFileRefuse
+)
+
+// WithSecretFilePolicy returns an option that sets the way loads treat insecure
+// configuration files containing values for the paths marked by AddSecretPaths,
+// which catches common deployment mistakes. SOPS-encrypted files are not
+// checked, and ownership is not checked on Windows.
+func WithSecretFilePolicy(secretFilePolicy SecretFilePolicy) Option {
+	return func(options *options) { options.secretFilePolicy = secretFilePolicy }
+}
+
+// WithWarningHandler returns an option that sets the handler for warnings,
+// which are problems not failing loads.
+func WithWarningHandler(warningHandler func(warning error)) Option {
+	return func(options *options) { options.warningHandler = warningHandler }
+}
+
+// ErrInsecureSecretFile is returned or reported when a configuration file
+// containing secrets is world-readable or not owned by the user of the process.
+var ErrInsecureSecretFile = errors.New("configset: insecure secret file")
+
+func (cs *ConfigSet) AddSecretPaths(paths ...string) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.secretPaths = append(cs.secretPaths, paths...)
+}
+
+// checkSecretFile checks the given file of the given config if the file
+// contains secrets.
+func (cs *ConfigSet) checkSecretFile(fs afero.Fs, filePath string, configName string, rawConfig json.RawMessage) error {
+	if cs.options.secretFilePolicy == SecretFileIgnore || !cs.containsSecrets(configName, rawConfig) {
+		return nil
+	}
+	fileInfo, err := fs.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("stat file; filePath=%q: %w", filePath, err)
+	}
+	var problem error
+	if fileMode := fileInfo.Mode().Perm(); fileMode&0004 != 0 {
+		problem = fmt.Errorf("%w; filePath=%q fileMode=%q: world-readable", ErrInsecureSecretFile, filePath, fileMode)
+	} else if err := checkFileOwner(fileInfo); err != nil {
+		problem = fmt.Errorf("%w; filePath=%q: %v", ErrInsecureSecretFile, filePath, err)
+	}
+	if problem == nil {
+		return nil
+	}
+	if cs.options.secretFilePolicy == SecretFileRefuse {
+		return problem
+	}
+	cs.warn(problem)
+	return nil
+}
+
+// containsSecrets reports whether the given config contains secrets. The
+// reserved configs defaults and overrides cover the whole config set.
+func (cs *ConfigSet) containsSecrets(configName string, rawConfig json.RawMessage) bool {
+	for _, secretPath := range cs.secretPaths {
+		path := secretPath
+		if configName != defaultsConfigName && configName != overridesConfigName {
+			keys := splitPath(secretPath)
+			if keys[0] != configName {
+				continue
+			}
+			if len(keys) == 1 {
+				return true
+			}
+			path = joinKeys(keys[1:])
+		}
+		if gjson.GetBytes(rawConfig, path).Exists() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_SecretFilePolicy(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: test
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	cs.AddSecretPaths("db.password")
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	var warnings []error
+	cs.Configure(
+		WithSecretFilePolicy(SecretFileWarn),
+		WithWarningHandler(func(warning error) { warnings = append(warnings, warning) }),
+	)
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.ErrorIs(t, warnings[0], ErrInsecureSecretFile)
+		assert.EqualError(t, warnings[0], `configset: insecure secret file; filePath="/my_etc/db.yaml" fileMode="-rw-r--r--": world-readable`)
+	}
+
+	cs.Configure(WithSecretFilePolicy(SecretFileRefuse))
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrInsecureSecretFile)
+
+	if err := fs.Chmod("/my_etc/db.yaml", 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+}
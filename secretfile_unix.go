@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package configset
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func checkFileOwner(fileInfo os.FileInfo) error {
+	stat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(stat.Uid) != uid {
+		return fmt.Errorf("not owned by user of process; fileUID=%d processUID=%d", stat.Uid, uid)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package configset
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RegisterSecretProvider registers the given secret provider with the given
+// name, which resolves string values such as secretref://{name}/{ref} into
+// secrets at load time, e.g. secretref://vault/kv/app#db_password, so that
+// configuration files reference secrets by names instead of containing them.
+// If no secret provider is registered with the name referenced, the load fails
+// with ErrSecretProviderNotFound.
+func RegisterSecretProvider(name string, secretProvider SecretProvider) {
+	cs.RegisterSecretProvider(name, secretProvider)
+}
+
+// SecretProvider resolves references into secrets, e.g. from Vault or cloud
+// secret managers.
+type SecretProvider interface {
+	// ResolveSecret returns the secret for the given reference, which is the
+	// part after secretref://{name}/.
+	ResolveSecret(ctx context.Context, ref string) (secret string, err error)
+}
+
+// ErrSecretProviderNotFound is returned when no secret provider is registered
+// with the name referenced.
+var ErrSecretProviderNotFound = errors.New("configset: secret provider not found")
+
+const secretRefPrefix = "secretref://"
+
+func (cs *ConfigSet) RegisterSecretProvider(name string, secretProvider SecretProvider) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if cs.secretProviders == nil {
+		cs.secretProviders = make(map[string]SecretProvider)
+	}
+	cs.secretProviders[name] = secretProvider
+}
+
+// secretRef represents a secret reference resolved, along with the secret
+// resolved last, and the reference in form of JSON as the original.
+type secretRef struct {
+	path     string
+	name     string
+	ref      string
+	value    json.RawMessage
+	original json.RawMessage
+}
+
+// resolveSecretRefs resolves the secret references within the given config set,
+// and returns the config set along with the secret references resolved.
+func resolveSecretRefs(ctx context.Context, rawConfigSet json.RawMessage, secretProviders map[string]SecretProvider) (json.RawMessage, []secretRef, error) {
+	var secretRefs []secretRef
+	rawConfigSet, err := replaceStrings(rawConfigSet, func(path string, s string) (json.RawMessage, error) {
+		if !strings.HasPrefix(s, secretRefPrefix) {
+			return nil, nil
+		}
+		secretRef := secretRef{path: path}
+		secretRef.name = s[len(secretRefPrefix):]
+		if i := strings.IndexByte(secretRef.name, '/'); i >= 0 {
+			secretRef.name, secretRef.ref = secretRef.name[:i], secretRef.name[i+1:]
+		}
+		value, err := secretRef.resolve(ctx, secretProviders)
+		if err != nil {
+			return nil, err
+		}
+		secretRef.value = value
+		secretRef.original, _ = json.Marshal(s)
+		secretRefs = append(secretRefs, secretRef)
+		return value, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return rawConfigSet, secretRefs, nil
+}
+
+// id returns the identity of the secret reference, which is unique within a
+// config set.
+func (sr *secretRef) id() string { return sr.path + "\x00" + string(sr.original) }
+
+func (sr *secretRef) resolve(ctx context.Context, secretProviders map[string]SecretProvider) (json.RawMessage, error) {
+	secretProvider, ok := secretProviders[sr.name]
+	if !ok {
+		return nil, fmt.Errorf("%w; path=%q name=%q", ErrSecretProviderNotFound, sr.path, sr.name)
+	}
+	secret, err := secretProvider.ResolveSecret(ctx, sr.ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolve secret; path=%q name=%q ref=%q: %w", sr.path, sr.name, sr.ref, err)
+	}
+	value, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("marshal to json; path=%q: %w", sr.path, err)
+	}
+	return value, nil
+}
+
+// WithSecretsOnDemand returns an option that makes loads keep the secret
+// references unresolved in the config set, and makes ReadValue resolve them on
+// demand, wiping the buffers of the resolved values after decoding, so that
+// secrets are not kept in the long-lived config set, which reduces exposure in
+// core dumps and heap profiles. Note that rules and validators see the secret
+// references rather than the secrets, and the secrets decoded into configs are
+// out of the control of the config set.
+func WithSecretsOnDemand() Option {
+	return func(options *options) { options.secretsOnDemand = true }
+}
+
+func wipeBuffer(buffer []byte) {
+	for i := range buffer {
+		buffer[i] = 0
+	}
+}
@@ -0,0 +1,237 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type mapSecretProvider map[string]string
+
+func (msp mapSecretProvider) ResolveSecret(_ context.Context, ref string) (string, error) {
+	secret, ok := msp[ref]
+	if !ok {
+		return "", errors.New("no secret")
+	}
+	return secret, nil
+}
+
+func TestConfigSet_RegisterSecretProvider(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: secretref://vault/kv/app#db_password
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrSecretProviderNotFound)
+	assert.EqualError(t, err, `configset: secret provider not found; path="db.password" name="vault"`)
+
+	cs.RegisterSecretProvider("vault", mapSecretProvider{"kv/app#db_password": "test"})
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"password":"test","user":"root"}}`, string(cs.Dump("", "")))
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.user=secretref://vault/kv/app#db_user"})
+	assert.EqualError(t, err, `resolve secret; path="db.user" name="vault" ref="kv/app#db_user": no secret`)
+}
+
+type rotatingSecretProvider struct {
+	mutex  sync.Mutex
+	secret string
+}
+
+func (rsp *rotatingSecretProvider) ResolveSecret(context.Context, string) (string, error) {
+	rsp.mutex.Lock()
+	defer rsp.mutex.Unlock()
+	return rsp.secret, nil
+}
+
+func (rsp *rotatingSecretProvider) Rotate(secret string) {
+	rsp.mutex.Lock()
+	defer rsp.mutex.Unlock()
+	rsp.secret = secret
+}
+
+func TestConfigSet_WatchSecrets(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: secretref://vault/kv/app#db_password
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	cs.Configure(WithSecretRefreshInterval(10 * time.Millisecond))
+	secretProvider := rotatingSecretProvider{secret: "test1"}
+	cs.RegisterSecretProvider("vault", &secretProvider)
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	passwords := make(chan string, 10)
+	cs.Subscribe("db.password", func(_, newValue json.RawMessage) {
+		var password string
+		json.Unmarshal(newValue, &password)
+		passwords <- password
+	})
+	err = cs.Set("db.user", "admin")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.WatchSecrets(ctx)
+	secretProvider.Rotate("test2")
+	select {
+	case password := <-passwords:
+		assert.Equal(t, "test2", password)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	assert.Equal(t, `{"db":{"password":"test2","user":"admin"}}`, string(cs.Dump("", "")))
+}
+
+// blockingSecretProvider resolves the secrets from the map, and blocks in
+// resolving until released once resolving is set.
+type blockingSecretProvider struct {
+	mutex     sync.Mutex
+	secrets   map[string]string
+	resolving chan struct{}
+	release   chan struct{}
+}
+
+func (bsp *blockingSecretProvider) ResolveSecret(_ context.Context, ref string) (string, error) {
+	bsp.mutex.Lock()
+	secret := bsp.secrets[ref]
+	resolving, release := bsp.resolving, bsp.release
+	bsp.mutex.Unlock()
+	if resolving != nil {
+		select {
+		case resolving <- struct{}{}:
+		default:
+		}
+		<-release
+	}
+	return secret, nil
+}
+
+func TestConfigSet_WatchSecrets_rotation(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: secretref://vault/password
+token: secretref://vault/token
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	var auditEvents []AuditEvent
+	var auditMutex sync.Mutex
+	cs.Configure(
+		WithSecretRefreshInterval(10*time.Millisecond),
+		WithAuditSink(func(event AuditEvent) {
+			auditMutex.Lock()
+			auditEvents = append(auditEvents, event)
+			auditMutex.Unlock()
+		}),
+	)
+	secretProvider := blockingSecretProvider{secrets: map[string]string{"password": "p1", "token": "t1"}}
+	cs.RegisterSecretProvider("vault", &secretProvider)
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Set("db.token", "manual")
+	assert.NoError(t, err)
+	passwords := make(chan string, 10)
+	cs.Subscribe("db.password", func(_, newValue json.RawMessage) {
+		var password string
+		json.Unmarshal(newValue, &password)
+		passwords <- password
+	})
+
+	resolving := make(chan struct{}, 1)
+	release := make(chan struct{})
+	secretProvider.mutex.Lock()
+	secretProvider.secrets = map[string]string{"password": "p2", "token": "t2"}
+	secretProvider.resolving, secretProvider.release = resolving, release
+	secretProvider.mutex.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.WatchSecrets(ctx)
+	select {
+	case <-resolving:
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- cs.Set("db.user", "admin") }()
+	select {
+	case err := <-errs:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Set blocked by resolving secrets")
+	}
+	close(release)
+	select {
+	case password := <-passwords:
+		assert.Equal(t, "p2", password)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	assert.Equal(t, `{"db":{"password":"p2","token":"manual","user":"admin"}}`, string(cs.Dump("", "")))
+	assert.Equal(t, 4, cs.History()[0].Number)
+	auditMutex.Lock()
+	lastAuditEvent := auditEvents[len(auditEvents)-1]
+	auditMutex.Unlock()
+	assert.Equal(t, AuditSecretRotation, lastAuditEvent.Kind)
+	assert.Equal(t, "db.password", lastAuditEvent.Path)
+}
+
+func TestConfigSet_SecretsOnDemand(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+user: root
+password: secretref://vault/kv/app#db_password
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	cs.Configure(WithSecretsOnDemand())
+	secretProvider := rotatingSecretProvider{secret: "test1"}
+	cs.RegisterSecretProvider("vault", &secretProvider)
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"db":{"password":"secretref://vault/kv/app#db_password","user":"root"}}`, string(cs.Dump("", "")))
+
+	type DB struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	var db DB
+	err = cs.ReadValue("db", &db)
+	assert.NoError(t, err)
+	assert.Equal(t, DB{User: "root", Password: "test1"}, db)
+
+	secretProvider.Rotate("test2")
+	err = cs.ReadValue("db", &db)
+	assert.NoError(t, err)
+	assert.Equal(t, DB{User: "root", Password: "test2"}, db)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.user=secretref://aws/db_user"})
+	assert.NoError(t, err)
+	err = cs.ReadValue("db", &db)
+	assert.EqualError(t, err, `resolve secret refs; path="db": configset: secret provider not found; path="user" name="aws"`)
+}
@@ -0,0 +1,145 @@
+package configset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// WatchSecrets re-resolves the secret references, see RegisterSecretProvider,
+// at the interval set by WithSecretRefreshInterval, and updates the secrets
+// rotated, so that long-lived processes pick up rotated credentials, with
+// subscribers of the secrets notified, and the rotations recorded in the
+// history and reported to the audit sink. Secrets changed since resolved, e.g.
+// by Set, are left as they are. WatchSecrets blocks until the given
+// context is done or the config set is closed, and returns the error of the
+// context or ErrClosed.
+// If a refresh fails, the config set stays as it was, and the failure is
+// reported to the handler set by WithReloadErrorHandler.
+func WatchSecrets(ctx context.Context) error { return cs.WatchSecrets(ctx) }
+
+// WithSecretRefreshInterval returns an option that sets the interval at which
+// WatchSecrets re-resolves the secret references. The default interval is 5
+// minutes.
+func WithSecretRefreshInterval(secretRefreshInterval time.Duration) Option {
+	return func(options *options) { options.secretRefreshInterval = secretRefreshInterval }
+}
+
+const defaultSecretRefreshInterval = 5 * time.Minute
+
+func (cs *ConfigSet) WatchSecrets(ctx context.Context) error {
+	cs.mutex.Lock()
+	loaded := cs.input.fs != nil
+	secretRefreshInterval := cs.options.secretRefreshInterval
+	cs.mutex.Unlock()
+	if !loaded {
+		return ErrNotLoaded
+	}
+	closure, err := cs.enterBackground()
+	if err != nil {
+		return err
+	}
+	defer cs.leaveBackground()
+	ctx, cancel := contextWithClosure(ctx, closure)
+	defer cancel()
+	if secretRefreshInterval <= 0 {
+		secretRefreshInterval = defaultSecretRefreshInterval
+	}
+	ticker := time.NewTicker(secretRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return stopErr(ctx, closure)
+		case <-closure:
+			return ErrClosed
+		case <-ticker.C:
+		}
+		if err := cs.refreshSecrets(ctx); err != nil {
+			if ctx.Err() != nil {
+				return stopErr(ctx, closure)
+			}
+			cs.reportReloadError(fmt.Errorf("refresh secrets: %w", err))
+		}
+	}
+}
+
+func (cs *ConfigSet) refreshSecrets(ctx context.Context) error {
+	cs.mutex.Lock()
+	secretRefs := append([]secretRef(nil), cs.secretRefs...)
+	secretProviders := make(map[string]SecretProvider, len(cs.secretProviders))
+	for name, secretProvider := range cs.secretProviders {
+		secretProviders[name] = secretProvider
+	}
+	cs.mutex.Unlock()
+	// Secret providers may take network round trips, so the secrets are resolved
+	// without holding the lock.
+	newValues := make(map[string]json.RawMessage, len(secretRefs))
+	for i := range secretRefs {
+		secretRef := &secretRefs[i]
+		newValue, err := secretRef.resolve(ctx, secretProviders)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(newValue, secretRef.value) {
+			newValues[secretRef.id()] = newValue
+		}
+	}
+	if len(newValues) == 0 {
+		return nil
+	}
+	cs.mutex.Lock()
+	oldSnapshot := cs.Snapshot()
+	raw := oldSnapshot.Dump("", "")
+	var rotatedIndexes []int
+	for i := range cs.secretRefs {
+		secretRef := &cs.secretRefs[i]
+		newValue, ok := newValues[secretRef.id()]
+		if !ok {
+			continue
+		}
+		// Values changed since the secrets were resolved, e.g. by Set or
+		// reloads, are left as they are.
+		oldValue := gjson.GetBytes(raw, secretRef.path)
+		if !oldValue.Exists() || !jsonValueEqual(oldValue, gjson.ParseBytes(secretRef.value)) {
+			continue
+		}
+		var err error
+		raw, err = sjson.SetRawBytes(raw, secretRef.path, newValue)
+		if err != nil {
+			cs.mutex.Unlock()
+			return fmt.Errorf("set json value; path=%q: %w", secretRef.path, err)
+		}
+		rotatedIndexes = append(rotatedIndexes, i)
+	}
+	if len(rotatedIndexes) == 0 {
+		cs.mutex.Unlock()
+		return nil
+	}
+	if err := cs.check(raw, cs.provenance); err != nil {
+		cs.mutex.Unlock()
+		return err
+	}
+	rotatedPaths := make([]string, len(rotatedIndexes))
+	for i, j := range rotatedIndexes {
+		secretRef := &cs.secretRefs[j]
+		secretRef.value = newValues[secretRef.id()]
+		rotatedPaths[i] = secretRef.path
+	}
+	cs.storeSnapshot(raw)
+	newSnapshot := cs.Snapshot()
+	auditSink := cs.options.auditSink
+	cs.mutex.Unlock()
+	cs.subscriptions.Notify(oldSnapshot.raw, newSnapshot.raw)
+	if auditSink != nil {
+		for _, path := range rotatedPaths {
+			auditSink(newAuditEvent(AuditSecretRotation, "", path, oldSnapshot.raw, newSnapshot.raw))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"sigs.k8s.io/yaml"
+)
+
+// SetInFile sets the value for the given path to the given value in form of
+// JSON in the configuration file under the given directory, i.e. {name}.yaml for
+// the path {name}.{...}, preserving comments and key order of the file, so that
+// automation can edit configuration files safely rather than with regular
+// expressions. The file is created if it doesn't exist. Config sets are left
+// untouched, and SOPS-encrypted files are refused with ErrSOPSFile.
+func SetInFile(fs afero.Fs, dirPath string, path string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal to json; path=%q valueType=\"%T\": %w", path, value, err)
+	}
+	keys := splitPath(path)
+	filePath := filepath.Join(dirPath, keys[0]+".yaml")
+	yamlFile, err := afero.ReadFile(fs, filePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+	}
+	var rawConfig json.RawMessage
+	if len(yamlFile) >= 1 {
+		rawConfig, err = yaml.YAMLToJSON(yamlFile)
+		if err != nil {
+			return fmt.Errorf("convert yaml to json; filePath=%q: %w", filePath, err)
+		}
+		if isSOPSFile(rawConfig) {
+			return fmt.Errorf("%w; filePath=%q", ErrSOPSFile, filePath)
+		}
+	}
+	if len(keys) == 1 {
+		rawConfig = data
+	} else {
+		var subpath string
+		for _, key := range keys[1:] {
+			subpath = joinPath(subpath, key)
+		}
+		if !gjson.ParseBytes(rawConfig).IsObject() {
+			rawConfig = json.RawMessage("{}")
+		}
+		rawConfig, err = sjson.SetRawBytes(rawConfig, subpath, data)
+		if err != nil {
+			return fmt.Errorf("set json value; path=%q: %w", path, err)
+		}
+	}
+	yamlFile, err = roundTripYAML(yamlFile, gjson.ParseBytes(rawConfig))
+	if err != nil {
+		return fmt.Errorf("round trip yaml; filePath=%q: %w", filePath, err)
+	}
+	if err := afero.WriteFile(fs, filePath, yamlFile, 0644); err != nil {
+		return fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+	}
+	return nil
+}
+
+// ErrSOPSFile is returned when a SOPS-encrypted file is to be edited, or values
+// of SOPS-encrypted files are to be written back in plaintext.
+var ErrSOPSFile = errors.New("configset: sops file")
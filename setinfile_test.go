@@ -0,0 +1,52 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetInFile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`# server
+port: 8080 # the port
+tls:
+  cert: a.pem
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := SetInFile(fs, "/my_etc", "server.port", 8081)
+	assert.NoError(t, err)
+	err = SetInFile(fs, "/my_etc", "server.tls.key", "a.key")
+	assert.NoError(t, err)
+	data, err := afero.ReadFile(fs, "/my_etc/server.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, `# server
+port: 8081 # the port
+tls:
+  cert: a.pem
+  key: a.key
+`, string(data))
+
+	err = SetInFile(fs, "/my_etc", `client.labels.app\.kubernetes\.io/name`, "foo")
+	assert.NoError(t, err)
+	data, err = afero.ReadFile(fs, "/my_etc/client.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "labels:\n  app.kubernetes.io/name: foo\n", string(data))
+	err = SetInFile(fs, "/my_etc", "client", []int{1})
+	assert.NoError(t, err)
+	data, err = afero.ReadFile(fs, "/my_etc/client.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "- 1\n", string(data))
+
+	if err := afero.WriteFile(fs, "/my_etc/secrets.yaml", []byte(`
+password: ENC[AES256_GCM,data:x]
+sops: {mac: y}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = SetInFile(fs, "/my_etc", "secrets.password", "hunter2")
+	assert.ErrorIs(t, err, ErrSOPSFile)
+}
@@ -0,0 +1,31 @@
+package configset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// SetForTest likes Set but restores the previous value for the given path, or
+// deletes the value if there was none, when the given test and all its subtests
+// complete, so that table tests can tweak one value safely. If setting or
+// restoring the value fails, the test fails.
+func SetForTest(t testing.TB, path string, value interface{}) { cs.SetForTest(t, path, value) }
+
+func (cs *ConfigSet) SetForTest(t testing.TB, path string, value interface{}) {
+	t.Helper()
+	oldValue := cs.Snapshot().getValue(path)
+	if err := cs.Set(path, value); err != nil {
+		t.Fatalf("configset: set value for test; path=%q: %v", path, err)
+	}
+	t.Cleanup(func() {
+		var err error
+		if oldValue == "" {
+			err = cs.Delete(path)
+		} else {
+			err = cs.Set(path, json.RawMessage(oldValue))
+		}
+		if err != nil {
+			t.Errorf("configset: restore value for test; path=%q: %v", path, err)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_SetForTest(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+tags: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Run("", func(t *testing.T) {
+		cs.SetForTest(t, "server.port", 8081)
+		cs.SetForTest(t, "server.tags", []string{"c"})
+		cs.SetForTest(t, "server.host", "localhost")
+		assert.Equal(t, `{"server":{"host":"localhost","port":8081,"tags":["c"]}}`, string(cs.Dump("", "")))
+	})
+	assert.Equal(t, `{"server":{"port":8080,"tags":["a","b"]}}`, string(cs.Dump("", "")))
+}
@@ -0,0 +1,131 @@
+package configset
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+// WithSignatureKey returns an option that makes loads verify the signature of
+// the configuration files, in the reserved file configset.sig under the
+// directory, with the given Ed25519 public key before loading, so that only
+// configuration signed by the release pipeline is accepted. If the signature
+// is missing or invalid, the load fails with ErrInvalidSignature. The files are
+// loaded from the exact bytes verified, and placeholders such as
+// ${file:{path}}, which inline files the signature doesn't cover, fail with
+// ErrFileNotAllowed. See SignDir.
+func WithSignatureKey(publicKey ed25519.PublicKey) Option {
+	return func(options *options) { options.signatureKey = publicKey }
+}
+
+// SignDir signs the configuration files under the given directory with the
+// given Ed25519 private key, and writes the signature into the reserved file
+// configset.sig under the directory.
+// The signature is made over the manifest of the files, which lists the SHA-256
+// digests and relative paths of the *.yaml files under the directory and the
+// directory _defaults, in the format of SHA256SUMS files.
+func SignDir(fs afero.Fs, dirPath string, privateKey ed25519.PrivateKey) error {
+	manifest, err := makeManifest(fs, dirPath, nil)
+	if err != nil {
+		return err
+	}
+	signature := ed25519.Sign(privateKey, manifest)
+	data := []byte(base64.StdEncoding.EncodeToString(signature) + "\n")
+	filePath := filepath.Join(dirPath, signatureFileName)
+	if err := afero.WriteFile(fs, filePath, data, 0644); err != nil {
+		return fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+	}
+	return nil
+}
+
+// ErrInvalidSignature is returned when the signature of the configuration
+// files is missing or invalid.
+var ErrInvalidSignature = errors.New("configset: invalid signature")
+
+const signatureFileName = "configset.sig"
+
+func verifySignature(fs afero.Fs, dirPath string, manifest []byte, publicKey ed25519.PublicKey) error {
+	filePath := filepath.Join(dirPath, signatureFileName)
+	data, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		return fmt.Errorf("%w; filePath=%q: %v", ErrInvalidSignature, filePath, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return fmt.Errorf("%w; filePath=%q: %v", ErrInvalidSignature, filePath, err)
+	}
+	if !ed25519.Verify(publicKey, manifest, signature) {
+		return fmt.Errorf("%w; filePath=%q", ErrInvalidSignature, filePath)
+	}
+	return nil
+}
+
+// makeManifest returns the manifest of the configuration files under the given
+// directory, which lists the SHA-256 digests and relative paths of the files,
+// one line for each file sorted by path, in the format of SHA256SUMS files.
+// If fileCopies is not nil, the files are also copied into it as they are
+// digested, so that they can be loaded from the exact bytes the manifest is
+// made of, rather than reread after they might have changed.
+func makeManifest(fs afero.Fs, dirPath string, fileCopies afero.Fs) ([]byte, error) {
+	fileNames, err := listYAMLFiles(fs, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	defaultsDirPath := filepath.Join(dirPath, defaultsDirName)
+	if fileInfo, err := fs.Stat(defaultsDirPath); err == nil && fileInfo.IsDir() {
+		defaultsFileNames, err := listYAMLFiles(fs, defaultsDirPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, fileName := range defaultsFileNames {
+			fileNames = append(fileNames, path.Join(defaultsDirName, fileName))
+		}
+	}
+	sort.Strings(fileNames)
+	if fileCopies != nil {
+		if err := fileCopies.MkdirAll(dirPath, 0755); err != nil {
+			return nil, fmt.Errorf("make dir; dirPath=%q: %w", dirPath, err)
+		}
+	}
+	var buffer bytes.Buffer
+	fileBuffer := getBuffer()
+	defer putBuffer(fileBuffer)
+	for _, fileName := range fileNames {
+		filePath := filepath.Join(dirPath, filepath.FromSlash(fileName))
+		fileBuffer.Reset()
+		if err := readFile(fs, filePath, fileBuffer); err != nil {
+			return nil, fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+		}
+		fmt.Fprintf(&buffer, "%x  %s\n", sha256.Sum256(fileBuffer.Bytes()), fileName)
+		if fileCopies != nil {
+			if err := afero.WriteFile(fileCopies, filePath, fileBuffer.Bytes(), 0644); err != nil {
+				return nil, fmt.Errorf("write file; filePath=%q: %w", filePath, err)
+			}
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+func listYAMLFiles(fs afero.Fs, dirPath string) ([]string, error) {
+	fileInfoSet, err := afero.ReadDir(fs, dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dir; dirPath=%q: %w", dirPath, err)
+	}
+	var fileNames []string
+	for _, fileInfo := range fileInfoSet {
+		if fileInfo.IsDir() || !strings.HasSuffix(fileInfo.Name(), ".yaml") {
+			continue
+		}
+		fileNames = append(fileNames, fileInfo.Name())
+	}
+	return fileNames, nil
+}
@@ -0,0 +1,117 @@
+package configset_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_SignatureKey(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/_defaults/server.yaml", []byte(`
+host: localhost
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	cs.Configure(WithSignatureKey(publicKey))
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	err = SignDir(fs, "/my_etc", privateKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+
+	if err := afero.WriteFile(fs, "/my_etc/_defaults/server.yaml", []byte(`
+host: example.com
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.EqualError(t, err, `configset: invalid signature; filePath="/my_etc/configset.sig"`)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8080}}`, string(cs.Dump("", "")))
+}
+
+func TestConfigSet_SignatureKey_verifiedBytes(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = SignDir(fs, "/my_etc", privateKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	tamperedFS := afero.NewMemMapFs()
+	if err := afero.WriteFile(tamperedFS, "/my_etc/server.yaml", []byte(`
+port: 9090
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	cs.Configure(WithSignatureKey(publicKey))
+	err = cs.Load(&tamperingFS{Fs: fs, filePath: "/my_etc/server.yaml", tamperedFS: tamperedFS}, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+
+	if err := afero.WriteFile(fs, "/my_etc/cert.pem", []byte("-----BEGIN CERTIFICATE-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+cert: ${file:cert.pem}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = SignDir(fs, "/my_etc", privateKey)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrFileNotAllowed)
+}
+
+// tamperingFS likes the wrapped file system but opens the file with the given
+// path from the tampered file system once it has been opened, as if the file
+// were replaced right after being read.
+type tamperingFS struct {
+	afero.Fs
+
+	filePath   string
+	tamperedFS afero.Fs
+	opened     bool
+}
+
+func (tf *tamperingFS) Open(name string) (afero.File, error) {
+	if name != tf.filePath {
+		return tf.Fs.Open(name)
+	}
+	if tf.opened {
+		return tf.tamperedFS.Open(name)
+	}
+	tf.opened = true
+	return tf.Fs.Open(name)
+}
@@ -0,0 +1,94 @@
+package configset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+// WithFileSizeLimit returns an option that sets the maximum size in bytes of
+// each configuration file, beyond which loads fail with ErrFileTooLarge before
+// reading the files, so that a misplaced huge file can't run the service out of
+// memory. Files are read and converted to JSON as a whole rather than streamed,
+// so the limit also bounds the memory needed for each file. The default is 0,
+// i.e. no limit.
+func WithFileSizeLimit(fileSizeLimit int64) Option {
+	return func(options *options) { options.fileSizeLimit = fileSizeLimit }
+}
+
+// WithTotalSizeLimit returns an option that sets the maximum total size in bytes
+// of the configuration files, including the ones under the directory _defaults,
+// beyond which loads fail with ErrFileTooLarge before reading the files. The
+// default is 0, i.e. no limit.
+func WithTotalSizeLimit(totalSizeLimit int64) Option {
+	return func(options *options) { options.totalSizeLimit = totalSizeLimit }
+}
+
+// ErrFileTooLarge is returned when configuration files exceed the size limits
+// set by WithFileSizeLimit or WithTotalSizeLimit.
+var ErrFileTooLarge = errors.New("configset: file too large")
+
+// checkFileSizes checks the sizes of the configuration files against the limits
+// set by WithFileSizeLimit and WithTotalSizeLimit.
+func (cs *ConfigSet) checkFileSizes(fs afero.Fs, dirPath string) error {
+	fileSizeLimit, totalSizeLimit := cs.options.fileSizeLimit, cs.options.totalSizeLimit
+	if fileSizeLimit <= 0 && totalSizeLimit <= 0 {
+		return nil
+	}
+	dirPaths := []string{dirPath}
+	defaultsDirPath := filepath.Join(dirPath, defaultsDirName)
+	if fileInfo, err := fs.Stat(defaultsDirPath); err == nil && fileInfo.IsDir() {
+		dirPaths = append(dirPaths, defaultsDirPath)
+	}
+	var totalSize int64
+	for _, dirPath := range dirPaths {
+		fileNames, err := listYAMLFiles(fs, dirPath)
+		if err != nil {
+			return err
+		}
+		for _, fileName := range fileNames {
+			filePath := filepath.Join(dirPath, fileName)
+			fileInfo, err := fs.Stat(filePath)
+			if err != nil {
+				return fmt.Errorf("stat file; filePath=%q: %w", filePath, err)
+			}
+			if fileSizeLimit >= 1 && fileInfo.Size() > fileSizeLimit {
+				return fmt.Errorf("%w; filePath=%q fileSize=%d fileSizeLimit=%d", ErrFileTooLarge, filePath, fileInfo.Size(), fileSizeLimit)
+			}
+			totalSize += fileInfo.Size()
+			if totalSizeLimit >= 1 && totalSize > totalSizeLimit {
+				return fmt.Errorf("%w; filePath=%q totalSize=%d totalSizeLimit=%d", ErrFileTooLarge, filePath, totalSize, totalSizeLimit)
+			}
+		}
+	}
+	return nil
+}
+
+// readFile reads the given file into the given buffer, and fails with
+// ErrFileTooLarge if the file exceeds the limit set by WithFileSizeLimit, e.g.
+// when the file grows after the sizes are checked.
+func (cs *ConfigSet) readFile(fs afero.Fs, filePath string, buffer *bytes.Buffer) ([]byte, error) {
+	fileSizeLimit := cs.options.fileSizeLimit
+	if fileSizeLimit <= 0 {
+		if err := readFile(fs, filePath, buffer); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if _, err := buffer.ReadFrom(io.LimitReader(file, fileSizeLimit+1)); err != nil {
+		return nil, err
+	}
+	if fileSizeLimit >= 1 && int64(buffer.Len()) > fileSizeLimit {
+		return nil, fmt.Errorf("%w; fileSizeLimit=%d", ErrFileTooLarge, fileSizeLimit)
+	}
+	return buffer.Bytes(), nil
+}
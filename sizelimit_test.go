@@ -0,0 +1,35 @@
+package configset_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFileSizeLimit(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/_defaults/log.yaml", []byte("level: info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	cs.Configure(WithFileSizeLimit(12), WithTotalSizeLimit(23))
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	if err := afero.WriteFile(fs, "/my_etc/data.yaml", []byte("blob: "+strings.Repeat("x", 100)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.ForceRefresh()
+	assert.ErrorIs(t, err, ErrFileTooLarge)
+	assert.EqualError(t, err, `reload config set: configset: file too large; filePath="/my_etc/data.yaml" fileSize=107 fileSizeLimit=12`)
+
+	cs.Configure(WithFileSizeLimit(0))
+	err = cs.ForceRefresh()
+	assert.EqualError(t, err, `reload config set: configset: file too large; filePath="/my_etc/data.yaml" totalSize=107 totalSizeLimit=23`)
+}
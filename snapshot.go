@@ -0,0 +1,131 @@
+package configset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// CurrentSnapshot returns the snapshot of the config set.
+func CurrentSnapshot() *Snapshot { return cs.Snapshot() }
+
+// Snapshot represents an immutable view of the config set as of a load, which
+// enables reading multiple related values consistently even if the config set
+// is reloaded in the meanwhile.
+type Snapshot struct {
+	raw             json.RawMessage
+	decodeMode      DecodeMode
+	secretProviders map[string]SecretProvider
+	jsonCodec       JSONCodec
+	fingerprint     string
+	index           *valueIndex
+	decodeCache     *decodeCache
+	loaded          bool
+}
+
+func (cs *ConfigSet) Snapshot() *Snapshot {
+	snapshot, ok := cs.snapshot.Load().(*Snapshot)
+	if !ok {
+		return &Snapshot{}
+	}
+	return snapshot
+}
+
+// ReadValue likes the package-level ReadValue but reads the value from the
+// snapshot.
+func (s *Snapshot) ReadValue(path string, config interface{}) error {
+	if s.raw == nil {
+		return fmt.Errorf("%w; path=%q", ErrNotLoaded, path)
+	}
+	value := s.getValue(path)
+	if value == "" {
+		return fmt.Errorf("%w; path=%q", ErrValueNotFound, path)
+	}
+	hasSecretRefs := s.secretProviders != nil && strings.Contains(value, secretRefPrefix)
+	isCacheable := s.decodeCache != nil && !hasSecretRefs && s.decodeCache.isCacheable(config, s.decodeMode)
+	if isCacheable && s.decodeCache.get(path, config) {
+		return nil
+	}
+	data := []byte(value)
+	if hasSecretRefs {
+		var err error
+		data, _, err = resolveSecretRefs(context.Background(), data, s.secretProviders)
+		if err != nil {
+			return fmt.Errorf("resolve secret refs; path=%q: %w", path, err)
+		}
+		defer wipeBuffer(data)
+	}
+	switch s.decodeMode {
+	case DecodeReplace:
+		resetConfig(config)
+	case DecodeMerge:
+		currentData, err := s.getJSONCodec().Marshal(config)
+		if err != nil {
+			return fmt.Errorf("marshal to json; path=%q configType=\"%T\": %w", path, config, err)
+		}
+		data = mergeJSON(currentData, data)
+		if s.secretProviders != nil {
+			defer wipeBuffer(data)
+		}
+		resetConfig(config)
+	}
+	if err := s.getJSONCodec().Unmarshal(data, config); err != nil {
+		return fmt.Errorf("unmarshal from json; path=%q configType=\"%T\": %w", path, config, err)
+	}
+	if err := applyDefaultTags(reflect.ValueOf(config), gjson.ParseBytes(data)); err != nil {
+		return fmt.Errorf("apply default tags; path=%q configType=\"%T\": %w", path, config, err)
+	}
+	if isCacheable {
+		s.decodeCache.put(path, config)
+	}
+	return nil
+}
+
+// getValue returns the value for the given path in form of JSON, or an empty
+// string if the value can't be found.
+func (s *Snapshot) getValue(path string) string {
+	if s.index == nil {
+		return gjson.GetBytes(s.raw, path).Raw
+	}
+	return s.index.getValue(s.raw, path)
+}
+
+// getJSONCodec returns the codec set by WithJSONCodec as of the snapshot, or
+// the codec of the package encoding/json.
+func (s *Snapshot) getJSONCodec() JSONCodec {
+	if s.jsonCodec == nil {
+		return stdJSONCodec{}
+	}
+	return s.jsonCodec
+}
+
+func resetConfig(config interface{}) {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return
+	}
+	value = value.Elem()
+	value.Set(reflect.Zero(value.Type()))
+}
+
+// Bytes likes the package-level Bytes but returns the snapshot.
+func (s *Snapshot) Bytes() []byte { return s.raw }
+
+// Dump likes the package-level Dump but dumps the snapshot.
+func (s *Snapshot) Dump(prefix string, indention string) json.RawMessage {
+	if len(prefix)+len(indention) == 0 {
+		raw := make(json.RawMessage, len(s.raw))
+		copy(raw, s.raw)
+		return raw
+	}
+	var buffer bytes.Buffer
+	json.Indent(&buffer, s.raw, prefix, indention)
+	buffer.WriteByte('\n')
+	raw := buffer.Bytes()
+	return raw
+}
@@ -0,0 +1,141 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Snapshot(t *testing.T) {
+	var cs ConfigSet
+	assert.False(t, cs.IsLoaded())
+	err := cs.Set("server.host", "localhost")
+	assert.NoError(t, err)
+	assert.False(t, cs.IsLoaded())
+	cs = ConfigSet{}
+	snapshot := cs.Snapshot()
+	var port int
+	err = snapshot.ReadValue("server.port", &port)
+	assert.ErrorIs(t, err, ErrNotLoaded)
+	assert.EqualError(t, err, `configset: config set not loaded; path="server.port"`)
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.True(t, cs.IsLoaded())
+	snapshot = cs.Snapshot()
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081"})
+	assert.NoError(t, err)
+
+	err = snapshot.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(snapshot.Dump("", "")))
+	err = cs.Snapshot().ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, port)
+}
+
+func TestSnapshot_ReadValue(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+hosts:
+  - name: a
+  - name: b
+labels: {app.kubernetes.io/name: foo}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	snapshot := cs.Snapshot()
+	for i := 0; i < 2; i++ {
+		var name string
+		err = snapshot.ReadValue("server.hosts.1.name", &name)
+		assert.NoError(t, err)
+		assert.Equal(t, "b", name)
+		err = snapshot.ReadValue(`server.labels.app\.kubernetes\.io/name`, &name)
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", name)
+		var names []string
+		err = snapshot.ReadValue("server.hosts.#.name", &names)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, names)
+		err = snapshot.ReadValue("server.hosts.2", &name)
+		assert.ErrorIs(t, err, ErrValueNotFound)
+	}
+}
+
+func TestConfigSet_Bytes(t *testing.T) {
+	var cs ConfigSet
+	assert.Nil(t, cs.Bytes())
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Bytes()))
+	assert.Equal(t, string(cs.Dump("", "")), string(cs.Bytes()))
+	assert.Equal(t, &cs.Bytes()[0], &cs.Snapshot().Bytes()[0])
+}
+
+func TestConfigSet_ReadValue_duringLoad(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fetching := make(chan struct{}, 1)
+	fetched := make(chan struct{})
+	var cs ConfigSet
+	cs.AddSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+		fetching <- struct{}{}
+		<-fetched
+		return json.RawMessage(`{"server":{"port":8081}}`), nil
+	}))
+	go func() { fetched <- struct{}{} }()
+	err := cs.Load(fs, "/my_etc", nil)
+	<-fetching
+	assert.NoError(t, err)
+
+	errs := make(chan error, 1)
+	go func() { errs <- cs.ForceRefresh() }()
+	<-fetching
+	var port int
+	err = cs.ReadValue("server.port", &port)
+	assert.NoError(t, err)
+	assert.Equal(t, 8081, port)
+	assert.NotEmpty(t, cs.Fingerprint())
+	close(fetched)
+	assert.NoError(t, <-errs)
+}
+
+func BenchmarkConfigSet_ReadValue(b *testing.B) {
+	fs := newBenchmarkFs(b, 10, 100)
+	var cs ConfigSet
+	if err := cs.Load(fs, "/my_etc", nil); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var number int
+		if err := cs.ReadValue("config9.key99.number", &number); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
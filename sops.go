@@ -0,0 +1,70 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+	"sigs.k8s.io/yaml"
+)
+
+// WithSOPSDecryptor returns an option that sets the function decrypting files
+// encrypted by Mozilla SOPS, which are detected by the SOPS metadata and
+// decrypted at load time, so that encrypted secrets can live in the same
+// directory as plaintext configuration files. The function is called with the
+// content and format ("yaml") of a file, and is supposed to return the
+// decrypted content, which is what decrypt.Data of go.mozilla.org/sops/v3 does
+// with the age, PGP or KMS backends configured by SOPS, e.g.
+//
+//	configset.Configure(configset.WithSOPSDecryptor(decrypt.Data))
+//
+// Without the function, loading SOPS-encrypted files fails with
+// ErrNoSOPSDecryptor.
+func WithSOPSDecryptor(sopsDecryptor func(data []byte, format string) ([]byte, error)) Option {
+	return func(options *options) { options.sopsDecryptor = sopsDecryptor }
+}
+
+// ErrNoSOPSDecryptor is returned when a SOPS-encrypted file is loaded without
+// the function set by WithSOPSDecryptor.
+var ErrNoSOPSDecryptor = errors.New("configset: no sops decryptor")
+
+// isSOPSFile reports whether the given config is of a SOPS-encrypted file,
+// which has the top-level key `sops` for the metadata.
+func isSOPSFile(rawConfig json.RawMessage) bool {
+	metadata := gjson.GetBytes(rawConfig, "sops")
+	return metadata.IsObject() && metadata.Get("mac").Exists()
+}
+
+// checkSOPSFile returns ErrSOPSFile if the given file exists and is
+// SOPS-encrypted.
+func checkSOPSFile(fs afero.Fs, filePath string) error {
+	data, err := afero.ReadFile(fs, filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read file; filePath=%q: %w", filePath, err)
+	}
+	if rawConfig, err := yaml.YAMLToJSON(data); err == nil && isSOPSFile(rawConfig) {
+		return fmt.Errorf("%w; filePath=%q", ErrSOPSFile, filePath)
+	}
+	return nil
+}
+
+func (cs *ConfigSet) decryptSOPSFile(data []byte) (json.RawMessage, error) {
+	if cs.options.sopsDecryptor == nil {
+		return nil, ErrNoSOPSDecryptor
+	}
+	data, err := cs.options.sopsDecryptor(data, "yaml")
+	if err != nil {
+		return nil, err
+	}
+	rawConfig, err := yaml.YAMLToJSONStrict(data)
+	if err != nil {
+		return nil, fmt.Errorf("convert yaml to json: %w", err)
+	}
+	return rawConfig, nil
+}
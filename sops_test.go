@@ -0,0 +1,99 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_SOPSDecryptor(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	encryptedData := []byte(`
+password: ENC[AES256_GCM,data:dGVzdA==,iv:aXY=,tag:dGFn,type:str]
+sops:
+    age:
+        - recipient: age1xxx
+    lastmodified: "2021-01-01T00:00:00Z"
+    mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+    version: 3.7.1
+`)
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", encryptedData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.ErrorIs(t, err, ErrNoSOPSDecryptor)
+	assert.EqualError(t, err, `decrypt sops file; filePath="/my_etc/db.yaml": configset: no sops decryptor`)
+
+	cs.Configure(WithSOPSDecryptor(func(data []byte, format string) ([]byte, error) {
+		assert.Equal(t, encryptedData, data)
+		assert.Equal(t, "yaml", format)
+		return []byte("password: test\n"), nil
+	}))
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"db":{"password":"test"},"server":{"port":8080}}`, string(cs.Dump("", "")))
+	assert.Equal(t, `{"db":{"password":"******"},"server":{"port":8080}}`, string(cs.DumpRedacted("", "")))
+}
+
+func TestConfigSet_SOPSDecryptor_explain(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+users: [{name: a, token: t}]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/db.yaml", []byte(`
+password: ENC[AES256_GCM,data:dGVzdA==,iv:aXY=,tag:dGFn,type:str]
+sops:
+    age:
+        - recipient: age1xxx
+    lastmodified: "2021-01-01T00:00:00Z"
+    mac: ENC[AES256_GCM,data:bWFj,iv:aXY=,tag:dGFn,type:str]
+    version: 3.7.1
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	cs.AddSecretPaths("server.users.0.token")
+	cs.Configure(WithMergeTrace(), WithSOPSDecryptor(func([]byte, string) ([]byte, error) {
+		return []byte("password: test\n"), nil
+	}))
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.db.password=test2"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	explanations, err := cs.Explain("")
+	assert.NoError(t, err)
+	assert.Equal(t, []Explanation{
+		{Path: "db.password", Origins: []Origin{
+			{Layer: OriginEnv, Name: "CONFIGSET.db.password", Value: []byte(`"******"`)},
+			{Layer: OriginFile, Name: "/my_etc/db.yaml", Value: []byte(`"******"`)},
+		}},
+		{Path: "server.users", Origins: []Origin{
+			{Layer: OriginFile, Name: "/my_etc/server.yaml", Value: []byte(`[{"name":"a","token":"******"}]`)},
+		}},
+	}, explanations)
+	origin, err := cs.Origin("db.password")
+	assert.NoError(t, err)
+	assert.Equal(t, `"******"`, string(origin.Value))
+
+	var lines []string
+	for _, mergeStep := range cs.MergeTrace() {
+		lines = append(lines, mergeStep.String())
+	}
+	assert.Equal(t, []string{
+		`file /my_etc/db.yaml set db.password="******"`,
+		`file /my_etc/server.yaml set server.users=[{"name":"a","token":"******"}]`,
+		`env CONFIGSET.db.password replaced db.password="******" with "******"`,
+	}, lines)
+}
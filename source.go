@@ -0,0 +1,147 @@
+package configset
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// AddSources adds the given sources to the config set. At every load, configs
+// are fetched from the sources in order and deep-merged over the configs from
+// configuration files, beneath environment variables.
+func AddSources(sources ...Source) { cs.AddSources(sources...) }
+
+// Source represents a source of configs other than configuration files, e.g. a
+// remote server.
+type Source interface {
+	// Fetch fetches configs in form of a JSON object, whose keys are config
+	// names and values are configs.
+	Fetch(ctx context.Context) (json.RawMessage, error)
+}
+
+// WithSources returns an option that adds the given sources to the config set,
+// like AddSources does.
+func WithSources(sources ...Source) Option {
+	return func(options *options) { options.sources = append(options.sources, sources...) }
+}
+
+// Poll reloads the config set, refetching configs from the sources, at the
+// interval set by WithPollInterval, with a random jitter set by
+// WithPollJitter added to each interval to spread the load of remote servers.
+// Poll blocks until the given context is done or the config set is closed, and
+// returns the error of the context or ErrClosed.
+// If a reload fails, the config set stays as it was, and the failure is
+// reported to the handler set by WithReloadErrorHandler.
+func Poll(ctx context.Context) error { return cs.Poll(ctx) }
+
+// ForceRefresh reloads the config set immediately, refetching configs from the
+// sources.
+func ForceRefresh() error { return cs.ForceRefresh() }
+
+// WithPollInterval returns an option that sets the interval at which Poll
+// reloads the config set. The default interval is 1 minute.
+func WithPollInterval(pollInterval time.Duration) Option {
+	return func(options *options) { options.pollInterval = pollInterval }
+}
+
+// WithPollJitter returns an option that sets the maximum random jitter added
+// to each interval of Poll. The default jitter is 0.
+func WithPollJitter(pollJitter time.Duration) Option {
+	return func(options *options) { options.pollJitter = pollJitter }
+}
+
+const defaultPollInterval = time.Minute
+
+func (cs *ConfigSet) AddSources(sources ...Source) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.options.sources = append(cs.options.sources, sources...)
+}
+
+func (cs *ConfigSet) Poll(ctx context.Context) error {
+	cs.mutex.Lock()
+	loaded := cs.input.fs != nil
+	pollInterval := cs.options.pollInterval
+	pollJitter := cs.options.pollJitter
+	cs.mutex.Unlock()
+	if !loaded {
+		return ErrNotLoaded
+	}
+	closure, err := cs.enterBackground()
+	if err != nil {
+		return err
+	}
+	defer cs.leaveBackground()
+	ctx, cancel := contextWithClosure(ctx, closure)
+	defer cancel()
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+	nextDelay := func() time.Duration {
+		delay := pollInterval
+		if pollJitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(pollJitter)))
+		}
+		return delay
+	}
+	timer := time.NewTimer(nextDelay())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return stopErr(ctx, closure)
+		case <-closure:
+			return ErrClosed
+		case <-timer.C:
+		}
+		if err := cs.load(ctx, nil); err != nil {
+			if ctx.Err() != nil {
+				return stopErr(ctx, closure)
+			}
+			cs.reportReloadError(fmt.Errorf("reload config set: %w", err))
+		}
+		timer.Reset(nextDelay())
+	}
+}
+
+func (cs *ConfigSet) ForceRefresh() error {
+	if err := cs.load(context.Background(), nil); err != nil {
+		return fmt.Errorf("reload config set: %w", err)
+	}
+	return nil
+}
+
+func fetchConfigs(ctx context.Context, rawConfigSet json.RawMessage, sources []Source, tracer Tracer, provenance *provenanceNode) (json.RawMessage, error) {
+	for i, source := range sources {
+		rawConfigs, err := fetchSource(ctx, i, source, tracer)
+		if err != nil {
+			return nil, err
+		}
+		rawConfigSet = mergeJSON(rawConfigSet, rawConfigs)
+		provenance.mergeValue(gjson.ParseBytes(rawConfigs), Origin{
+			Layer: OriginSource,
+			Name:  fmt.Sprintf("%d (%T)", i, source),
+		}, false)
+	}
+	return rawConfigSet, nil
+}
+
+func fetchSource(ctx context.Context, i int, source Source, tracer Tracer) (rawConfigs json.RawMessage, err error) {
+	ctx, span := tracer.StartSpan(ctx, "configset.fetch")
+	defer func() { span.End(err) }()
+	span.SetAttribute("configset.source_index", i)
+	span.SetAttribute("configset.source_type", fmt.Sprintf("%T", source))
+	rawConfigs, err = source.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch configs; sourceIndex=%d sourceType=\"%T\": %w", i, source, err)
+	}
+	span.SetAttribute("configset.byte_size", len(rawConfigs))
+	if !gjson.ValidBytes(rawConfigs) || !gjson.ParseBytes(rawConfigs).IsObject() {
+		return nil, fmt.Errorf("fetch configs; sourceIndex=%d sourceType=\"%T\": configs not in form of JSON object", i, source)
+	}
+	return rawConfigs, nil
+}
@@ -0,0 +1,111 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSource(t *testing.T) {
+	var port int32 = 8081
+	var requestCount, notModifiedCount, unavailable int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		if atomic.LoadInt32(&unavailable) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		port := strconv.Itoa(int(atomic.LoadInt32(&port)))
+		etag := `"` + port + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			atomic.AddInt32(&notModifiedCount, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte("server:\n  port: " + port + "\n"))
+	}))
+	defer server.Close()
+
+	var cs ConfigSet
+	reloadErrs := make(chan error, 100)
+	cs.Configure(
+		WithPollInterval(10*time.Millisecond),
+		WithPollJitter(time.Millisecond),
+		WithReloadErrorHandler(func(err error) { reloadErrs <- err }),
+	)
+	cs.AddSources(NewHTTPSource(server.URL, nil))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+host: localhost
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=example.com"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"server":{"host":"example.com","port":8081}}`, string(cs.Dump("", "")))
+
+	err = cs.ForceRefresh()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModifiedCount))
+	assert.Equal(t, `{"server":{"host":"example.com","port":8081}}`, string(cs.Dump("", "")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.Poll(ctx)
+	atomic.StoreInt32(&port, 8082)
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"host":"example.com","port":8082}}`
+	}, time.Second, 10*time.Millisecond)
+
+	atomic.StoreInt32(&unavailable, 1)
+	select {
+	case err := <-reloadErrs:
+		assert.EqualError(t, err, `reload config set: fetch configs; sourceIndex=0 sourceType="*configset.HTTPSource": unexpected status; url="`+server.URL+`" statusCode=503`)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	assert.Equal(t, `{"server":{"host":"example.com","port":8082}}`, string(cs.Dump("", "")))
+	atomic.StoreInt32(&unavailable, 0)
+	atomic.StoreInt32(&port, 8083)
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"host":"example.com","port":8083}}`
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigSet_Poll(t *testing.T) {
+	var fetchCount int32
+	var cs ConfigSet
+	cs.Configure(WithPollInterval(time.Hour))
+	cs.AddSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+		atomic.AddInt32(&fetchCount, 1)
+		return json.RawMessage(`{}`), nil
+	}))
+	fs := afero.NewMemMapFs()
+	if err := fs.Mkdir("/my_etc", 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- cs.Poll(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetchCount))
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+}
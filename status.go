@@ -0,0 +1,63 @@
+package configset
+
+import (
+	"fmt"
+	"time"
+)
+
+// Status returns the status of the config set, which readiness probes and admin
+// endpoints can surface directly, e.g. to report a config set never loaded or
+// failing to reload.
+func Status() StatusReport { return cs.Status() }
+
+// StatusReport represents the status of the config set.
+type StatusReport struct {
+	// LastLoadTime is the time of the last successful load, or zero if the config
+	// set has not been loaded.
+	LastLoadTime time.Time
+
+	// LastErr is the error of the last load, including reloads, or nil if the
+	// last load succeeded.
+	LastErr error
+
+	// Sources are the descriptions of the sources added with AddSources, in
+	// order. A source is described by its String method if it implements
+	// fmt.Stringer, otherwise by its type.
+	Sources []string
+
+	// Generation is the sequence number of the current generation, see History,
+	// or 0 if no generation has been created.
+	Generation int
+
+	// Fingerprint is the fingerprint of the config set, see Fingerprint.
+	Fingerprint string
+
+	loaded bool
+}
+
+// Loaded reports whether the config set has been loaded, see IsLoaded.
+func (sr *StatusReport) Loaded() bool { return sr.loaded }
+
+func (cs *ConfigSet) Status() StatusReport {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	snapshot := cs.Snapshot()
+	statusReport := StatusReport{
+		LastLoadTime: cs.loadStats.lastLoadTime,
+		LastErr:      cs.loadStats.lastErr,
+		Generation:   cs.generationCount,
+		Fingerprint:  snapshot.Fingerprint(),
+		loaded:       snapshot.loaded,
+	}
+	for _, source := range cs.options.sources {
+		statusReport.Sources = append(statusReport.Sources, describeSource(source))
+	}
+	return statusReport
+}
+
+func describeSource(source Source) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
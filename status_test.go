@@ -0,0 +1,67 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Status(t *testing.T) {
+	var cs ConfigSet
+	statusReport := cs.Status()
+	assert.False(t, statusReport.Loaded())
+	assert.Equal(t, StatusReport{}, statusReport)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("server: {timeout: 1s}"))
+	}))
+	defer server.Close()
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs.AddSources(
+		sourceFunc(func(context.Context) (json.RawMessage, error) { return json.RawMessage(`{}`), nil }),
+		NewHTTPSource(server.URL, nil),
+	)
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	statusReport = cs.Status()
+	assert.True(t, statusReport.Loaded())
+	assert.False(t, statusReport.LastLoadTime.IsZero())
+	assert.NoError(t, statusReport.LastErr)
+	assert.Equal(t, []string{"configset_test.sourceFunc", server.URL}, statusReport.Sources)
+	assert.Equal(t, 1, statusReport.Generation)
+	assert.Equal(t, cs.Fingerprint(), statusReport.Fingerprint)
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: [8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.ForceRefresh()
+	assert.Error(t, err)
+	lastLoadTime := statusReport.LastLoadTime
+	statusReport = cs.Status()
+	assert.Equal(t, lastLoadTime, statusReport.LastLoadTime)
+	assert.Error(t, statusReport.LastErr)
+	assert.Equal(t, 1, statusReport.Generation)
+}
+
+func TestConfigSet_Status_set(t *testing.T) {
+	var cs ConfigSet
+	err := cs.Set("server.port", 8080)
+	assert.NoError(t, err)
+	statusReport := cs.Status()
+	assert.False(t, statusReport.Loaded())
+	assert.Equal(t, cs.IsLoaded(), statusReport.Loaded())
+	assert.Equal(t, 1, statusReport.Generation)
+}
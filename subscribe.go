@@ -0,0 +1,76 @@
+package configset
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/tidwall/gjson"
+)
+
+// Subscribe subscribes to changes of the value for the given path prefix, the
+// given function will be called with the old and new values, in form of JSON,
+// once the value has changed after a reload. A nil value means the value does
+// not exist. An empty path prefix refers to the whole config set.
+// The returned function cancels the subscription.
+func Subscribe(pathPrefix string, callback func(oldValue, newValue json.RawMessage)) (cancel func()) {
+	return cs.Subscribe(pathPrefix, callback)
+}
+
+type subscriptions struct {
+	mutex  sync.Mutex
+	nextID int
+	items  []subscription
+}
+
+type subscription struct {
+	id         int
+	pathPrefix string
+	callback   func(oldValue, newValue json.RawMessage)
+}
+
+func (cs *ConfigSet) Subscribe(pathPrefix string, callback func(oldValue, newValue json.RawMessage)) func() {
+	subscriptions := &cs.subscriptions
+	subscriptions.mutex.Lock()
+	defer subscriptions.mutex.Unlock()
+	id := subscriptions.nextID
+	subscriptions.nextID++
+	subscriptions.items = append(subscriptions.items, subscription{
+		id:         id,
+		pathPrefix: pathPrefix,
+		callback:   callback,
+	})
+	return func() {
+		subscriptions.mutex.Lock()
+		defer subscriptions.mutex.Unlock()
+		for i, subscription := range subscriptions.items {
+			if subscription.id == id {
+				subscriptions.items = append(subscriptions.items[:i:i], subscriptions.items[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (s *subscriptions) Notify(oldRawConfigSet, newRawConfigSet json.RawMessage) {
+	s.mutex.Lock()
+	items := s.items
+	s.mutex.Unlock()
+	for _, subscription := range items {
+		oldValue, newValue := getValue(oldRawConfigSet, subscription.pathPrefix), getValue(newRawConfigSet, subscription.pathPrefix)
+		if string(oldValue) == string(newValue) {
+			continue
+		}
+		subscription.callback(oldValue, newValue)
+	}
+}
+
+func getValue(rawConfigSet json.RawMessage, path string) json.RawMessage {
+	if path == "" {
+		return rawConfigSet
+	}
+	value := gjson.GetBytes(rawConfigSet, path).Raw
+	if value == "" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
@@ -0,0 +1,49 @@
+package configset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Subscribe(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+host: localhost
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var changes []string
+	subscribe := func(pathPrefix string) func() {
+		return cs.Subscribe(pathPrefix, func(oldValue, newValue json.RawMessage) {
+			changes = append(changes, pathPrefix+": "+string(oldValue)+" -> "+string(newValue))
+		})
+	}
+	subscribe("server.port")
+	cancel := subscribe("server.host")
+	subscribe("server.timeout")
+
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081", "CONFIGSET.server.timeout=30"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"server.port: 8080 -> 8081",
+		"server.timeout:  -> 30",
+	}, changes)
+
+	changes = nil
+	cancel()
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.port=8081", "CONFIGSET.server.host=example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"server.timeout: 30 -> ",
+	}, changes)
+}
@@ -0,0 +1,71 @@
+package configset
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+)
+
+// WithTemplate returns an option that makes loads run each configuration file
+// through text/template, with the given data and functions, before parsing the
+// file as YAML, which replaces external steps such as envsubst, e.g.
+//
+//	host: {{ hostname }}
+//	logLevel: {{ env "LOG_LEVEL" | default "info" }}
+//
+// Besides the given functions, which take precedence, the functions of sprig
+// (github.com/Masterminds/sprig), e.g. default, upper, quote and b64enc, are
+// available, along with:
+//
+//	hostname      returns the host name
+//
+// The sprig functions env and expandenv look up the environment given to the
+// load rather than the environment of the process.
+func WithTemplate(data interface{}, funcs template.FuncMap) Option {
+	return func(options *options) {
+		options.template = &templateOptions{
+			data:  data,
+			funcs: funcs,
+		}
+	}
+}
+
+type templateOptions struct {
+	data  interface{}
+	funcs template.FuncMap
+}
+
+func (cs *ConfigSet) renderTemplate(filePath string, data []byte, environment []string) ([]byte, error) {
+	funcs := sprig.TxtFuncMap()
+	funcs["hostname"] = os.Hostname
+	funcs["env"] = func(name string) string { return lookUpEnv(environment, name) }
+	funcs["expandenv"] = func(s string) string {
+		return os.Expand(s, func(name string) string { return lookUpEnv(environment, name) })
+	}
+	for name, f := range cs.options.template.funcs {
+		funcs[name] = f
+	}
+	template, err := template.New(filePath).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buffer bytes.Buffer
+	if err := template.Execute(&buffer, cs.options.template.data); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func lookUpEnv(environment []string, name string) string {
+	prefix := name + "="
+	for i := len(environment) - 1; i >= 0; i-- {
+		if kv := environment[i]; strings.HasPrefix(kv, prefix) {
+			return kv[len(prefix):]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package configset_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Template(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+host: {{ hostname }}
+region: {{ .Region }}
+logLevel: {{ env "LOG_LEVEL" | default "info" }}
+name: {{ upper (env "APP_NAME") }}
+path: {{ expandenv "/home/$APP_NAME" | quote }}
+token: {{ "foo" | b64enc | title }}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	cs.Configure(WithTemplate(
+		map[string]string{"Region": "us-east-1"},
+		template.FuncMap{"upper": strings.ToUpper},
+	))
+	err := cs.Load(fs, "/my_etc", []string{"APP_NAME=foo"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	hostname, _ := os.Hostname()
+	var server struct {
+		Host     string `json:"host"`
+		Region   string `json:"region"`
+		LogLevel string `json:"logLevel"`
+		Name     string `json:"name"`
+		Path     string `json:"path"`
+		Token    string `json:"token"`
+	}
+	err = cs.ReadValue("server", &server)
+	assert.NoError(t, err)
+	assert.Equal(t, hostname, server.Host)
+	assert.Equal(t, "us-east-1", server.Region)
+	assert.Equal(t, "info", server.LogLevel)
+	assert.Equal(t, "FOO", server.Name)
+	assert.Equal(t, "/home/foo", server.Path)
+	assert.Equal(t, "Zm9v", server.Token)
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+region: {{ .Zone }}
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Load(fs, "/my_etc", nil)
+	assert.EqualError(t, err, `render template; filePath="/my_etc/server.yaml": execute template: template: /my_etc/server.yaml:2:11: executing "/my_etc/server.yaml" at <.Zone>: map has no entry for key "Zone"`)
+}
@@ -0,0 +1,56 @@
+package configset
+
+import "context"
+
+// Tracer represents a tracer starting spans for loads of the config set and
+// fetches of remote sources, which can be adapted to OpenTelemetry, e.g. with
+// trace.Tracer.Start, so that configuration-related startup latency and slow
+// sources show up in traces.
+type Tracer interface {
+	// StartSpan starts a span of the given name as a child of the span within
+	// the given context, if any, and returns the context with the span.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span represents a span started by a Tracer.
+type Span interface {
+	// SetAttribute sets the attribute of the given key to the given value,
+	// which is a bool, an int or a string.
+	SetAttribute(key string, value interface{})
+
+	// AddEvent adds an event of the given name to the span.
+	AddEvent(name string)
+
+	// End ends the span with the given error, if any.
+	End(err error)
+}
+
+// WithTracer returns an option that sets the tracer for the config set. Loads
+// and reloads are traced with spans named configset.load, with the attributes
+// configset.reload, configset.file_count and configset.byte_size, i.e. the size
+// of the config set in form of JSON, and fetches of sources with spans named
+// configset.fetch, with the attributes configset.source_index,
+// configset.source_type and configset.byte_size.
+func WithTracer(tracer Tracer) Option {
+	return func(options *options) { options.tracer = tracer }
+}
+
+type nopTracer struct{}
+
+func (nopTracer) StartSpan(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttribute(string, interface{}) {}
+func (nopSpan) AddEvent(string)                  {}
+func (nopSpan) End(error)                        {}
+
+// tracer returns the tracer set by WithTracer, or a tracer tracing nothing.
+func (cs *ConfigSet) tracer() Tracer {
+	if cs.options.tracer == nil {
+		return nopTracer{}
+	}
+	return cs.options.tracer
+}
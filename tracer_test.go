@@ -0,0 +1,84 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTracer struct {
+	records []string
+}
+
+func (rt *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	rt.records = append(rt.records, "start "+name)
+	return ctx, &recordingSpan{rt, name}
+}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	name   string
+}
+
+func (rs *recordingSpan) SetAttribute(key string, value interface{}) {
+	rs.tracer.records = append(rs.tracer.records, fmt.Sprintf("%s %s=%v", rs.name, key, value))
+}
+
+func (rs *recordingSpan) AddEvent(name string) {
+	rs.tracer.records = append(rs.tracer.records, fmt.Sprintf("%s event %s", rs.name, name))
+}
+
+func (rs *recordingSpan) End(err error) {
+	rs.tracer.records = append(rs.tracer.records, fmt.Sprintf("end %s err=%v", rs.name, err))
+}
+
+func TestWithTracer(t *testing.T) {
+	var cs ConfigSet
+	var tracer recordingTracer
+	cs.Configure(WithTracer(&tracer))
+	cs.AddSources(sourceFunc(func(context.Context) (json.RawMessage, error) {
+		return json.RawMessage(`{"client":{}}`), nil
+	}))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"start configset.load",
+		"configset.load configset.reload=false",
+		"configset.load configset.file_count=1",
+		"start configset.fetch",
+		"configset.fetch configset.source_index=0",
+		"configset.fetch configset.source_type=configset_test.sourceFunc",
+		"configset.fetch configset.byte_size=13",
+		"end configset.fetch err=<nil>",
+		"configset.load configset.byte_size=36",
+		"configset.load event configset.built",
+		"end configset.load err=<nil>",
+	}, tracer.records)
+
+	tracer.records = nil
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: [8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = cs.ForceRefresh()
+	if assert.Error(t, err) {
+		assert.Equal(t, []string{
+			"start configset.load",
+			"configset.load configset.reload=true",
+			"configset.load configset.file_count=1",
+			"end configset.load err=" + err.Error()[len("reload config set: "):],
+		}, tracer.records)
+	}
+}
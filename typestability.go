@@ -0,0 +1,47 @@
+package configset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+func checkTypeStability(oldRawConfigSet, newRawConfigSet json.RawMessage) error {
+	return doCheckTypeStability("", gjson.ParseBytes(oldRawConfigSet), gjson.ParseBytes(newRawConfigSet))
+}
+
+func doCheckTypeStability(path string, oldValue, newValue gjson.Result) error {
+	oldTypeName, newTypeName := jsonTypeName(oldValue), jsonTypeName(newValue)
+	if oldTypeName != newTypeName {
+		return fmt.Errorf("%w; path=%q oldType=%q newType=%q", ErrTypeChanged, path, oldTypeName, newTypeName)
+	}
+	switch oldTypeName {
+	case "object":
+		newValues := newValue.Map()
+		var err error
+		oldValue.ForEach(func(key, oldValue gjson.Result) bool {
+			newValue, ok := newValues[key.Str]
+			if !ok {
+				return true
+			}
+			err = doCheckTypeStability(joinPath(path, key.Str), oldValue, newValue)
+			return err == nil
+		})
+		return err
+	case "array":
+		oldValues, newValues := oldValue.Array(), newValue.Array()
+		for i := 0; i < len(oldValues) && i < len(newValues); i++ {
+			if err := doCheckTypeStability(joinPath(path, strconv.Itoa(i)), oldValues[i], newValues[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ErrTypeChanged is returned when the JSON type of a value changes on reload
+// with the type stability check enabled.
+var ErrTypeChanged = errors.New("configset: type changed")
@@ -0,0 +1,34 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTypeStabilityCheck(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithTypeStabilityCheck())
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+timeout: 30
+hosts: [a, b]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.hosts.1=c", "CONFIGSET.server.port=80"})
+	assert.NoError(t, err)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.timeout=30s"})
+	assert.EqualError(t, err, `configset: type changed; path="server.timeout" oldType="number" newType="string"`)
+	assert.ErrorIs(t, err, ErrTypeChanged)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.hosts.0=[a]"})
+	assert.EqualError(t, err, `configset: type changed; path="server.hosts.0" oldType="string" newType="array"`)
+	assert.Equal(t, `{"server":{"hosts":["a","c"],"port":80,"timeout":30}}`, string(cs.Dump("", "")))
+}
@@ -0,0 +1,40 @@
+package configset
+
+import (
+	"encoding/json"
+
+	"github.com/tidwall/gjson"
+)
+
+// AddValidators adds the given validators to the config set. The validators
+// are called at every load, after rules are evaluated, and the first error
+// returned by them fails the load.
+func AddValidators(validators ...Validator) { cs.AddValidators(validators...) }
+
+// Validator validates the whole config set, which enables enforcing
+// relationships between values, e.g.
+//
+//	func(configSet gjson.Result) error {
+//		if configSet.Get("cache.ttl").Int() >= configSet.Get("session.ttl").Int() {
+//			return errors.New("cache.ttl should be less than session.ttl")
+//		}
+//		return nil
+//	}
+type Validator func(configSet gjson.Result) error
+
+func (cs *ConfigSet) AddValidators(validators ...Validator) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.validators = append(cs.validators, validators...)
+}
+
+func runValidators(rawConfigSet json.RawMessage, validators []Validator) []error {
+	var errs []error
+	configSet := gjson.ParseBytes(rawConfigSet)
+	for _, validator := range validators {
+		if err := validator(configSet); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
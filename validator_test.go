@@ -0,0 +1,39 @@
+package configset_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/tidwall/gjson"
+)
+
+func TestConfigSet_AddValidators(t *testing.T) {
+	var cs ConfigSet
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/cache.yaml", []byte(`
+ttl: 60
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := afero.WriteFile(fs, "/my_etc/session.yaml", []byte(`
+ttl: 300
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cs.AddValidators(func(configSet gjson.Result) error {
+		if configSet.Get("cache.ttl").Int() >= configSet.Get("session.ttl").Int() {
+			return errors.New("cache.ttl should be less than session.ttl")
+		}
+		return nil
+	})
+
+	err := cs.Load(fs, "/my_etc", nil)
+	assert.NoError(t, err)
+
+	err = cs.Load(fs, "/my_etc", []string{"CONFIGSET.cache.ttl=600"})
+	assert.EqualError(t, err, "validate config set: cache.ttl should be less than session.ttl")
+	assert.Equal(t, `{"cache":{"ttl":60},"session":{"ttl":300}}`, string(cs.Dump("", "")))
+}
@@ -0,0 +1,102 @@
+package configset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/spf13/afero"
+	"github.com/tidwall/gjson"
+)
+
+// RegisterType registers the type of the given config for the given path.
+// The value for the path is required to exist and is unmarshalled into a new
+// config of that type, with unknown fields disallowed, when vetting.
+func RegisterType(path string, config interface{}) { cs.RegisterType(path, config) }
+
+// Vet vets the config set from all *.yaml files under the given directory, with
+// environment variables applied, against the registered rules, validators and
+// types.
+// Unlike Load, Vet leaves the loaded config set untouched and reports all the
+// problems found rather than the first one, which is suitable for running in
+// CI before deploys.
+func Vet(fs afero.Fs, dirPath string) Report { return cs.Vet(fs, dirPath, os.Environ()) }
+
+// Report represents the result of vetting.
+type Report struct {
+	Problems []Problem `json:"problems"`
+}
+
+// OK reports whether no problem has been found.
+func (r *Report) OK() bool { return len(r.Problems) == 0 }
+
+// Problem describes a problem found by vetting.
+type Problem struct {
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+type typeRegistration struct {
+	path       string
+	configType reflect.Type
+}
+
+func (cs *ConfigSet) RegisterType(path string, config interface{}) {
+	configType := reflect.TypeOf(config)
+	for configType.Kind() == reflect.Ptr {
+		configType = configType.Elem()
+	}
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	cs.types = append(cs.types, typeRegistration{
+		path:       path,
+		configType: configType,
+	})
+}
+
+func (cs *ConfigSet) Vet(fs afero.Fs, dirPath string, environment []string) Report {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	var report Report
+	result, err := cs.build(context.Background(), fs, dirPath, environment)
+	if err != nil {
+		report.Problems = append(report.Problems, Problem{Message: err.Error()})
+		return report
+	}
+	raw := result.raw
+	for _, ruleViolation := range checkRules(raw, cs.rules) {
+		report.Problems = append(report.Problems, Problem{
+			Path:    ruleViolation.path,
+			Message: ruleViolation.err.Error(),
+		})
+	}
+	for _, err := range runValidators(raw, cs.validators) {
+		report.Problems = append(report.Problems, Problem{Message: err.Error()})
+	}
+	for _, typeRegistration := range cs.types {
+		if err := checkType(raw, typeRegistration); err != nil {
+			report.Problems = append(report.Problems, Problem{
+				Path:    typeRegistration.path,
+				Message: err.Error(),
+			})
+		}
+	}
+	return report
+}
+
+func checkType(rawConfigSet json.RawMessage, typeRegistration typeRegistration) error {
+	value := gjson.GetBytes(rawConfigSet, typeRegistration.path).Raw
+	if value == "" {
+		return ErrValueNotFound
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	config := reflect.New(typeRegistration.configType).Interface()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("unmarshal from json; configType=\"%T\": %w", config, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package configset_test
+
+import (
+	"testing"
+
+	. "github.com/go-tk/configset"
+	"github.com/go-tk/testcase"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Vet(t *testing.T) {
+	type Server struct {
+		Port int    `json:"port"`
+		Host string `json:"host"`
+	}
+	type C struct {
+		fs             afero.Fs
+		dirPath        string
+		environment    []string
+		expectedReport Report
+	}
+	tc := testcase.New(func(t *testing.T, c *C) {
+		t.Parallel()
+
+		var cs ConfigSet
+		fs := afero.NewMemMapFs()
+		if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+host: localhost
+`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		c.fs = fs
+		c.dirPath = "/my_etc"
+
+		testcase.DoCallback(0, t, c)
+
+		cs.AddRules(Assert("server.port", Between(1, 65535)))
+		cs.RegisterType("server", Server{})
+		report := cs.Vet(c.fs, c.dirPath, c.environment)
+		assert.Equal(t, c.expectedReport, report)
+		assert.Equal(t, len(c.expectedReport.Problems) == 0, report.OK())
+	})
+
+	// valid config set
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {}).
+		Run(t)
+
+	// invalid config set
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.environment = []string{
+				"CONFIGSET.server.port=70000",
+				"CONFIGSET.server.timeout=30",
+			}
+			c.expectedReport = Report{
+				Problems: []Problem{
+					{Path: "server.port", Message: "value 70000 is not between 1 and 65535"},
+					{Path: "server", Message: `unmarshal from json; configType="*configset_test.Server": json: unknown field "timeout"`},
+				},
+			}
+		}).
+		Run(t)
+
+	// missing config
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			if err := c.fs.Remove("/my_etc/server.yaml"); err != nil {
+				t.Fatal(err)
+			}
+			c.expectedReport = Report{
+				Problems: []Problem{
+					{Path: "server", Message: "configset: value not found"},
+				},
+			}
+		}).
+		Run(t)
+
+	// non-existent configuration directory
+	tc.Copy().
+		SetCallback(0, func(t *testing.T, c *C) {
+			c.dirPath = "/helloworld"
+			c.expectedReport = Report{
+				Problems: []Problem{
+					{Message: `read dir; dirPath="/helloworld": open /helloworld: file does not exist`},
+				},
+			}
+		}).
+		Run(t)
+}
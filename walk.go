@@ -0,0 +1,66 @@
+package configset
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// replaceStrings returns a copy of the given config set with string values
+// replaced with the values, in form of JSON, returned by the given function,
+// which is called with the path and the string of each string value, and
+// returns nil to keep the string value.
+func replaceStrings(rawConfigSet json.RawMessage, replace func(path string, s string) (json.RawMessage, error)) (json.RawMessage, error) {
+	return appendReplacedStrings(nil, "", gjson.ParseBytes(rawConfigSet), replace)
+}
+
+func appendReplacedStrings(buffer []byte, path string, value gjson.Result, replace func(string, string) (json.RawMessage, error)) ([]byte, error) {
+	switch {
+	case value.Type == gjson.String:
+		newValue, err := replace(path, value.Str)
+		if err != nil {
+			return nil, err
+		}
+		if newValue == nil {
+			return append(buffer, value.Raw...), nil
+		}
+		return append(buffer, newValue...), nil
+	case value.IsObject():
+		buffer = append(buffer, '{')
+		n := 0
+		var err error
+		value.ForEach(func(key, value gjson.Result) bool {
+			if n >= 1 {
+				buffer = append(buffer, ',')
+			}
+			n++
+			buffer = append(buffer, key.Raw...)
+			buffer = append(buffer, ':')
+			buffer, err = appendReplacedStrings(buffer, joinPath(path, key.Str), value, replace)
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(buffer, '}'), nil
+	case value.IsArray():
+		buffer = append(buffer, '[')
+		n := 0
+		var err error
+		value.ForEach(func(_, value gjson.Result) bool {
+			if n >= 1 {
+				buffer = append(buffer, ',')
+			}
+			buffer, err = appendReplacedStrings(buffer, joinPath(path, strconv.Itoa(n)), value, replace)
+			n++
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return append(buffer, ']'), nil
+	default:
+		return append(buffer, value.Raw...), nil
+	}
+}
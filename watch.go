@@ -0,0 +1,242 @@
+package configset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+)
+
+// Watch watches the directory the config set has been loaded from, and reloads
+// the config set, with the same environment variables applied, once any change
+// of configuration files is detected. Watch blocks until the given context is
+// done or the config set is closed, and returns the error of the context or
+// ErrClosed.
+// If a reload fails, the config set stays as it was, and the failure is
+// reported to the handler set by WithReloadErrorHandler.
+//
+// If the config set has been loaded from the file system of the OS, i.e.
+// afero.OsFs, Watch is notified of changes with fsnotify, otherwise, e.g. for
+// in-memory file systems, which provide no change notifications, changes are
+// detected by polling the directory at the interval set by WithWatchInterval.
+// Symbolic links are followed, and the `..data` symbolic link, which kubelet
+// swaps atomically to update mounted ConfigMaps and Secrets, is tracked as
+// well, so that hot reload works in Kubernetes clusters.
+func Watch(ctx context.Context) error { return cs.Watch(ctx) }
+
+// WithWatchInterval returns an option that sets the interval at which Watch
+// polls the directory for changes, if the file system provides no change
+// notifications, see Watch. The default interval is 1 second.
+func WithWatchInterval(watchInterval time.Duration) Option {
+	return func(options *options) { options.watchInterval = watchInterval }
+}
+
+const defaultWatchInterval = time.Second
+
+// WithWatchQuietPeriod returns an option that makes Watch coalesce a burst of
+// changes, e.g. made by editors or kubelet symlink swaps, into a single reload,
+// which happens only after no further change has been detected for the given
+// quiet period. The default quiet period is 0, which means reloading as soon as
+// any change is detected.
+func WithWatchQuietPeriod(watchQuietPeriod time.Duration) Option {
+	return func(options *options) { options.watchQuietPeriod = watchQuietPeriod }
+}
+
+func (cs *ConfigSet) Watch(ctx context.Context) error {
+	cs.mutex.Lock()
+	loaded := cs.input.fs != nil
+	fs, dirPath := cs.input.fs, cs.input.dirPath
+	watchInterval := cs.options.watchInterval
+	watchQuietPeriod := cs.options.watchQuietPeriod
+	cs.mutex.Unlock()
+	if !loaded {
+		return ErrNotLoaded
+	}
+	closure, err := cs.enterBackground()
+	if err != nil {
+		return err
+	}
+	defer cs.leaveBackground()
+	ctx, cancel := contextWithClosure(ctx, closure)
+	defer cancel()
+	var ticks, quietPeriodEnds <-chan time.Time
+	var events <-chan fsnotify.Event
+	var watchErrs <-chan error
+	watcher := newFSWatcher(fs, dirPath)
+	if watcher == nil {
+		if watchInterval <= 0 {
+			watchInterval = defaultWatchInterval
+		}
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	} else {
+		defer watcher.Close()
+		events, watchErrs = watcher.Events, watcher.Errors
+		// Check for changes made before the watcher was set up.
+		quietPeriodEnds = time.After(0)
+	}
+	var pendingDirState, failedDirState string
+	var pendingSince time.Time
+	statFailed := false
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return stopErr(ctx, closure)
+		case <-closure:
+			return ErrClosed
+		case now = <-ticks:
+		case now = <-quietPeriodEnds:
+			quietPeriodEnds = nil
+		case event := <-events:
+			now = time.Now()
+			if event.Op&fsnotify.Create != 0 && event.Name == filepath.Join(dirPath, defaultsDirName) {
+				// The directory _defaults may be created after Watch starts.
+				_ = watcher.Add(event.Name)
+			}
+		case err := <-watchErrs:
+			cs.reportReloadError(fmt.Errorf("reload config set: watch dir; dirPath=%q: %w", dirPath, err))
+			continue
+		}
+		dirState, dirChanged, err := cs.statDir()
+		if err != nil {
+			if !statFailed {
+				statFailed = true
+				cs.reportReloadError(fmt.Errorf("reload config set: %w", err))
+			}
+			continue
+		}
+		statFailed = false
+		if !dirChanged || dirState == failedDirState {
+			pendingDirState = ""
+			continue
+		}
+		if dirState != pendingDirState {
+			pendingDirState = dirState
+			pendingSince = now
+		}
+		if quietPeriod := now.Sub(pendingSince); quietPeriod < watchQuietPeriod {
+			if watcher != nil {
+				// Without notifications of further changes, check again once
+				// the quiet period ends.
+				quietPeriodEnds = time.After(watchQuietPeriod - quietPeriod)
+			}
+			continue
+		}
+		pendingDirState = ""
+		if err := cs.load(ctx, nil); err != nil {
+			if ctx.Err() != nil {
+				return stopErr(ctx, closure)
+			}
+			failedDirState = dirState
+			cs.reportReloadError(fmt.Errorf("reload config set: %w", err))
+			continue
+		}
+		failedDirState = ""
+	}
+}
+
+// newFSWatcher returns a watcher notified of changes of the given directory,
+// including the directory _defaults under it, if the given file system is the
+// one of the OS, or nil otherwise, or if the watcher can't be set up, e.g. when
+// the OS limit on watches is reached, so that Watch falls back to polling.
+func newFSWatcher(fs afero.Fs, dirPath string) *fsnotify.Watcher {
+	if _, ok := fs.(*afero.OsFs); !ok {
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil
+	}
+	if err := watcher.Add(dirPath); err != nil {
+		watcher.Close()
+		return nil
+	}
+	defaultsDirPath := filepath.Join(dirPath, defaultsDirName)
+	if fileInfo, err := os.Stat(defaultsDirPath); err == nil && fileInfo.IsDir() {
+		if err := watcher.Add(defaultsDirPath); err != nil {
+			watcher.Close()
+			return nil
+		}
+	}
+	return watcher
+}
+
+func (cs *ConfigSet) statDir() (string, bool, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	dirState, err := statDir(cs.input.fs, cs.input.dirPath)
+	if err != nil {
+		return "", false, err
+	}
+	return dirState, dirState != cs.dirState, nil
+}
+
+// ErrNotLoaded is returned when the config set is used before it's loaded, e.g.
+// by ReadValue, DumpAt, Watch and Poll.
+var ErrNotLoaded = errors.New("configset: config set not loaded")
+
+// statDir returns a summary of the states of the configuration files under the
+// given directory, which changes whenever any file is added, removed or
+// modified.
+func statDir(fs afero.Fs, dirPath string) (string, error) {
+	var builder strings.Builder
+	if err := doStatDir(fs, dirPath, &builder); err != nil {
+		return "", err
+	}
+	defaultsDirPath := filepath.Join(dirPath, defaultsDirName)
+	if fileInfo, err := fs.Stat(defaultsDirPath); err == nil && fileInfo.IsDir() {
+		if err := doStatDir(fs, defaultsDirPath, &builder); err != nil {
+			return "", err
+		}
+	}
+	return builder.String(), nil
+}
+
+func doStatDir(fs afero.Fs, dirPath string, builder *strings.Builder) error {
+	fileInfoSet, err := afero.ReadDir(fs, dirPath)
+	if err != nil {
+		return fmt.Errorf("read dir; dirPath=%q: %w", dirPath, err)
+	}
+	sort.Slice(fileInfoSet, func(i, j int) bool { return fileInfoSet[i].Name() < fileInfoSet[j].Name() })
+	for _, fileInfo := range fileInfoSet {
+		fileName := fileInfo.Name()
+		filePath := filepath.Join(dirPath, fileName)
+		if fileName == kubernetesDataLinkName {
+			if linkReader, ok := fs.(afero.LinkReader); ok {
+				target, err := linkReader.ReadlinkIfPossible(filePath)
+				if err != nil {
+					return fmt.Errorf("read link; filePath=%q: %w", filePath, err)
+				}
+				fmt.Fprintf(builder, "%s\t%s\n", filePath, target)
+			}
+			continue
+		}
+		if !strings.HasSuffix(fileName, ".yaml") && fileName != signatureFileName && fileName != checksumManifestFileName {
+			continue
+		}
+		if fileInfo.Mode()&os.ModeSymlink != 0 {
+			fileInfo, err = fs.Stat(filePath)
+			if err != nil {
+				return fmt.Errorf("stat file; filePath=%q: %w", filePath, err)
+			}
+		}
+		if fileInfo.IsDir() {
+			continue
+		}
+		fmt.Fprintf(builder, "%s\t%d\t%d\n", filePath, fileInfo.Size(), fileInfo.ModTime().UnixNano())
+	}
+	return nil
+}
+
+// kubernetesDataLinkName is the name of the symbolic link to the directory of
+// the current version of files of a mounted ConfigMap or Secret.
+const kubernetesDataLinkName = "..data"
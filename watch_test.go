@@ -0,0 +1,202 @@
+package configset_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/go-tk/configset"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigSet_Watch(t *testing.T) {
+	var cs ConfigSet
+	reloadErrs := make(chan error, 10)
+	cs.Configure(
+		WithWatchInterval(10*time.Millisecond),
+		WithReloadErrorHandler(func(err error) { reloadErrs <- err }),
+	)
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8080
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", []string{"CONFIGSET.server.host=localhost"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.Watch(ctx)
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8081
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"host":"localhost","port":8081}}`
+	}, time.Second, 10*time.Millisecond)
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: [8082
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloadErrs:
+		assert.EqualError(t, err, `reload config set: convert yaml to json; filePath="/my_etc/server.yaml": yaml: line 2: did not find expected ',' or ']'`)
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, reloadErrs, 0)
+	assert.Equal(t, `{"server":{"host":"localhost","port":8081}}`, string(cs.Dump("", "")))
+
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(`
+port: 8082
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"host":"localhost","port":8082}}`
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigSet_Watch_Cancel(t *testing.T) {
+	var cs ConfigSet
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := cs.Watch(ctx)
+	assert.EqualError(t, err, "configset: config set not loaded")
+
+	err = cs.Load(afero.NewMemMapFs(), "/", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = cs.Watch(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWithWatchQuietPeriod(t *testing.T) {
+	var cs ConfigSet
+	cs.Configure(WithWatchInterval(10*time.Millisecond), WithWatchQuietPeriod(200*time.Millisecond))
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := cs.Load(fs, "/my_etc", nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var reloadCount int32
+	cs.Subscribe("", func(json.RawMessage, json.RawMessage) { atomic.AddInt32(&reloadCount, 1) })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.Watch(ctx)
+
+	for port := 8081; port <= 8085; port++ {
+		if err := afero.WriteFile(fs, "/my_etc/server.yaml", []byte(fmt.Sprintf("port: %d\n", port)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"port":8085}}`
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reloadCount))
+}
+
+func TestConfigSet_Watch_KubernetesConfigMap(t *testing.T) {
+	dirPath := t.TempDir()
+	writeVersion := func(version string, port int) {
+		versionDirPath := filepath.Join(dirPath, version)
+		if err := os.Mkdir(versionDirPath, 0755); err != nil {
+			t.Fatal(err)
+		}
+		filePath := filepath.Join(versionDirPath, "server.yaml")
+		if err := os.WriteFile(filePath, []byte(fmt.Sprintf("port: %d\n", port)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		if err := os.Chtimes(filePath, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	swapDataLink := func(version string) {
+		tempLinkPath := filepath.Join(dirPath, "..data_tmp")
+		if err := os.Symlink(version, tempLinkPath); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tempLinkPath, filepath.Join(dirPath, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("..2020_01_01_00_00_00.1", 8080)
+	swapDataLink("..2020_01_01_00_00_00.1")
+	if err := os.Symlink(filepath.Join("..data", "server.yaml"), filepath.Join(dirPath, "server.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	var cs ConfigSet
+	cs.Configure(WithWatchInterval(10 * time.Millisecond))
+	err := cs.Load(afero.NewOsFs(), dirPath, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, `{"server":{"port":8080}}`, string(cs.Dump("", "")))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.Watch(ctx)
+
+	writeVersion("..2020_01_01_00_00_00.2", 8081)
+	swapDataLink("..2020_01_01_00_00_00.2")
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"port":8081}}`
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestConfigSet_Watch_fsnotify(t *testing.T) {
+	dirPath := t.TempDir()
+	filePath := filepath.Join(dirPath, "server.yaml")
+	if err := os.WriteFile(filePath, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var cs ConfigSet
+	// Polling would take an hour, so only notifications trigger reloads.
+	cs.Configure(WithWatchInterval(time.Hour), WithWatchQuietPeriod(20*time.Millisecond))
+	err := cs.Load(afero.NewOsFs(), dirPath, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cs.Watch(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	if err := os.WriteFile(filePath, []byte("port: 8081\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"port":8081}}`
+	}, time.Second, 10*time.Millisecond)
+
+	defaultsDirPath := filepath.Join(dirPath, "_defaults")
+	if err := os.Mkdir(defaultsDirPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(defaultsDirPath, "server.yaml"), []byte("host: localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	assert.Eventually(t, func() bool {
+		return string(cs.Dump("", "")) == `{"server":{"host":"localhost","port":8081}}`
+	}, time.Second, 10*time.Millisecond)
+}